@@ -2,6 +2,7 @@ package api
 
 import (
 	db "BankAppGo/db/sqlc"
+	"BankAppGo/db/util"
 	"BankAppGo/token"
 	"database/sql"
 	"errors"
@@ -14,6 +15,8 @@ import (
 
 type createAccountRequest struct {
 	Currency string `json:"currency" binding:"required,currency"`
+	// checking when not given
+	Kind string `json:"kind" binding:"omitempty,oneof=checking savings"`
 }
 
 func (server *Server) createAccount(ctx *gin.Context) {
@@ -30,15 +33,32 @@ func (server *Server) createAccount(ctx *gin.Context) {
 		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
 		return
 	}
+	if req.Kind == "" {
+		req.Kind = util.CheckingAccount
+	}
 	arg := db.CreateAccountParams{
 		Owner:    authPayload.Username,
 		Currency: req.Currency,
 		Balance:  0,
+		Kind:     req.Kind,
 	}
-	// creates account
-	account, err := server.store.CreateAccount(ctx, arg)
 
+	idempotency, err := newIdempotency(ctx, authPayload.Username, req)
 	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// creates account, recording the idempotency key if the client sent one
+	account, err := server.store.CreateAccountTx(ctx, db.CreateAccountTxParams{
+		CreateAccountParams: arg,
+		Idempotency:         idempotency,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrIdempotencyKeyReused) {
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
 		if pqError, ok := err.(*pq.Error); ok {
 			switch pqError.Code.Name() {
 			case "foreign_key_violation", "unique_violation":
@@ -77,7 +97,7 @@ func (server *Server) getAccount(ctx *gin.Context) {
 	}
 
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
-	if account.Owner != authPayload.Username {
+	if !canReadAccount(authPayload, account) {
 		err := errors.New("account does not belong to the authenticated user")
 		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
 		return
@@ -87,6 +107,11 @@ func (server *Server) getAccount(ctx *gin.Context) {
 
 }
 
+// bankers and admins may read any account, depositors only their own
+func canReadAccount(payload *token.Payload, account db.Account) bool {
+	return account.Owner == payload.Username || hasRole(payload, util.BankerRole, util.AdminRole)
+}
+
 type listAccountsRequest struct {
 	Limit  int64 `form:"page_size" binding:"required,min=5,max=10"`
 	Offset int64 `form:"page_id" binding:"required,min=1"`
@@ -119,53 +144,169 @@ func (server *Server) listAccounts(ctx *gin.Context) {
 
 }
 
-type updateAccountRequest struct {
-	ID      int64 `form:"id" binding:"required,min=1"`
-	Balance int64 `form:"balance" binding:"required"`
+type adjustBalanceURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
 }
 
-func (server *Server) updateAccount(ctx *gin.Context) {
-	var req updateAccountRequest
+type adjustBalanceRequest struct {
+	// positive credits the account, negative debits it
+	Amount int64  `form:"amount" binding:"required"`
+	Reason string `form:"reason" binding:"required"`
+}
 
-	// binds HTTP request which is then extracted to struct
-	if err := ctx.ShouldBind(&req); err != nil {
+func (server *Server) adjustBalance(ctx *gin.Context) {
+	var uri adjustBalanceURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
 
-	arg := db.UpdateBalanceParams{
-		ID:      req.ID,
-		Balance: req.Balance,
+	var req adjustBalanceRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
 	}
 
-	accountID, err := server.store.UpdateBalance(ctx, arg)
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	// the adjustment is recorded with the admin who made it
+	result, err := server.store.AdjustBalanceTx(ctx, db.AdjustBalanceTxParams{
+		AccountID:  uri.ID,
+		Amount:     req.Amount,
+		Reason:     req.Reason,
+		AdjustedBy: authPayload.Username,
+	})
 
 	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		case errors.Is(err, db.ErrAccountClosed):
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		case errors.Is(err, db.ErrAccountFrozen):
+			ctx.JSON(http.StatusLocked, errorResponse(err))
+			return
+		case errors.Is(err, db.ErrInsufficientFunds):
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	ctx.JSON(http.StatusOK, accountID)
+	ctx.JSON(http.StatusOK, result)
 
 }
 
-type deleteAccountRequest struct {
+type closeAccountRequest struct {
 	ID int64 `uri:"id" binding:"required,min=1"`
 }
 
-func (server *Server) deleteAccount(ctx *gin.Context) {
-	var req deleteAccountRequest
+func (server *Server) closeAccount(ctx *gin.Context) {
+	var req closeAccountRequest
 	if err := ctx.ShouldBindUri(&req); err != nil {
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
 
-	err := server.store.DeleteAccount(ctx, req.ID)
+	account, err := server.store.GetAccount(ctx, req.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// admins may close any account, depositors only their own
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if account.Owner != authPayload.Username && !hasRole(authPayload, util.AdminRole) {
+		err := errors.New("account does not belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	account, err = server.store.CloseAccountTx(ctx, req.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrAccountNotEmpty):
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+			return
+		case errors.Is(err, db.ErrAccountClosed):
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		case errors.Is(err, db.ErrAccountFrozen):
+			ctx.JSON(http.StatusLocked, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, account)
+}
+
+type setAccountStatusURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type setAccountStatusRequest struct {
+	Status string `form:"status" binding:"required,oneof=active frozen closed"`
+	Reason string `form:"reason" binding:"required,max=255"`
+}
+
+// freezes, unfreezes or closes an account on behalf of compliance
+func (server *Server) setAccountStatus(ctx *gin.Context) {
+	var uri setAccountStatusURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req setAccountStatusRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
 
+	account, err := server.store.SetAccountStatusTx(ctx, db.SetAccountStatusTxParams{
+		AccountID: uri.ID,
+		Status:    req.Status,
+		Reason:    req.Reason,
+	})
 	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		case errors.Is(err, db.ErrAccountClosed), errors.Is(err, db.ErrInvalidStatusTransition):
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		case errors.Is(err, db.ErrAccountNotEmpty):
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{"status": fmt.Sprintf("Successfully deleted account %d", req.ID)})
+	ctx.JSON(http.StatusOK, account)
+}
+
+// responds with 423 when the account is frozen and 403 when it is closed
+func ensureActive(ctx *gin.Context, account db.Account) bool {
+	switch account.Status {
+	case util.AccountFrozen:
+		err := fmt.Errorf("%w: account %d", db.ErrAccountFrozen, account.ID)
+		ctx.JSON(http.StatusLocked, errorResponse(err))
+		return false
+	case util.AccountClosed:
+		err := fmt.Errorf("%w: account %d", db.ErrAccountClosed, account.ID)
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return false
+	}
+	return true
 }
@@ -0,0 +1,95 @@
+package api
+
+import (
+	db "BankAppGo/db/sqlc"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type listAuditEventsRequest struct {
+	Actor string `form:"actor"`
+	// method and route, e.g. "POST /transfers"
+	Action     string    `form:"action"`
+	TargetType string    `form:"target_type"`
+	TargetID   string    `form:"target_id"`
+	From       time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To         time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00" binding:"omitempty,gtfield=From"`
+	// next_cursor of the previous page, empty for the first one
+	Cursor   int64 `form:"cursor" binding:"min=0"`
+	PageSize int64 `form:"page_size" binding:"required,min=1,max=100"`
+}
+
+type auditEventResponse struct {
+	ID         int64           `json:"id"`
+	Actor      string          `json:"actor,omitempty"`
+	Action     string          `json:"action"`
+	TargetType string          `json:"target_type,omitempty"`
+	TargetID   string          `json:"target_id,omitempty"`
+	Before     json.RawMessage `json:"before"`
+	After      json.RawMessage `json:"after"`
+	ClientIP   string          `json:"client_ip"`
+	RequestID  string          `json:"request_id"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+type listAuditEventsResponse struct {
+	Events []auditEventResponse `json:"events"`
+	// passed as cursor to get the next page, omitted on the last one
+	NextCursor int64 `json:"next_cursor,omitempty"`
+}
+
+func newAuditEventResponse(event db.AuditEvent) auditEventResponse {
+	return auditEventResponse{
+		ID:         event.ID,
+		Actor:      event.Actor.String,
+		Action:     event.Action,
+		TargetType: event.TargetType.String,
+		TargetID:   event.TargetID.String,
+		Before:     event.Before,
+		After:      event.After,
+		ClientIP:   event.ClientIp,
+		RequestID:  event.RequestID,
+		CreatedAt:  event.CreatedAt,
+	}
+}
+
+// lists audit events newest first, narrowed by the filters given
+func (server *Server) listAuditEvents(ctx *gin.Context) {
+	var req listAuditEventsRequest
+
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// one extra event tells whether there is a next page
+	events, err := server.store.ListAuditEvents(ctx, db.ListAuditEventsParams{
+		Actor:      sql.NullString{String: req.Actor, Valid: req.Actor != ""},
+		Action:     sql.NullString{String: req.Action, Valid: req.Action != ""},
+		TargetType: sql.NullString{String: req.TargetType, Valid: req.TargetType != ""},
+		TargetID:   sql.NullString{String: req.TargetID, Valid: req.TargetID != ""},
+		FromTime:   sql.NullTime{Time: req.From, Valid: !req.From.IsZero()},
+		ToTime:     sql.NullTime{Time: req.To, Valid: !req.To.IsZero()},
+		BeforeID:   sql.NullInt64{Int64: req.Cursor, Valid: req.Cursor != 0},
+		LimitCount: req.PageSize + 1,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	response := listAuditEventsResponse{Events: make([]auditEventResponse, 0, len(events))}
+	for _, event := range events {
+		response.Events = append(response.Events, newAuditEventResponse(event))
+	}
+	if int64(len(events)) > req.PageSize {
+		response.Events = response.Events[:req.PageSize]
+		response.NextCursor = response.Events[req.PageSize-1].ID
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,162 @@
+package api
+
+import (
+	mockdb "BankAppGo/db/mock"
+	db "BankAppGo/db/sqlc"
+	"BankAppGo/db/util"
+	"BankAppGo/token"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func randomAuditEvent(id int64) db.AuditEvent {
+	return db.AuditEvent{
+		ID:         id,
+		Actor:      sql.NullString{String: util.RandomOwner(), Valid: true},
+		Action:     "POST /transfers",
+		TargetType: sql.NullString{String: db.AuditTargetTransfer, Valid: true},
+		TargetID:   sql.NullString{String: "1", Valid: true},
+		Before:     json.RawMessage(`null`),
+		After:      json.RawMessage(`{"ID":1}`),
+		ClientIp:   "127.0.0.1",
+		RequestID:  "request-1",
+		CreatedAt:  time.Now(),
+	}
+}
+
+func TestListAuditEventsAPI(t *testing.T) {
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name          string
+		query         string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			query: "page_size=2",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "admin", util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListAuditEvents(gomock.Any(), gomock.Eq(db.ListAuditEventsParams{LimitCount: 3})).
+					Times(1).
+					Return([]db.AuditEvent{randomAuditEvent(9), randomAuditEvent(8), randomAuditEvent(7)}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response listAuditEventsResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Len(t, response.Events, 2)
+				require.Equal(t, int64(8), response.NextCursor)
+				require.Equal(t, db.AuditTargetTransfer, response.Events[0].TargetType)
+				require.JSONEq(t, `{"ID":1}`, string(response.Events[0].After))
+			},
+		},
+		{
+			name:  "Filters",
+			query: "actor=alice&action=POST%20/transfers&target_type=transfer&target_id=1&from=2026-01-01T00:00:00Z&cursor=8&page_size=5",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "admin", util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListAuditEventsParams{
+					Actor:      sql.NullString{String: "alice", Valid: true},
+					Action:     sql.NullString{String: "POST /transfers", Valid: true},
+					TargetType: sql.NullString{String: db.AuditTargetTransfer, Valid: true},
+					TargetID:   sql.NullString{String: "1", Valid: true},
+					FromTime:   sql.NullTime{Time: from, Valid: true},
+					BeforeID:   sql.NullInt64{Int64: 8, Valid: true},
+					LimitCount: 6,
+				}
+				store.EXPECT().
+					ListAuditEvents(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return([]db.AuditEvent{randomAuditEvent(7)}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response listAuditEventsResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Len(t, response.Events, 1)
+				require.Zero(t, response.NextCursor)
+			},
+		},
+		{
+			name:  "NotAdmin",
+			query: "page_size=5",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "banker", util.BankerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAuditEvents(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:  "InvalidRange",
+			query: "from=2026-02-01T00:00:00Z&to=2026-01-01T00:00:00Z&page_size=5",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "admin", util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAuditEvents(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "InternalError",
+			query: "page_size=5",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "admin", util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListAuditEvents(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/audit?"+tc.query, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
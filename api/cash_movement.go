@@ -0,0 +1,81 @@
+package api
+
+import (
+	db "BankAppGo/db/sqlc"
+	"BankAppGo/token"
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type cashMovementURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type cashMovementRequest struct {
+	Amount   int64  `form:"amount" binding:"required,gt=0"`
+	Currency string `form:"currency" binding:"required,currency"`
+	// id given by the payment processor, retries with the same reference return the first movement
+	ExternalReference string `form:"external_reference" binding:"required,max=255"`
+}
+
+// pays money into an account from outside the bank
+func (server *Server) createDeposit(ctx *gin.Context) {
+	server.moveCash(ctx, server.store.DepositTx)
+}
+
+// pays money out of an account to outside the bank
+func (server *Server) createWithdrawal(ctx *gin.Context) {
+	server.moveCash(ctx, server.store.WithdrawTx)
+}
+
+// books a deposit or withdrawal reported by a member of staff once the money was received or paid out,
+// the owner of an account can't credit it on their own
+func (server *Server) moveCash(ctx *gin.Context, move func(context.Context, db.CashTxParams) (db.CashTxResult, error)) {
+	var uri cashMovementURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req cashMovementRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, valid := server.validate_account(ctx, uri.ID, req.Currency); !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	result, err := move(ctx, db.CashTxParams{
+		AccountID:         uri.ID,
+		Amount:            req.Amount,
+		ExternalReference: req.ExternalReference,
+		CreatedBy:         authPayload.Username,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrExternalReferenceReused):
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		case errors.Is(err, db.ErrInsufficientFunds):
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+			return
+		case errors.Is(err, db.ErrAccountClosed):
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		case errors.Is(err, db.ErrAccountFrozen):
+			ctx.JSON(http.StatusLocked, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
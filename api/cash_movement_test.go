@@ -0,0 +1,208 @@
+package api
+
+import (
+	mockdb "BankAppGo/db/mock"
+	db "BankAppGo/db/sqlc"
+	"BankAppGo/db/util"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCashMovementAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	staff, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	account.Currency = util.USD
+
+	body := gin.H{
+		"amount":             50,
+		"currency":           account.Currency,
+		"external_reference": "psp_7F3a91",
+	}
+	arg := db.CashTxParams{
+		AccountID:         account.ID,
+		Amount:            50,
+		ExternalReference: "psp_7F3a91",
+		CreatedBy:         staff.Username,
+	}
+
+	testCases := []struct {
+		name       string
+		path       string
+		body       gin.H
+		username   string
+		role       string
+		buildStubs func(store *mockdb.MockStore)
+		status     int
+	}{
+		{
+			name:     "DepositOK",
+			path:     "deposits",
+			body:     body,
+			username: staff.Username,
+			role:     util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().DepositTx(gomock.Any(), gomock.Eq(arg)).Times(1)
+				store.EXPECT().WithdrawTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			status: http.StatusOK,
+		},
+		{
+			name:     "OwnerDeposits",
+			path:     "deposits",
+			body:     body,
+			username: user.Username,
+			role:     util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().DepositTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			status: http.StatusForbidden,
+		},
+		{
+			name:     "ReferenceReused",
+			path:     "deposits",
+			body:     body,
+			username: staff.Username,
+			role:     util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					DepositTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.CashTxResult{}, db.ErrExternalReferenceReused)
+			},
+			status: http.StatusConflict,
+		},
+		{
+			name: "CurrencyMismatch",
+			path: "deposits",
+			body: gin.H{
+				"amount":             50,
+				"currency":           util.EUR,
+				"external_reference": "psp_7F3a91",
+			},
+			username: staff.Username,
+			role:     util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().DepositTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			status: http.StatusBadRequest,
+		},
+		{
+			name: "MissingReference",
+			path: "deposits",
+			body: gin.H{
+				"amount":   50,
+				"currency": account.Currency,
+			},
+			username: staff.Username,
+			role:     util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:     "AccountNotFound",
+			path:     "deposits",
+			body:     body,
+			username: staff.Username,
+			role:     util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+			},
+			status: http.StatusNotFound,
+		},
+		{
+			name:     "WithdrawalOK",
+			path:     "withdrawals",
+			body:     body,
+			username: staff.Username,
+			role:     util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().WithdrawTx(gomock.Any(), gomock.Eq(arg)).Times(1)
+				store.EXPECT().DepositTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			status: http.StatusOK,
+		},
+		{
+			name:     "OwnerWithdraws",
+			path:     "withdrawals",
+			body:     body,
+			username: user.Username,
+			role:     util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().WithdrawTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			status: http.StatusForbidden,
+		},
+		{
+			name:     "InsufficientFunds",
+			path:     "withdrawals",
+			body:     body,
+			username: staff.Username,
+			role:     util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					WithdrawTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.CashTxResult{}, db.ErrInsufficientFunds)
+			},
+			status: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "AccountClosed",
+			path:     "withdrawals",
+			body:     body,
+			username: staff.Username,
+			role:     util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					WithdrawTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.CashTxResult{}, db.ErrAccountClosed)
+			},
+			status: http.StatusForbidden,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data := GinHToURLValues(tc.body)
+			url := fmt.Sprintf("/accounts/%d/%s", account.ID, tc.path)
+			request, err := http.NewRequest(http.MethodPost, url, strings.NewReader(data.Encode()))
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, tc.role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, tc.status, recorder.Code)
+		})
+	}
+}
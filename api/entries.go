@@ -0,0 +1,82 @@
+package api
+
+import (
+	db "BankAppGo/db/sqlc"
+	"BankAppGo/token"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type listEntriesURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type listEntriesRequest struct {
+	From time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00" binding:"required"`
+	To   time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00" binding:"required,gtfield=From"`
+	// next_cursor of the previous page, empty for the first one
+	Cursor   int64 `form:"cursor" binding:"min=0"`
+	PageSize int64 `form:"page_size" binding:"required,min=1,max=100"`
+}
+
+type listEntriesResponse struct {
+	db.ListAccountEntriesTxResult
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+	// passed as cursor to get the next page, omitted on the last one
+	NextCursor int64 `json:"next_cursor,omitempty"`
+}
+
+func (server *Server) listEntries(ctx *gin.Context) {
+	var uri listEntriesURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req listEntriesRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// one extra entry tells whether there is a next page
+	result, err := server.store.ListAccountEntriesTx(ctx, db.ListAccountEntriesTxParams{
+		AccountID: uri.ID,
+		From:      req.From,
+		To:        req.To,
+		AfterID:   req.Cursor,
+		Limit:     req.PageSize + 1,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if !canReadAccount(authPayload, result.Account) {
+		err := errors.New("account does not belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	response := listEntriesResponse{
+		ListAccountEntriesTxResult: result,
+		From:                       req.From,
+		To:                         req.To,
+	}
+	if int64(len(result.Entries)) > req.PageSize {
+		response.Entries = result.Entries[:req.PageSize]
+		response.NextCursor = response.Entries[req.PageSize-1].ID
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
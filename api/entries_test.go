@@ -0,0 +1,212 @@
+package api
+
+import (
+	mockdb "BankAppGo/db/mock"
+	db "BankAppGo/db/sqlc"
+	"BankAppGo/db/util"
+	"BankAppGo/token"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListEntriesAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	from := time.Now().Add(-24 * time.Hour).UTC().Truncate(time.Second)
+	to := time.Now().UTC().Truncate(time.Second)
+
+	lines := []db.EntryLine{
+		{Entry: db.Entry{ID: 1, AccountID: account.ID, Amount: 10}, Balance: 10},
+		{Entry: db.Entry{ID: 4, AccountID: account.ID, Amount: -3}, Balance: 7},
+		{Entry: db.Entry{ID: 9, AccountID: account.ID, Amount: 5}, Balance: 12},
+	}
+
+	query := func(from, to time.Time, cursor int64, pageSize int) url.Values {
+		values := url.Values{}
+		values.Set("from", from.Format(time.RFC3339))
+		values.Set("to", to.Format(time.RFC3339))
+		values.Set("page_size", fmt.Sprint(pageSize))
+		if cursor > 0 {
+			values.Set("cursor", fmt.Sprint(cursor))
+		}
+		return values
+	}
+
+	testCases := []struct {
+		name          string
+		query         url.Values
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			query: query(from, to, 0, 2),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListAccountEntriesTxParams{
+					AccountID: account.ID,
+					From:      from,
+					To:        to,
+					Limit:     3,
+				}
+				store.EXPECT().
+					ListAccountEntriesTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.ListAccountEntriesTxResult{
+						Account:        account,
+						OpeningBalance: 0,
+						ClosingBalance: 12,
+						Entries:        lines,
+					}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got listEntriesResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, lines[:2], got.Entries)
+				require.Equal(t, int64(4), got.NextCursor)
+				require.Equal(t, int64(12), got.ClosingBalance)
+			},
+		},
+		{
+			name:  "LastPage",
+			query: query(from, to, 4, 2),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListAccountEntriesTxParams{
+					AccountID: account.ID,
+					From:      from,
+					To:        to,
+					AfterID:   4,
+					Limit:     3,
+				}
+				store.EXPECT().
+					ListAccountEntriesTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.ListAccountEntriesTxResult{Account: account, Entries: lines[2:]}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got listEntriesResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, lines[2:], got.Entries)
+				require.Zero(t, got.NextCursor)
+			},
+		},
+		{
+			name:  "UnauthorizedUser",
+			query: query(from, to, 0, 2),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "unauthorized", util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListAccountEntriesTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ListAccountEntriesTxResult{Account: account, Entries: lines}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:  "InvalidRange",
+			query: query(to, from, 0, 2),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccountEntriesTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "InvalidPageSize",
+			query: query(from, to, 0, 1000),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccountEntriesTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "NotFound",
+			query: query(from, to, 0, 2),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListAccountEntriesTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ListAccountEntriesTxResult{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:  "InternalError",
+			query: query(from, to, 0, 2),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListAccountEntriesTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ListAccountEntriesTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/accounts/%d/entries?%s", account.ID, tc.query.Encode())
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
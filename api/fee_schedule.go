@@ -0,0 +1,106 @@
+package api
+
+import (
+	db "BankAppGo/db/sqlc"
+	"BankAppGo/fees"
+	"BankAppGo/token"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type createFeeScheduleRequest struct {
+	Currency string `form:"currency" binding:"required,currency"`
+	Kind     string `form:"kind" binding:"required,oneof=flat percentage tiered"`
+	FlatFee  int64  `form:"flat_fee" binding:"min=0"`
+	RateBps  int64  `form:"rate_bps" binding:"min=0,max=10000"`
+	// json array of tiers, for tiered schedules
+	Tiers  string `form:"tiers"`
+	MinFee int64  `form:"min_fee" binding:"min=0"`
+	MaxFee int64  `form:"max_fee" binding:"min=0"`
+}
+
+// sets the fee schedule of a currency, it replaces the one in force for transfers made from then on
+func (server *Server) createFeeSchedule(ctx *gin.Context) {
+	var req createFeeScheduleRequest
+
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	schedule := fees.Schedule{
+		Kind:    req.Kind,
+		Flat:    req.FlatFee,
+		RateBps: req.RateBps,
+		Min:     req.MinFee,
+		Max:     req.MaxFee,
+	}
+	if req.Tiers != "" {
+		if err := json.Unmarshal([]byte(req.Tiers), &schedule.Tiers); err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+	}
+	if err := schedule.Validate(); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	tiers, err := json.Marshal(schedule.Tiers)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if schedule.Tiers == nil {
+		tiers = []byte("[]")
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	row, err := server.store.CreateFeeScheduleTx(ctx, db.CreateFeeScheduleParams{
+		Currency:  req.Currency,
+		Kind:      req.Kind,
+		FlatFee:   req.FlatFee,
+		RateBps:   req.RateBps,
+		Tiers:     tiers,
+		MinFee:    req.MinFee,
+		MaxFee:    req.MaxFee,
+		CreatedBy: authPayload.Username,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, row)
+}
+
+type getFeeScheduleRequest struct {
+	Currency string `uri:"currency" binding:"required,currency"`
+}
+
+// returns the fee schedule in force for a currency
+func (server *Server) getFeeSchedule(ctx *gin.Context) {
+	var req getFeeScheduleRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	schedule, err := server.store.GetFeeSchedule(ctx, req.Currency)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, schedule)
+}
@@ -0,0 +1,247 @@
+package api
+
+import (
+	mockdb "BankAppGo/db/mock"
+	db "BankAppGo/db/sqlc"
+	"BankAppGo/db/util"
+	"BankAppGo/token"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateFeeScheduleAPI(t *testing.T) {
+	testCases := []struct {
+		name          string
+		body          gin.H
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Tiered",
+			body: gin.H{
+				"currency": util.EUR,
+				"kind":     "tiered",
+				"tiers":    `[{"up_to":1000,"flat":10},{"rate_bps":100}]`,
+				"max_fee":  500,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "admin", util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateFeeScheduleTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.CreateFeeScheduleParams) (db.FeeSchedule, error) {
+						require.Equal(t, util.EUR, arg.Currency)
+						require.Equal(t, "admin", arg.CreatedBy)
+						require.JSONEq(t, `[{"up_to":1000,"flat":10,"rate_bps":0},{"up_to":0,"flat":0,"rate_bps":100}]`, string(arg.Tiers))
+						return db.FeeSchedule{ID: 1, Currency: arg.Currency, Kind: arg.Kind, Tiers: arg.Tiers}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "FlatWithoutTiers",
+			body: gin.H{
+				"currency": util.USD,
+				"kind":     "flat",
+				"flat_fee": 25,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "admin", util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateFeeScheduleTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.CreateFeeScheduleParams) (db.FeeSchedule, error) {
+						require.Equal(t, int64(25), arg.FlatFee)
+						require.Equal(t, json.RawMessage("[]"), arg.Tiers)
+						return db.FeeSchedule{ID: 2}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "NotAdmin",
+			body: gin.H{
+				"currency": util.USD,
+				"kind":     "flat",
+				"flat_fee": 25,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "banker", util.BankerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateFeeScheduleTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidTiers",
+			body: gin.H{
+				"currency": util.USD,
+				"kind":     "tiered",
+				"tiers":    `[{"up_to":1000,"flat":10}]`,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "admin", util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateFeeScheduleTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "MalformedTiers",
+			body: gin.H{
+				"currency": util.USD,
+				"kind":     "tiered",
+				"tiers":    `not json`,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "admin", util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateFeeScheduleTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "MaxBelowMin",
+			body: gin.H{
+				"currency": util.USD,
+				"kind":     "percentage",
+				"rate_bps": 100,
+				"min_fee":  50,
+				"max_fee":  10,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "admin", util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateFeeScheduleTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data := GinHToURLValues(tc.body)
+
+			request, err := http.NewRequest(http.MethodPost, "/fee-schedules", strings.NewReader(data.Encode()))
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestGetFeeScheduleAPI(t *testing.T) {
+	testCases := []struct {
+		name          string
+		currency      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			currency: util.USD,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetFeeSchedule(gomock.Any(), gomock.Eq(util.USD)).
+					Times(1).
+					Return(db.FeeSchedule{ID: 4, Currency: util.USD, Kind: "flat", FlatFee: 25, Tiers: json.RawMessage("[]")}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var schedule db.FeeSchedule
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &schedule))
+				require.Equal(t, int64(25), schedule.FlatFee)
+			},
+		},
+		{
+			name:     "NotFound",
+			currency: util.USD,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetFeeSchedule(gomock.Any(), gomock.Eq(util.USD)).
+					Times(1).
+					Return(db.FeeSchedule{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:     "InvalidCurrency",
+			currency: "XYZ",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetFeeSchedule(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/fee-schedules/"+tc.currency, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "user", util.DepositorRole, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
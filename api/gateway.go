@@ -0,0 +1,113 @@
+package api
+
+import (
+	"BankAppGo/doc/swagger"
+	"BankAppGo/pb"
+	"context"
+	"net/http"
+	"net/textproto"
+	"strings"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// gatewayPrefix is the path the REST API generated from proto/service_bank.proto is served under
+const gatewayPrefix = "/v1"
+
+// gatewayService answers the REST API in process, running every call through the interceptor of the gRPC server
+type gatewayService struct {
+	pb.UnimplementedBankServiceServer
+	server  *Server
+	service *grpcServer
+}
+
+// creates the handler of the REST routes declared by the google.api.http options of the BankService
+func (server *Server) newGateway() (http.Handler, error) {
+	mux := runtime.NewServeMux(
+		// keeps the snake_case field names of the protos, as the rest of the HTTP API uses
+		runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
+			MarshalOptions: protojson.MarshalOptions{
+				UseProtoNames:   true,
+				EmitUnpopulated: true,
+			},
+			UnmarshalOptions: protojson.UnmarshalOptions{
+				DiscardUnknown: true,
+			},
+		}),
+		runtime.WithIncomingHeaderMatcher(gatewayIncomingHeader),
+		runtime.WithOutgoingHeaderMatcher(gatewayOutgoingHeader),
+	)
+
+	err := pb.RegisterBankServiceHandlerServer(context.Background(), mux, &gatewayService{
+		server:  server,
+		service: &grpcServer{Server: server},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = mux.HandlePath(http.MethodGet, gatewayPrefix+"/swagger.json", func(w http.ResponseWriter, _ *http.Request, _ map[string]string) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(swagger.Bank)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mux, nil
+}
+
+// forwards the headers the handlers read as metadata, on top of the ones grpc-gateway forwards by default
+func gatewayIncomingHeader(key string) (string, bool) {
+	switch textproto.CanonicalMIMEHeaderKey(key) {
+	case "User-Agent", textproto.CanonicalMIMEHeaderKey(requestIDHeaderKey):
+		return strings.ToLower(key), true
+	}
+	return runtime.DefaultHeaderMatcher(key)
+}
+
+// answers the request id under the header the gin routes use
+func gatewayOutgoingHeader(key string) (string, bool) {
+	if strings.EqualFold(key, requestIDHeaderKey) {
+		return requestIDHeaderKey, true
+	}
+	return runtime.MetadataHeaderPrefix + key, true
+}
+
+// runs a call of the gateway through the interceptor, so REST requests are authenticated, validated and audited as gRPC ones
+func gatewayCall[Req, Res any](ctx context.Context, server *Server, method string, req Req, handler func(context.Context, Req) (Res, error)) (Res, error) {
+	info := &grpc.UnaryServerInfo{FullMethod: method}
+	res, err := server.grpcInterceptor(ctx, req, info, func(ctx context.Context, req any) (any, error) {
+		return handler(ctx, req.(Req))
+	})
+	if err != nil {
+		var zero Res
+		return zero, err
+	}
+	return res.(Res), nil
+}
+
+func (gateway *gatewayService) CreateUser(ctx context.Context, req *pb.CreateUserRequest) (*pb.CreateUserResponse, error) {
+	return gatewayCall(ctx, gateway.server, pb.BankService_CreateUser_FullMethodName, req, gateway.service.CreateUser)
+}
+
+func (gateway *gatewayService) LoginUser(ctx context.Context, req *pb.LoginUserRequest) (*pb.LoginUserResponse, error) {
+	return gatewayCall(ctx, gateway.server, pb.BankService_LoginUser_FullMethodName, req, gateway.service.LoginUser)
+}
+
+func (gateway *gatewayService) CreateAccount(ctx context.Context, req *pb.CreateAccountRequest) (*pb.CreateAccountResponse, error) {
+	return gatewayCall(ctx, gateway.server, pb.BankService_CreateAccount_FullMethodName, req, gateway.service.CreateAccount)
+}
+
+func (gateway *gatewayService) GetAccount(ctx context.Context, req *pb.GetAccountRequest) (*pb.GetAccountResponse, error) {
+	return gatewayCall(ctx, gateway.server, pb.BankService_GetAccount_FullMethodName, req, gateway.service.GetAccount)
+}
+
+func (gateway *gatewayService) ListAccounts(ctx context.Context, req *pb.ListAccountsRequest) (*pb.ListAccountsResponse, error) {
+	return gatewayCall(ctx, gateway.server, pb.BankService_ListAccounts_FullMethodName, req, gateway.service.ListAccounts)
+}
+
+func (gateway *gatewayService) CreateTransfer(ctx context.Context, req *pb.CreateTransferRequest) (*pb.CreateTransferResponse, error) {
+	return gatewayCall(ctx, gateway.server, pb.BankService_CreateTransfer_FullMethodName, req, gateway.service.CreateTransfer)
+}
@@ -0,0 +1,227 @@
+package api
+
+import (
+	mockdb "BankAppGo/db/mock"
+	db "BankAppGo/db/sqlc"
+	"BankAppGo/db/util"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGatewayCreateTransfer(t *testing.T) {
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+
+	account1 := randomAccount(user1.Username)
+	account2 := randomAccount(user2.Username)
+	account2.Currency = account1.Currency
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		setupAuth     func(t *testing.T, request *http.Request, server *Server)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          10,
+				"currency":        account1.Currency,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, server *Server) {
+				addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user1.Username, util.DepositorRole, time.Minute)
+				request.Header.Set(requestIDHeaderKey, "gateway-request")
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+
+				arg := db.TransferTxParams{
+					FromAccountID: account1.ID,
+					ToAccountID:   account2.ID,
+					Amount:        10,
+					Limits:        &db.TransferLimits{},
+				}
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, _ db.TransferTxParams) (db.TransferTxResult, error) {
+						// REST calls of the gateway are audited as the gRPC ones
+						audit := db.AuditFromContext(ctx)
+						require.NotNil(t, audit)
+						require.Equal(t, user1.Username, audit.Actor)
+						require.Equal(t, "/pb.BankService/CreateTransfer", audit.Action)
+						require.Equal(t, "gateway-request", audit.RequestID)
+						require.Equal(t, "192.0.2.1", audit.ClientIP)
+
+						return db.TransferTxResult{
+							Transfer:    db.Transfer{ID: 1, FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 10, ToAmount: 10},
+							FromAccount: account1,
+							ToAccount:   account2,
+						}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "gateway-request", recorder.Header().Get(requestIDHeaderKey))
+
+				var response struct {
+					Transfer struct {
+						ID            string `json:"id"`
+						FromAccountID string `json:"from_account_id"`
+					} `json:"transfer"`
+				}
+				data, err := io.ReadAll(recorder.Body)
+				require.NoError(t, err)
+				require.NoError(t, json.Unmarshal(data, &response))
+				require.Equal(t, "1", response.Transfer.ID)
+				require.Equal(t, fmt.Sprint(account1.ID), response.Transfer.FromAccountID)
+			},
+		},
+		{
+			name: "NoAuthorization",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          10,
+				"currency":        account1.Currency,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, server *Server) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "NegativeAmount",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          -1,
+				"currency":        account1.Currency,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, server *Server) {
+				addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user1.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "MissingCurrency",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          10,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, server *Server) {
+				addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user1.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request := httptest.NewRequest(http.MethodPost, "/v1/transfers", bytes.NewReader(data))
+			tc.setupAuth(t, request, server)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestGatewayGetAccount(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	// the id is bound from the path as the google.api.http option declares it
+	request := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/v1/accounts/%d", account.ID), nil)
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var response struct {
+		Account struct {
+			ID       string `json:"id"`
+			Owner    string `json:"owner"`
+			Currency string `json:"currency"`
+		} `json:"account"`
+	}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	require.Equal(t, fmt.Sprint(account.ID), response.Account.ID)
+	require.Equal(t, account.Owner, response.Account.Owner)
+	require.Equal(t, account.Currency, response.Account.Currency)
+}
+
+func TestGatewaySwagger(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := newTestServer(t, mockdb.NewMockStore(ctrl))
+	recorder := httptest.NewRecorder()
+
+	request := httptest.NewRequest(http.MethodGet, "/v1/swagger.json", nil)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var document struct {
+		Paths map[string]map[string]any `json:"paths"`
+	}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &document))
+	require.Contains(t, document.Paths["/v1/transfers"], "post")
+	require.Contains(t, document.Paths["/v1/accounts/{id}"], "get")
+
+	// the gin routes are still served next to the gateway
+	recorder = httptest.NewRecorder()
+	request = httptest.NewRequest(http.MethodPost, "/transfers", nil)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
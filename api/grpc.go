@@ -0,0 +1,208 @@
+package api
+
+import (
+	db "BankAppGo/db/sqlc"
+	"BankAppGo/fx"
+	"BankAppGo/pb"
+	"BankAppGo/token"
+	"context"
+	"database/sql"
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// methods callable without an access token
+var grpcPublicMethods = map[string]bool{
+	pb.BankService_CreateUser_FullMethodName: true,
+	pb.BankService_LoginUser_FullMethodName:  true,
+}
+
+// methods that change nothing, so no audit event is written for them
+var grpcReadMethods = map[string]bool{
+	pb.BankService_GetAccount_FullMethodName:   true,
+	pb.BankService_ListAccounts_FullMethodName: true,
+}
+
+// grpcServer serves the BankService on top of the store, token maker and revocations of the HTTP server
+type grpcServer struct {
+	pb.UnimplementedBankServiceServer
+	*Server
+}
+
+type grpcPayloadKey struct{}
+
+// creates the gRPC server, authenticating and auditing calls through the interceptor
+func (server *Server) newGRPCServer() *grpc.Server {
+	grpcSrv := grpc.NewServer(grpc.UnaryInterceptor(server.grpcInterceptor))
+	pb.RegisterBankServiceServer(grpcSrv, &grpcServer{Server: server})
+	// lets tools such as grpcurl discover the service
+	reflection.Register(grpcSrv)
+	return grpcSrv
+}
+
+// does for unary calls what authMiddleware, revocationMiddleware, auditMiddleware and request binding do for HTTP requests
+func (server *Server) grpcInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	var payload *token.Payload
+	if !grpcPublicMethods[info.FullMethod] {
+		var err error
+		payload, err = verifyAuthorization(server.tokenMaker, grpcMetadata(ctx, authorizationHeaderKey))
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		if server.revocations.isRevoked(payload) {
+			return nil, status.Error(codes.Unauthenticated, "token has been revoked")
+		}
+		ctx = context.WithValue(ctx, grpcPayloadKey{}, payload)
+	}
+
+	if msg, ok := req.(proto.Message); ok {
+		err := validateProto(msg)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !grpcReadMethods[info.FullMethod] {
+		requestID := grpcMetadata(ctx, requestIDHeaderKey)
+		if len(requestID) == 0 || len(requestID) > maxRequestIDLength {
+			requestID = uuid.NewString()
+		}
+		grpc.SetHeader(ctx, metadata.Pairs(requestIDHeaderKey, requestID))
+
+		audit := &db.Audit{
+			Action:    info.FullMethod,
+			ClientIP:  grpcClientIP(ctx),
+			RequestID: requestID,
+		}
+		if payload != nil {
+			audit.Actor = payload.Username
+		}
+		ctx = db.WithAudit(ctx, audit)
+	}
+
+	return handler(ctx, req)
+}
+
+// the payload of the access token the interceptor verified
+func grpcAuthPayload(ctx context.Context) *token.Payload {
+	return ctx.Value(grpcPayloadKey{}).(*token.Payload)
+}
+
+// the first value of a metadata entry, keys are matched case-insensitively
+func grpcMetadata(ctx context.Context, key string) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	values := md.Get(strings.ToLower(key))
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// the address the call came from, without its port.
+// Calls of the gateway have no peer, the address is the last one of the x-forwarded-for metadata it sets
+func grpcClientIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		md, _ := metadata.FromIncomingContext(ctx)
+		forwardedFor := md.Get("x-forwarded-for")
+		if len(forwardedFor) == 0 {
+			return ""
+		}
+		addresses := strings.Split(forwardedFor[len(forwardedFor)-1], ",")
+		return strings.TrimSpace(addresses[len(addresses)-1])
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+// maps an error of the store to the status matching the one the HTTP handlers answer with
+func grpcStoreError(err error) error {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, db.ErrInsufficientFunds),
+		errors.Is(err, db.ErrTransferLimitExceeded),
+		errors.Is(err, db.ErrAmountBelowFee),
+		errors.Is(err, db.ErrAccountClosed),
+		errors.Is(err, db.ErrAccountFrozen),
+		errors.Is(err, db.ErrSystemAccount):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, fx.ErrRateNotFound), errors.Is(err, errAmountTooSmallToConvert):
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if pqError, ok := err.(*pq.Error); ok {
+		switch pqError.Code.Name() {
+		case "unique_violation":
+			return status.Error(codes.AlreadyExists, err.Error())
+		case "foreign_key_violation":
+			return status.Error(codes.FailedPrecondition, err.Error())
+		}
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+func convertUser(user db.User) *pb.User {
+	return &pb.User{
+		Username:          user.Username,
+		FullName:          user.FullName,
+		Email:             user.Email,
+		Role:              user.Role,
+		PasswordChangedAt: timestamppb.New(user.PasswordChangedAt),
+		CreatedAt:         timestamppb.New(user.CreatedAt),
+	}
+}
+
+func convertAccount(account db.Account) *pb.Account {
+	return &pb.Account{
+		Id:             account.ID,
+		Owner:          account.Owner,
+		Balance:        account.Balance,
+		Currency:       account.Currency,
+		Kind:           account.Kind,
+		Status:         account.Status,
+		OverdraftLimit: account.OverdraftLimit,
+		HeldAmount:     account.HeldAmount,
+		CreatedAt:      timestamppb.New(account.CreatedAt),
+	}
+}
+
+func convertTransfer(transfer db.Transfer) *pb.Transfer {
+	return &pb.Transfer{
+		Id:            transfer.ID,
+		FromAccountId: transfer.FromAccountID,
+		ToAccountId:   transfer.ToAccountID,
+		Amount:        transfer.Amount,
+		ToAmount:      transfer.ToAmount,
+		ExchangeRate:  transfer.ExchangeRate,
+		Fee:           transfer.Fee,
+		CreatedAt:     timestamppb.New(transfer.CreatedAt),
+	}
+}
+
+func convertEntry(entry db.Entry) *pb.Entry {
+	return &pb.Entry{
+		Id:        entry.ID,
+		AccountId: entry.AccountID,
+		Amount:    entry.Amount,
+		CreatedAt: timestamppb.New(entry.CreatedAt),
+	}
+}
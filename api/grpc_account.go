@@ -0,0 +1,64 @@
+package api
+
+import (
+	db "BankAppGo/db/sqlc"
+	"BankAppGo/db/util"
+	"BankAppGo/pb"
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *grpcServer) CreateAccount(ctx context.Context, req *pb.CreateAccountRequest) (*pb.CreateAccountResponse, error) {
+	authPayload := grpcAuthPayload(ctx)
+
+	kind := req.GetKind()
+	if kind == "" {
+		kind = util.CheckingAccount
+	}
+
+	account, err := server.store.CreateAccountTx(ctx, db.CreateAccountTxParams{
+		CreateAccountParams: db.CreateAccountParams{
+			Owner:    authPayload.Username,
+			Currency: req.GetCurrency(),
+			Balance:  0,
+			Kind:     kind,
+		},
+	})
+	if err != nil {
+		return nil, grpcStoreError(err)
+	}
+
+	return &pb.CreateAccountResponse{Account: convertAccount(account)}, nil
+}
+
+func (server *grpcServer) GetAccount(ctx context.Context, req *pb.GetAccountRequest) (*pb.GetAccountResponse, error) {
+	account, err := server.store.GetAccount(ctx, req.GetId())
+	if err != nil {
+		return nil, grpcStoreError(err)
+	}
+
+	if !canReadAccount(grpcAuthPayload(ctx), account) {
+		return nil, status.Error(codes.PermissionDenied, "account does not belong to the authenticated user")
+	}
+
+	return &pb.GetAccountResponse{Account: convertAccount(account)}, nil
+}
+
+func (server *grpcServer) ListAccounts(ctx context.Context, req *pb.ListAccountsRequest) (*pb.ListAccountsResponse, error) {
+	accounts, err := server.store.ListAccounts(ctx, db.ListAccountsParams{
+		Owner:  grpcAuthPayload(ctx).Username,
+		Limit:  req.GetPageSize(),
+		Offset: (req.GetPageId() - 1) * req.GetPageSize(),
+	})
+	if err != nil {
+		return nil, grpcStoreError(err)
+	}
+
+	response := &pb.ListAccountsResponse{Accounts: make([]*pb.Account, 0, len(accounts))}
+	for _, account := range accounts {
+		response.Accounts = append(response.Accounts, convertAccount(account))
+	}
+	return response, nil
+}
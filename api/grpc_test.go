@@ -0,0 +1,400 @@
+package api
+
+import (
+	mockdb "BankAppGo/db/mock"
+	db "BankAppGo/db/sqlc"
+	"BankAppGo/db/util"
+	"BankAppGo/pb"
+	"BankAppGo/token"
+	"context"
+	"database/sql"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// serves the gRPC API of the server in memory and returns a client of it
+func newTestGRPCClient(t *testing.T, server *Server) pb.BankServiceClient {
+	listener := bufconn.Listen(1024 * 1024)
+	grpcSrv := server.newGRPCServer()
+	go grpcSrv.Serve(listener)
+	t.Cleanup(grpcSrv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return pb.NewBankServiceClient(conn)
+}
+
+// returns a context carrying an access token in its authorization metadata
+func grpcAuthorization(t *testing.T, tokenMaker token.TokenMaker, username string, role string) context.Context {
+	accessToken, _, err := tokenMaker.CreateToken(username, role, token.TokenTypeAccess, time.Minute)
+	require.NoError(t, err)
+
+	authorization := fmt.Sprintf("%s %s", authorizationTypeBearer, accessToken)
+	return metadata.AppendToOutgoingContext(context.Background(), authorizationHeaderKey, authorization)
+}
+
+func requireStatusCode(t *testing.T, err error, code codes.Code) {
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	require.Equal(t, code, st.Code(), st.Message())
+}
+
+func TestGRPCAuthInterceptor(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+
+	testCases := []struct {
+		name       string
+		setupAuth  func(t *testing.T, server *Server) context.Context
+		buildStubs func(store *mockdb.MockStore)
+		code       codes.Code
+	}{
+		{
+			name: "OK",
+			setupAuth: func(t *testing.T, server *Server) context.Context {
+				return grpcAuthorization(t, server.tokenMaker, user.Username, util.DepositorRole)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+			},
+			code: codes.OK,
+		},
+		{
+			name: "NoAuthorization",
+			setupAuth: func(t *testing.T, server *Server) context.Context {
+				return context.Background()
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.Unauthenticated,
+		},
+		{
+			name: "UnsupportedAuthorization",
+			setupAuth: func(t *testing.T, server *Server) context.Context {
+				return metadata.AppendToOutgoingContext(context.Background(), authorizationHeaderKey, "basic abc")
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.Unauthenticated,
+		},
+		{
+			name: "RefreshToken",
+			setupAuth: func(t *testing.T, server *Server) context.Context {
+				refreshToken, _, err := server.tokenMaker.CreateToken(user.Username, util.DepositorRole, token.TokenTypeRefresh, time.Minute)
+				require.NoError(t, err)
+				authorization := fmt.Sprintf("%s %s", authorizationTypeBearer, refreshToken)
+				return metadata.AppendToOutgoingContext(context.Background(), authorizationHeaderKey, authorization)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.Unauthenticated,
+		},
+		{
+			name: "RevokedToken",
+			setupAuth: func(t *testing.T, server *Server) context.Context {
+				server.revocations.add(db.TokenRevocation{
+					Username:  user.Username,
+					RevokedAt: time.Now().Add(time.Second),
+					ExpiresAt: time.Now().Add(time.Hour),
+				})
+				return grpcAuthorization(t, server.tokenMaker, user.Username, util.DepositorRole)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.Unauthenticated,
+		},
+		{
+			name: "OtherOwner",
+			setupAuth: func(t *testing.T, server *Server) context.Context {
+				return grpcAuthorization(t, server.tokenMaker, "mallory", util.DepositorRole)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+			},
+			code: codes.PermissionDenied,
+		},
+		{
+			name: "Banker",
+			setupAuth: func(t *testing.T, server *Server) context.Context {
+				return grpcAuthorization(t, server.tokenMaker, "banker", util.BankerRole)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+			},
+			code: codes.OK,
+		},
+		{
+			name: "NotFound",
+			setupAuth: func(t *testing.T, server *Server) context.Context {
+				return grpcAuthorization(t, server.tokenMaker, user.Username, util.DepositorRole)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+			},
+			code: codes.NotFound,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			client := newTestGRPCClient(t, server)
+
+			response, err := client.GetAccount(tc.setupAuth(t, server), &pb.GetAccountRequest{Id: account.ID})
+			if tc.code != codes.OK {
+				requireStatusCode(t, err, tc.code)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, account.ID, response.GetAccount().GetId())
+			require.Equal(t, account.Owner, response.GetAccount().GetOwner())
+		})
+	}
+}
+
+func TestGRPCCreateTransfer(t *testing.T) {
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+
+	account1 := randomAccount(user1.Username)
+	account2 := randomAccount(user2.Username)
+	account2.Currency = account1.Currency
+
+	testCases := []struct {
+		name       string
+		req        *pb.CreateTransferRequest
+		buildStubs func(store *mockdb.MockStore)
+		code       codes.Code
+	}{
+		{
+			name: "OK",
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Amount:        10,
+				Currency:      account1.Currency,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+
+				arg := db.TransferTxParams{
+					FromAccountID: account1.ID,
+					ToAccountID:   account2.ID,
+					Amount:        10,
+					Limits:        &db.TransferLimits{},
+				}
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, _ db.TransferTxParams) (db.TransferTxResult, error) {
+						// the transfer is audited in its transaction, as the HTTP one is
+						audit := db.AuditFromContext(ctx)
+						require.NotNil(t, audit)
+						require.Equal(t, user1.Username, audit.Actor)
+						require.Equal(t, pb.BankService_CreateTransfer_FullMethodName, audit.Action)
+						require.NotEmpty(t, audit.RequestID)
+
+						return db.TransferTxResult{
+							Transfer:    db.Transfer{ID: 1, FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 10, ToAmount: 10},
+							FromAccount: account1,
+							ToAccount:   account2,
+						}, nil
+					})
+			},
+			code: codes.OK,
+		},
+		{
+			name: "InvalidCurrency",
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Amount:        10,
+				Currency:      "XYZ",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "NegativeAmount",
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Amount:        -1,
+				Currency:      account1.Currency,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "FromAccountOfOtherUser",
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account2.ID,
+				ToAccountId:   account1.ID,
+				Amount:        10,
+				Currency:      account2.Currency,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.PermissionDenied,
+		},
+		{
+			name: "InsufficientFunds",
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Amount:        10,
+				Currency:      account1.Currency,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, db.ErrInsufficientFunds)
+			},
+			code: codes.FailedPrecondition,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			client := newTestGRPCClient(t, server)
+
+			ctx := grpcAuthorization(t, server.tokenMaker, user1.Username, util.DepositorRole)
+			response, err := client.CreateTransfer(ctx, tc.req)
+			if tc.code != codes.OK {
+				requireStatusCode(t, err, tc.code)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, int64(1), response.GetTransfer().GetId())
+			require.Equal(t, account1.ID, response.GetFromAccount().GetId())
+		})
+	}
+}
+
+func TestGRPCLoginUser(t *testing.T) {
+	user, password := randomUser(t)
+
+	testCases := []struct {
+		name       string
+		password   string
+		buildStubs func(store *mockdb.MockStore)
+		code       codes.Code
+	}{
+		{
+			name:     "OK",
+			password: password,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().
+					CreateSessionTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.CreateSessionParams) (db.Session, error) {
+						// nobody is authenticated yet, the login is audited without an actor
+						audit := db.AuditFromContext(ctx)
+						require.NotNil(t, audit)
+						require.Empty(t, audit.Actor)
+						require.Equal(t, user.Username, arg.Username)
+						return db.Session{ID: arg.ID, Username: arg.Username, ExpiresAt: arg.ExpiresAt}, nil
+					})
+			},
+			code: codes.OK,
+		},
+		{
+			name:     "WrongPassword",
+			password: "wrong-password",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().CreateSessionTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.Unauthenticated,
+		},
+		{
+			name:     "UserNotFound",
+			password: password,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(1).Return(db.User{}, sql.ErrNoRows)
+			},
+			code: codes.NotFound,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			client := newTestGRPCClient(t, server)
+
+			response, err := client.LoginUser(context.Background(), &pb.LoginUserRequest{
+				Username: user.Username,
+				Password: tc.password,
+			})
+			if tc.code != codes.OK {
+				requireStatusCode(t, err, tc.code)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, user.Username, response.GetUser().GetUsername())
+
+			// the access token works on the authenticated methods
+			payload, err := server.tokenMaker.VerifyToken(response.GetAccessToken())
+			require.NoError(t, err)
+			require.Equal(t, user.Username, payload.Username)
+		})
+	}
+}
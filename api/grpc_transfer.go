@@ -0,0 +1,66 @@
+package api
+
+import (
+	db "BankAppGo/db/sqlc"
+	"BankAppGo/pb"
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *grpcServer) CreateTransfer(ctx context.Context, req *pb.CreateTransferRequest) (*pb.CreateTransferResponse, error) {
+	arg := transferRequest{
+		FromAccountID: req.GetFromAccountId(),
+		ToAccountID:   req.GetToAccountId(),
+		Amount:        req.GetAmount(),
+		Currency:      req.GetCurrency(),
+	}
+	account, err := server.store.GetAccount(ctx, arg.FromAccountID)
+	if err != nil {
+		return nil, grpcStoreError(err)
+	}
+	if account.Currency != arg.Currency {
+		err := fmt.Errorf("account [%d] currency mismatch: %s vs %s", account.ID, account.Currency, arg.Currency)
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if account.Owner != grpcAuthPayload(ctx).Username {
+		return nil, status.Error(codes.PermissionDenied, "from account doesn't belong to the authenticated user")
+	}
+
+	toAccount, err := server.store.GetAccount(ctx, arg.ToAccountID)
+	if err != nil {
+		return nil, grpcStoreError(err)
+	}
+
+	var result db.TransferTxResult
+	if toAccount.Currency == arg.Currency {
+		result, err = server.store.TransferTx(ctx, db.TransferTxParams{
+			FromAccountID: arg.FromAccountID,
+			ToAccountID:   arg.ToAccountID,
+			Amount:        arg.Amount,
+			Limits:        server.defaultTransferLimits(),
+		})
+	} else {
+		// converts the amount into the destination currency at the current rate
+		var exchange db.ExchangeTransferTxParams
+		exchange, err = server.exchangeTransferParams(ctx, arg, toAccount.Currency)
+		if err != nil {
+			return nil, grpcStoreError(err)
+		}
+		exchange.Limits = server.defaultTransferLimits()
+		result, err = server.store.ExchangeTransferTx(ctx, exchange)
+	}
+	if err != nil {
+		return nil, grpcStoreError(err)
+	}
+
+	return &pb.CreateTransferResponse{
+		Transfer:    convertTransfer(result.Transfer),
+		FromAccount: convertAccount(result.FromAccount),
+		ToAccount:   convertAccount(result.ToAccount),
+		FromEntry:   convertEntry(result.FromEntry),
+		ToEntry:     convertEntry(result.ToEntry),
+	}, nil
+}
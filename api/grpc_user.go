@@ -0,0 +1,77 @@
+package api
+
+import (
+	db "BankAppGo/db/sqlc"
+	"BankAppGo/db/util"
+	"BankAppGo/pb"
+	"BankAppGo/token"
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (server *grpcServer) CreateUser(ctx context.Context, req *pb.CreateUserRequest) (*pb.CreateUserResponse, error) {
+	hashedPassword, err := util.HashPassword(req.GetPassword())
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	user, err := server.store.CreateUserTx(ctx, db.CreateUserParams{
+		Username:       req.GetUsername(),
+		HashedPassword: hashedPassword,
+		FullName:       req.GetFullName(),
+		Email:          req.GetEmail(),
+	})
+	if err != nil {
+		return nil, grpcStoreError(err)
+	}
+
+	return &pb.CreateUserResponse{User: convertUser(user)}, nil
+}
+
+func (server *grpcServer) LoginUser(ctx context.Context, req *pb.LoginUserRequest) (*pb.LoginUserResponse, error) {
+	user, err := server.store.GetUser(ctx, req.GetUsername())
+	if err != nil {
+		return nil, grpcStoreError(err)
+	}
+
+	err = util.CheckPassword(req.GetPassword(), user.HashedPassword)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	accessToken, accessPayload, err := server.tokenMaker.CreateToken(user.Username, user.Role, token.TokenTypeAccess, server.config.AccessTokenDuration)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	refreshToken, refreshPayload, err := server.tokenMaker.CreateToken(user.Username, user.Role, token.TokenTypeRefresh, server.config.RefreshTokenDuration)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	// the refresh token is only honoured while its session exists and is not blocked
+	session, err := server.store.CreateSessionTx(ctx, db.CreateSessionParams{
+		ID:           refreshPayload.ID,
+		Username:     user.Username,
+		RefreshToken: refreshToken,
+		UserAgent:    grpcMetadata(ctx, "user-agent"),
+		ClientIp:     grpcClientIP(ctx),
+		IsBlocked:    false,
+		ExpiresAt:    refreshPayload.ExpiredAt,
+	})
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &pb.LoginUserResponse{
+		User:                  convertUser(user),
+		SessionId:             session.ID.String(),
+		AccessToken:           accessToken,
+		RefreshToken:          refreshToken,
+		AccessTokenExpiresAt:  timestamppb.New(accessPayload.ExpiredAt),
+		RefreshTokenExpiresAt: timestamppb.New(refreshPayload.ExpiredAt),
+	}, nil
+}
@@ -0,0 +1,118 @@
+package api
+
+import (
+	"fmt"
+	"net/mail"
+	"regexp"
+	"slices"
+	"sync"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/protoc-gen-openapiv2/options"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// patterns of the openapiv2 field options, compiled once
+var protoPatterns sync.Map
+
+// checks a request against the openapiv2 options of its message, the same options the OpenAPI document is generated from.
+// Fields listed as required must not be zero, the field constraints only apply to fields that are set
+func validateProto(msg proto.Message) error {
+	message := msg.ProtoReflect()
+	fields := message.Descriptor().Fields()
+
+	if schema, ok := proto.GetExtension(message.Descriptor().Options(), options.E_Openapiv2Schema).(*options.Schema); ok {
+		for _, name := range schema.GetJsonSchema().GetRequired() {
+			field := fields.ByName(protoreflect.Name(name))
+			if field != nil && !message.Has(field) {
+				return status.Errorf(codes.InvalidArgument, "%s is required", name)
+			}
+		}
+	}
+
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+		if !message.Has(field) {
+			continue
+		}
+		rules, ok := proto.GetExtension(field.Options(), options.E_Openapiv2Field).(*options.JSONSchema)
+		if !ok || rules == nil {
+			continue
+		}
+
+		var err error
+		switch field.Kind() {
+		case protoreflect.StringKind:
+			err = validateProtoString(message.Get(field).String(), rules)
+		case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+			protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+			err = validateProtoNumber(float64(message.Get(field).Int()), rules)
+		case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+			err = validateProtoNumber(float64(message.Get(field).Uint()), rules)
+		case protoreflect.FloatKind, protoreflect.DoubleKind:
+			err = validateProtoNumber(message.Get(field).Float(), rules)
+		}
+		if err != nil {
+			return status.Errorf(codes.InvalidArgument, "%s %s", field.Name(), err)
+		}
+	}
+	return nil
+}
+
+func validateProtoString(value string, rules *options.JSONSchema) error {
+	length := uint64(len([]rune(value)))
+	if rules.GetMinLength() > 0 && length < rules.GetMinLength() {
+		return fmt.Errorf("must be at least %d characters long", rules.GetMinLength())
+	}
+	if rules.GetMaxLength() > 0 && length > rules.GetMaxLength() {
+		return fmt.Errorf("must be at most %d characters long", rules.GetMaxLength())
+	}
+	if len(rules.GetEnum()) > 0 && !slices.Contains(rules.GetEnum(), value) {
+		return fmt.Errorf("must be one of %v", rules.GetEnum())
+	}
+	if rules.GetFormat() == "email" {
+		address, err := mail.ParseAddress(value)
+		if err != nil || address.Address != value {
+			return fmt.Errorf("must be an email address")
+		}
+	}
+	if len(rules.GetPattern()) > 0 {
+		pattern, err := protoPattern(rules.GetPattern())
+		if err != nil {
+			return err
+		}
+		if !pattern.MatchString(value) {
+			return fmt.Errorf("must match %s", rules.GetPattern())
+		}
+	}
+	return nil
+}
+
+// a zero minimum or maximum is read as unset, proto3 can't tell the two apart
+func validateProtoNumber(value float64, rules *options.JSONSchema) error {
+	if rules.GetMinimum() != 0 {
+		if value < rules.GetMinimum() || (rules.GetExclusiveMinimum() && value == rules.GetMinimum()) {
+			return fmt.Errorf("must be at least %v", rules.GetMinimum())
+		}
+	}
+	if rules.GetMaximum() != 0 {
+		if value > rules.GetMaximum() || (rules.GetExclusiveMaximum() && value == rules.GetMaximum()) {
+			return fmt.Errorf("must be at most %v", rules.GetMaximum())
+		}
+	}
+	return nil
+}
+
+func protoPattern(expr string) (*regexp.Regexp, error) {
+	if pattern, ok := protoPatterns.Load(expr); ok {
+		return pattern.(*regexp.Regexp), nil
+	}
+	pattern, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	protoPatterns.Store(expr, pattern)
+	return pattern, nil
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"BankAppGo/db/util"
+	"BankAppGo/pb"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/protoc-gen-openapiv2/options"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestValidateProto(t *testing.T) {
+	testCases := []struct {
+		name string
+		msg  proto.Message
+		code codes.Code
+	}{
+		{
+			name: "OK",
+			msg:  &pb.CreateUserRequest{Username: "alice1", Password: "secret", FullName: "Alice", Email: "alice@example.com"},
+			code: codes.OK,
+		},
+		{
+			name: "MissingRequired",
+			msg:  &pb.CreateUserRequest{Username: "alice1", Password: "secret", Email: "alice@example.com"},
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "PatternMismatch",
+			msg:  &pb.CreateUserRequest{Username: "alice-1", Password: "secret", FullName: "Alice", Email: "alice@example.com"},
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "TooShort",
+			msg:  &pb.CreateUserRequest{Username: "alice1", Password: "short", FullName: "Alice", Email: "alice@example.com"},
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "InvalidEmail",
+			msg:  &pb.CreateUserRequest{Username: "alice1", Password: "secret", FullName: "Alice", Email: "Alice <alice@example.com>"},
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "NotInEnum",
+			msg:  &pb.CreateAccountRequest{Currency: util.USD, Kind: "loan"},
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "OptionalUnset",
+			msg:  &pb.CreateAccountRequest{Currency: util.USD},
+			code: codes.OK,
+		},
+		{
+			name: "BelowMinimum",
+			msg:  &pb.ListAccountsRequest{PageId: 1, PageSize: 4},
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "AboveMaximum",
+			msg:  &pb.ListAccountsRequest{PageId: 1, PageSize: 11},
+			code: codes.InvalidArgument,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateProto(tc.msg)
+			if tc.code == codes.OK {
+				require.NoError(t, err)
+				return
+			}
+			requireStatusCode(t, err, tc.code)
+		})
+	}
+}
+
+// the currencies the protos accept are the ones the currency binding of the gin routes accepts
+func TestValidateProtoCurrencies(t *testing.T) {
+	for _, msg := range []proto.Message{&pb.CreateAccountRequest{}, &pb.CreateTransferRequest{}} {
+		field := msg.ProtoReflect().Descriptor().Fields().ByName("currency")
+		require.NotNil(t, field)
+
+		rules := proto.GetExtension(field.Options(), options.E_Openapiv2Field).(*options.JSONSchema)
+		for _, currency := range rules.GetEnum() {
+			require.True(t, util.IsSupportedCurrency(currency), currency)
+		}
+		require.Len(t, rules.GetEnum(), 11)
+	}
+}
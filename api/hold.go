@@ -0,0 +1,255 @@
+package api
+
+import (
+	db "BankAppGo/db/sqlc"
+	"BankAppGo/db/util"
+	"BankAppGo/token"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type authorizeTransferRequest struct {
+	FromAccountID int64  `form:"from_account_id" binding:"required,min=1"`
+	ToAccountID   int64  `form:"to_account_id" binding:"required,min=1"`
+	Amount        int64  `form:"amount" binding:"required,gt=0"`
+	Currency      string `form:"currency" binding:"required,currency"`
+}
+
+// reserves money on the caller's account for a transfer captured or voided later
+func (server *Server) authorizeTransfer(ctx *gin.Context) {
+	var req authorizeTransferRequest
+
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	account, valid := server.validate_account(ctx, req.FromAccountID, req.Currency)
+	if !valid {
+		return
+	}
+
+	if account.Owner != authPayload.Username {
+		err := errors.New("from account doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	// the rate is not known until capture, so holds stay within one currency
+	if _, valid := server.validate_account(ctx, req.ToAccountID, req.Currency); !valid {
+		return
+	}
+
+	idempotency, err := newIdempotency(ctx, authPayload.Username, req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	ttl := server.config.HoldTTL
+	if ttl <= 0 {
+		ttl = defaultHoldTTL
+	}
+
+	hold, err := server.store.AuthorizeTransferTx(ctx, db.AuthorizeTransferTxParams{
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+		ExpiresAt:     time.Now().Add(ttl),
+		Limits:        server.defaultTransferLimits(),
+		Idempotency:   idempotency,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrInsufficientFunds), errors.Is(err, db.ErrTransferLimitExceeded):
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+			return
+		case errors.Is(err, db.ErrAccountClosed), errors.Is(err, db.ErrSystemAccount):
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		case errors.Is(err, db.ErrAccountFrozen):
+			ctx.JSON(http.StatusLocked, errorResponse(err))
+			return
+		case errors.Is(err, db.ErrIdempotencyKeyReused):
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, hold)
+}
+
+type holdURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type captureHoldRequest struct {
+	// the whole hold when empty
+	Amount int64 `form:"amount" binding:"min=0"`
+}
+
+// settles a hold with a transfer, releasing what is left of it
+func (server *Server) captureHold(ctx *gin.Context) {
+	var uri holdURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req captureHoldRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	hold, valid := server.lookupHold(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	// the payee captures the hold, as a merchant captures a card authorization
+	if !server.canSettleHold(ctx, hold, hold.ToAccountID) {
+		return
+	}
+
+	result, err := server.store.CaptureHoldTx(ctx, db.CaptureHoldTxParams{
+		HoldID: uri.ID,
+		Amount: req.Amount,
+		Limits: server.defaultTransferLimits(),
+	})
+	if err != nil {
+		settleHoldError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// releases a hold without moving any money
+func (server *Server) voidHold(ctx *gin.Context) {
+	var uri holdURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	hold, valid := server.lookupHold(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	// the payer who placed the hold may release it
+	if !server.canSettleHold(ctx, hold, hold.FromAccountID) {
+		return
+	}
+
+	hold, err := server.store.VoidHoldTx(ctx, uri.ID)
+	if err != nil {
+		settleHoldError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, hold)
+}
+
+// maps the errors of capturing or voiding a hold to a response
+func settleHoldError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, db.ErrHoldNotPending), errors.Is(err, db.ErrHoldExpired):
+		ctx.JSON(http.StatusConflict, errorResponse(err))
+	case errors.Is(err, db.ErrCaptureExceedsHold):
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+	case errors.Is(err, db.ErrInsufficientFunds), errors.Is(err, db.ErrTransferLimitExceeded), errors.Is(err, db.ErrAmountBelowFee):
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+	case errors.Is(err, db.ErrAccountClosed), errors.Is(err, db.ErrSystemAccount):
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+	case errors.Is(err, db.ErrAccountFrozen):
+		ctx.JSON(http.StatusLocked, errorResponse(err))
+	default:
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+	}
+}
+
+func (server *Server) lookupHold(ctx *gin.Context, id int64) (db.Hold, bool) {
+	hold, err := server.store.GetHold(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return db.Hold{}, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return db.Hold{}, false
+	}
+
+	return hold, true
+}
+
+// a hold is settled by the owner of one of its accounts, given by accountID, or by a banker or admin
+func (server *Server) canSettleHold(ctx *gin.Context, hold db.Hold, accountID int64) bool {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if hasRole(authPayload, util.BankerRole, util.AdminRole) {
+		return true
+	}
+
+	account, valid := server.lookupAccount(ctx, accountID)
+	if !valid {
+		return false
+	}
+	if account.Owner == authPayload.Username {
+		return true
+	}
+
+	err := fmt.Errorf("hold %d can only be settled by the owner of account %d", hold.ID, accountID)
+	ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+	return false
+}
+
+// action of the audit events written when holds expire, nobody is authenticated
+const holdExpiryAction = "expire holds"
+
+// releases every hold that expired, until none is left or one cannot be released
+func expireDueHolds(ctx context.Context, store db.Store, now time.Time) (int, error) {
+	// the holds released by one run share a request id
+	ctx = db.WithAudit(ctx, &db.Audit{Action: holdExpiryAction, RequestID: uuid.NewString()})
+	expired := 0
+	for {
+		_, err := store.ExpireHoldTx(ctx, now)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return expired, nil
+			}
+			return expired, err
+		}
+		expired++
+	}
+}
+
+// releases expired holds every interval until ctx is done
+func expireHolds(ctx context.Context, store db.Store, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_, err := expireDueHolds(ctx, store, time.Now())
+			if err != nil {
+				log.Println("cannot expire holds:", err)
+			}
+		}
+	}
+}
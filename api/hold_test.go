@@ -0,0 +1,450 @@
+package api
+
+import (
+	mockdb "BankAppGo/db/mock"
+	db "BankAppGo/db/sqlc"
+	"BankAppGo/db/util"
+	"BankAppGo/token"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthorizeTransferAPI(t *testing.T) {
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+
+	account1 := randomAccount(user1.Username)
+	account1.Currency = util.USD
+	account2 := randomAccount(user2.Username)
+	account2.Currency = util.USD
+
+	hold := randomHold(account1.ID, account2.ID)
+	body := gin.H{
+		"from_account_id": account1.ID,
+		"to_account_id":   account2.ID,
+		"amount":          hold.Amount,
+		"currency":        util.USD,
+	}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: body,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().
+					AuthorizeTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.AuthorizeTransferTxParams) (db.Hold, error) {
+						require.Equal(t, account1.ID, arg.FromAccountID)
+						require.Equal(t, account2.ID, arg.ToAccountID)
+						require.Equal(t, hold.Amount, arg.Amount)
+						require.WithinDuration(t, time.Now().Add(defaultHoldTTL), arg.ExpiresAt, time.Minute)
+						require.Nil(t, arg.Idempotency)
+						require.NotNil(t, arg.Limits)
+						return hold, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "InsufficientFunds",
+			body: body,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().AuthorizeTransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.Hold{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name: "TransferLimitExceeded",
+			body: body,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().AuthorizeTransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.Hold{}, db.ErrTransferLimitExceeded)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name: "UnauthorizedUser",
+			body: body,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user2.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().AuthorizeTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "CurrencyMismatch",
+			body: body,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				other := account2
+				other.Currency = util.EUR
+
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(other, nil)
+				store.EXPECT().AuthorizeTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidAmount",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          -1,
+				"currency":        util.USD,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().AuthorizeTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data := GinHToURLValues(tc.body)
+			request, err := http.NewRequest(http.MethodPost, "/transfers/authorize", strings.NewReader(data.Encode()))
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestSettleHoldAPI(t *testing.T) {
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+	banker, _ := randomUser(t)
+
+	account1 := randomAccount(user1.Username)
+	account2 := randomAccount(user2.Username)
+	hold := randomHold(account1.ID, account2.ID)
+
+	testCases := []struct {
+		name          string
+		action        string
+		body          gin.H
+		username      string
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "Capture",
+			action:   "capture",
+			username: user2.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.CaptureHoldTxParams{HoldID: hold.ID, Limits: &db.TransferLimits{}}
+
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().CaptureHoldTx(gomock.Any(), gomock.Eq(arg)).Times(1)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "PartialCapture",
+			action:   "capture",
+			body:     gin.H{"amount": 1},
+			username: user2.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.CaptureHoldTxParams{HoldID: hold.ID, Amount: 1, Limits: &db.TransferLimits{}}
+
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().CaptureHoldTx(gomock.Any(), gomock.Eq(arg)).Times(1)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "CaptureByBanker",
+			action:   "capture",
+			username: banker.Username,
+			role:     util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CaptureHoldTx(gomock.Any(), gomock.Any()).Times(1)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "CaptureByPayer",
+			action:   "capture",
+			username: user1.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().CaptureHoldTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "CaptureExceedsHold",
+			action:   "capture",
+			body:     gin.H{"amount": hold.Amount + 1},
+			username: user2.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().CaptureHoldTx(gomock.Any(), gomock.Any()).Times(1).Return(db.CaptureHoldTxResult{}, db.ErrCaptureExceedsHold)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "CaptureBelowFee",
+			action:   "capture",
+			body:     gin.H{"amount": 1},
+			username: user2.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().CaptureHoldTx(gomock.Any(), gomock.Any()).Times(1).Return(db.CaptureHoldTxResult{}, db.ErrAmountBelowFee)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name:     "CaptureExpired",
+			action:   "capture",
+			username: user2.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().CaptureHoldTx(gomock.Any(), gomock.Any()).Times(1).Return(db.CaptureHoldTxResult{}, db.ErrHoldExpired)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:     "Void",
+			action:   "void",
+			username: user1.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				voided := hold
+				voided.Status = util.HoldVoided
+
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().VoidHoldTx(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(voided, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "VoidByBanker",
+			action:   "void",
+			username: banker.Username,
+			role:     util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().VoidHoldTx(gomock.Any(), gomock.Eq(hold.ID)).Times(1)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "VoidByPayee",
+			action:   "void",
+			username: user2.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().VoidHoldTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "VoidSettled",
+			action:   "void",
+			username: user1.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().VoidHoldTx(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(db.Hold{}, db.ErrHoldNotPending)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:     "UnauthorizedUser",
+			action:   "void",
+			username: "unauthorized",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().VoidHoldTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "NotFound",
+			action:   "capture",
+			username: user2.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(db.Hold{}, sql.ErrNoRows)
+				store.EXPECT().CaptureHoldTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data := GinHToURLValues(tc.body)
+			url := fmt.Sprintf("/transfers/%d/%s", hold.ID, tc.action)
+			request, err := http.NewRequest(http.MethodPost, url, strings.NewReader(data.Encode()))
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			role := tc.role
+			if role == "" {
+				role = util.DepositorRole
+			}
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestExpireDueHolds(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Now()
+	store := mockdb.NewMockStore(ctrl)
+	gomock.InOrder(
+		store.EXPECT().
+			ExpireHoldTx(gomock.Any(), gomock.Eq(now)).
+			Times(2).
+			DoAndReturn(func(ctx context.Context, _ time.Time) (db.Hold, error) {
+				// the releases are audited though nobody is authenticated
+				audit := db.AuditFromContext(ctx)
+				require.NotNil(t, audit)
+				require.Equal(t, holdExpiryAction, audit.Action)
+				require.Empty(t, audit.Actor)
+				return db.Hold{}, nil
+			}),
+		store.EXPECT().ExpireHoldTx(gomock.Any(), gomock.Eq(now)).Times(1).Return(db.Hold{}, sql.ErrNoRows),
+	)
+
+	expired, err := expireDueHolds(context.Background(), store, now)
+	require.NoError(t, err)
+	require.Equal(t, 2, expired)
+
+	store.EXPECT().ExpireHoldTx(gomock.Any(), gomock.Eq(now)).Times(1).Return(db.Hold{}, errors.New("connection reset"))
+
+	expired, err = expireDueHolds(context.Background(), store, now)
+	require.Error(t, err)
+	require.Zero(t, expired)
+}
+
+func randomHold(fromAccountID int64, toAccountID int64) db.Hold {
+	return db.Hold{
+		ID:            util.RandomInt(1, 1000),
+		FromAccountID: fromAccountID,
+		ToAccountID:   toAccountID,
+		Amount:        util.RandomInt(10, 100),
+		Status:        util.HoldPending,
+		ExpiresAt:     time.Now().Add(time.Hour),
+	}
+}
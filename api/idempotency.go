@@ -0,0 +1,42 @@
+package api
+
+import (
+	db "BankAppGo/db/sqlc"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	idempotencyKeyHeader    = "Idempotency-Key"
+	maxIdempotencyKeyLength = 255
+)
+
+// builds the idempotency parameters of a request from its Idempotency-Key header.
+// Returns nil when the client did not send a key
+func newIdempotency(ctx *gin.Context, username string, req any) (*db.Idempotency, error) {
+	key := ctx.GetHeader(idempotencyKeyHeader)
+	if len(key) == 0 {
+		return nil, nil
+	}
+	if len(key) > maxIdempotencyKeyLength {
+		return nil, fmt.Errorf("%s header must be at most %d characters", idempotencyKeyHeader, maxIdempotencyKeyLength)
+	}
+
+	// fingerprint the bound request so a reused key with a different body is detected
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	hash := sha256.Sum256(body)
+
+	return &db.Idempotency{
+		Key:         key,
+		Username:    username,
+		RequestPath: ctx.Request.Method + " " + ctx.FullPath(),
+		RequestHash: hex.EncodeToString(hash[:]),
+	}, nil
+}
@@ -0,0 +1,81 @@
+package api
+
+import (
+	db "BankAppGo/db/sqlc"
+	"BankAppGo/db/util"
+	"BankAppGo/token"
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type interestRateURI struct {
+	Currency string `uri:"currency" binding:"required,currency"`
+	Kind     string `uri:"kind" binding:"required,oneof=checking savings"`
+}
+
+type setInterestRateRequest struct {
+	// decimal fraction paid over a year, "0.035" is 3.5%
+	AnnualRate string `form:"annual_rate" binding:"required"`
+}
+
+// sets the annual rate earned by accounts of a currency and kind, accruals from then on use it
+func (server *Server) setInterestRate(ctx *gin.Context) {
+	var uri interestRateURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req setInterestRateRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	_, err := util.ParseInterestRate(req.AnnualRate)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	interestRate, err := server.store.SetInterestRateTx(ctx, db.UpsertInterestRateParams{
+		Currency:    uri.Currency,
+		AccountKind: uri.Kind,
+		AnnualRate:  req.AnnualRate,
+		UpdatedBy:   authPayload.Username,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, interestRate)
+}
+
+func (server *Server) getInterestRate(ctx *gin.Context) {
+	var uri interestRateURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	interestRate, err := server.store.GetInterestRate(ctx, db.GetInterestRateParams{
+		Currency:    uri.Currency,
+		AccountKind: uri.Kind,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, interestRate)
+}
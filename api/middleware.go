@@ -1,6 +1,7 @@
 package api
 
 import (
+	db "BankAppGo/db/sqlc"
 	"BankAppGo/token"
 	"errors"
 	"fmt"
@@ -8,45 +9,125 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 const (
 	authorizationHeaderKey  = "authorization"
 	authorizationTypeBearer = "bearer"
 	authorizationPayloadKey = "authorization_payload"
+	requestIDHeaderKey      = "X-Request-ID"
 )
 
+// longest request id taken from a client, longer ones are replaced by a generated id
+const maxRequestIDLength = 128
+
 func authMiddleware(tokenMaker token.TokenMaker) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		authorizationHeader := ctx.GetHeader(authorizationHeaderKey)
-		if len(authorizationHeader) == 0 {
-			err := errors.New("authorization header is not provided")
+		payload, err := verifyAuthorization(tokenMaker, ctx.GetHeader(authorizationHeaderKey))
+		if err != nil {
 			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
 			return
 		}
 
-		fields := strings.Fields(authorizationHeader)
-		if len(fields) < 2 {
-			err := errors.New("invalid authorization header format")
+		ctx.Set(authorizationPayloadKey, payload)
+		ctx.Next()
+	}
+}
+
+// verifies the access token of a "Bearer <token>" authorization header or gRPC metadata entry
+func verifyAuthorization(tokenMaker token.TokenMaker, authorizationHeader string) (*token.Payload, error) {
+	if len(authorizationHeader) == 0 {
+		return nil, errors.New("authorization header is not provided")
+	}
+
+	fields := strings.Fields(authorizationHeader)
+	if len(fields) < 2 {
+		return nil, errors.New("invalid authorization header format")
+	}
+
+	authorizationType := strings.ToLower(fields[0])
+	if authorizationType != authorizationTypeBearer {
+		return nil, fmt.Errorf("unsupported authorization type %s", authorizationType)
+	}
+
+	accessToken := fields[1]
+	payload, err := tokenMaker.VerifyToken(accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := payload.CheckType(token.TokenTypeAccess); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// refuses tokens that were revoked before they expired, must run after authMiddleware
+func revocationMiddleware(revocations *revocationCache) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		payload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+		if revocations.isRevoked(payload) {
+			err := errors.New("token has been revoked")
 			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
 			return
 		}
 
-		authorizationType := strings.ToLower(fields[0])
-		if authorizationType != authorizationTypeBearer {
-			err := fmt.Errorf("unsupported authorization type %s", authorizationType)
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+		ctx.Next()
+	}
+}
+
+// lets the request through only if the authenticated user holds one of the roles, must run after authMiddleware
+func requireRoles(roles ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		payload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+		if !hasRole(payload, roles...) {
+			err := fmt.Errorf("role %q is not allowed to perform this operation", payload.Role)
+			ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(err))
 			return
 		}
 
-		accessToken := fields[1]
-		payload, err := tokenMaker.VerifyToken(accessToken)
-		if err != nil {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+		ctx.Next()
+	}
+}
+
+// reports whether the token was issued to a user holding one of the roles
+func hasRole(payload *token.Payload, roles ...string) bool {
+	for _, role := range roles {
+		if payload.Role == role {
+			return true
+		}
+	}
+	return false
+}
+
+// attaches who makes a state-changing request to its context, so that every transaction the
+// request runs writes an audit event before it commits. Must run after authMiddleware on
+// authenticated routes, on public ones the event is written without an actor
+func auditMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		switch ctx.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			ctx.Next()
 			return
 		}
 
-		ctx.Set(authorizationPayloadKey, payload)
+		requestID := ctx.GetHeader(requestIDHeaderKey)
+		if len(requestID) == 0 || len(requestID) > maxRequestIDLength {
+			requestID = uuid.NewString()
+		}
+		ctx.Header(requestIDHeaderKey, requestID)
+
+		audit := &db.Audit{
+			Action:    ctx.Request.Method + " " + ctx.FullPath(),
+			ClientIP:  ctx.ClientIP(),
+			RequestID: requestID,
+		}
+		if payload, ok := ctx.Get(authorizationPayloadKey); ok {
+			audit.Actor = payload.(*token.Payload).Username
+		}
+
+		ctx.Request = ctx.Request.WithContext(db.WithAudit(ctx.Request.Context(), audit))
 		ctx.Next()
 	}
 }
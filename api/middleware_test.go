@@ -1,6 +1,8 @@
 package api
 
 import (
+	db "BankAppGo/db/sqlc"
+	"BankAppGo/db/util"
 	"BankAppGo/token"
 	"fmt"
 	"net/http"
@@ -18,10 +20,12 @@ func addAuthorization(
 	tokenMaker token.TokenMaker,
 	authorizationType string,
 	username string,
+	role string,
 	duration time.Duration,
 ) {
-	token, err := tokenMaker.CreateToken(username, duration)
+	token, payload, err := tokenMaker.CreateToken(username, role, token.TokenTypeAccess, duration)
 	require.NoError(t, err)
+	require.NotEmpty(t, payload)
 
 	authorizationHeader := fmt.Sprintf("%s %s", authorizationType, token)
 	request.Header.Set(authorizationHeaderKey, authorizationHeader)
@@ -36,7 +40,7 @@ func TestAuthMid(t *testing.T) {
 		{
 			name: "OK",
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "user", time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "user", util.DepositorRole, time.Minute)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
@@ -53,7 +57,7 @@ func TestAuthMid(t *testing.T) {
 		{
 			name: "UnsupportedAuthorization",
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
-				addAuthorization(t, request, tokenMaker, "unsupported", "user", time.Minute)
+				addAuthorization(t, request, tokenMaker, "unsupported", "user", util.DepositorRole, time.Minute)
 
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
@@ -64,7 +68,7 @@ func TestAuthMid(t *testing.T) {
 		{
 			name: "InvalidAuthorizationFormat",
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
-				addAuthorization(t, request, tokenMaker, "", "user", time.Minute)
+				addAuthorization(t, request, tokenMaker, "", "user", util.DepositorRole, time.Minute)
 
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
@@ -74,13 +78,24 @@ func TestAuthMid(t *testing.T) {
 		{
 			name: "ExpiredToken",
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "user", -time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "user", util.DepositorRole, -time.Minute)
 
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
 			},
 		},
+		{
+			name: "RefreshToken",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				refreshToken, _, err := tokenMaker.CreateToken("user", util.DepositorRole, token.TokenTypeRefresh, time.Minute)
+				require.NoError(t, err)
+				request.Header.Set(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationTypeBearer, refreshToken))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
 	}
 
 	for i := range testCases {
@@ -109,3 +124,152 @@ func TestAuthMid(t *testing.T) {
 		})
 	}
 }
+
+func TestRequireRoles(t *testing.T) {
+	testCases := []struct {
+		name          string
+		role          string
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			role: util.AdminRole,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "OtherAllowedRole",
+			role: util.BankerRole,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "Forbidden",
+			role: util.DepositorRole,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "NoRole",
+			role: "",
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			server := newTestServer(t, nil)
+
+			authPath := "/auth"
+			server.router.GET(
+				authPath,
+				authMiddleware(server.tokenMaker),
+				requireRoles(util.AdminRole, util.BankerRole),
+				func(ctx *gin.Context) {
+					ctx.JSON(http.StatusOK, gin.H{})
+				},
+			)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, authPath, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "user", tc.role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestAuditMid(t *testing.T) {
+	testCases := []struct {
+		name      string
+		method    string
+		requestID string
+		// public routes are audited without authentication
+		authenticated bool
+		checkAudit    func(t *testing.T, audit *db.Audit, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:          "OK",
+			method:        http.MethodPost,
+			requestID:     "request-1",
+			authenticated: true,
+			checkAudit: func(t *testing.T, audit *db.Audit, recorder *httptest.ResponseRecorder) {
+				require.NotNil(t, audit)
+				require.Equal(t, "user", audit.Actor)
+				require.Equal(t, "POST /audited", audit.Action)
+				require.Equal(t, "request-1", audit.RequestID)
+				require.Equal(t, "request-1", recorder.Header().Get(requestIDHeaderKey))
+			},
+		},
+		{
+			name:          "GeneratedRequestID",
+			method:        http.MethodDelete,
+			authenticated: true,
+			checkAudit: func(t *testing.T, audit *db.Audit, recorder *httptest.ResponseRecorder) {
+				require.NotNil(t, audit)
+				require.NotEmpty(t, audit.RequestID)
+				require.Equal(t, audit.RequestID, recorder.Header().Get(requestIDHeaderKey))
+			},
+		},
+		{
+			name:   "Unauthenticated",
+			method: http.MethodPost,
+			checkAudit: func(t *testing.T, audit *db.Audit, recorder *httptest.ResponseRecorder) {
+				require.NotNil(t, audit)
+				require.Empty(t, audit.Actor)
+			},
+		},
+		{
+			name:          "ReadOnly",
+			method:        http.MethodGet,
+			authenticated: true,
+			checkAudit: func(t *testing.T, audit *db.Audit, recorder *httptest.ResponseRecorder) {
+				require.Nil(t, audit)
+				require.Empty(t, recorder.Header().Get(requestIDHeaderKey))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			server := newTestServer(t, nil)
+
+			var audit *db.Audit
+			authPath := "/audited"
+			handlers := []gin.HandlerFunc{auditMiddleware()}
+			if tc.authenticated {
+				handlers = append([]gin.HandlerFunc{authMiddleware(server.tokenMaker)}, handlers...)
+			}
+			handlers = append(handlers, func(ctx *gin.Context) {
+				audit = db.AuditFromContext(ctx)
+				ctx.JSON(http.StatusOK, gin.H{})
+			})
+			server.router.Handle(tc.method, authPath, handlers...)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(tc.method, authPath, nil)
+			require.NoError(t, err)
+			if tc.requestID != "" {
+				request.Header.Set(requestIDHeaderKey, tc.requestID)
+			}
+
+			if tc.authenticated {
+				addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "user", util.DepositorRole, time.Minute)
+			}
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, http.StatusOK, recorder.Code)
+			tc.checkAudit(t, audit, recorder)
+		})
+	}
+}
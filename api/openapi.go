@@ -0,0 +1,742 @@
+package api
+
+import (
+	"BankAppGo/db/util"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	swaggerFiles "github.com/swaggo/files/v2"
+)
+
+const (
+	// serves the OpenAPI document of the gin routes
+	openAPIPath = "/openapi.json"
+	// serves the Swagger UI, its assets are under swaggerUIPath/assets
+	swaggerUIPath = "/docs"
+)
+
+// who may call an operation
+type apiAuth int
+
+const (
+	authPublic apiAuth = iota
+	// any valid access token
+	authUser
+	// an access token of a banker or an admin
+	authStaff
+	// an access token of an admin
+	authAdmin
+)
+
+// the roles requireRoles lets through on the routes of the level, none when any user may call them
+func (auth apiAuth) roles() []string {
+	switch auth {
+	case authStaff:
+		return []string{util.BankerRole, util.AdminRole}
+	case authAdmin:
+		return []string{util.AdminRole}
+	}
+	return nil
+}
+
+// apiOperation describes a gin route for the OpenAPI document, the request and response types are read by reflection
+type apiOperation struct {
+	summary string
+	auth    apiAuth
+	// bound by ShouldBindUri
+	uri any
+	// bound from the query string
+	query any
+	// bound from a url-encoded form body
+	form any
+	// bound by ShouldBindJSON
+	json any
+	// reads an Idempotency-Key header
+	idempotent bool
+	// the json answered on success, by status
+	responses map[int]any
+	// media types answered instead of json on success
+	produces []string
+}
+
+// a 200 answered with body
+func ok(body any) map[int]any {
+	return map[int]any{http.StatusOK: body}
+}
+
+type openAPIDocument struct {
+	OpenAPI    string                                  `json:"openapi"`
+	Info       openAPIInfo                             `json:"info"`
+	Paths      map[string]map[string]*openAPIOperation `json:"paths"`
+	Components openAPIComponents                       `json:"components"`
+	Security   []map[string][]string                   `json:"security"`
+}
+
+type openAPIInfo struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type openAPIComponents struct {
+	Schemas         map[string]*openAPISchema         `json:"schemas"`
+	Responses       map[string]*openAPIResponse       `json:"responses"`
+	SecuritySchemes map[string]*openAPISecurityScheme `json:"securitySchemes"`
+}
+
+type openAPISecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	Description  string `json:"description,omitempty"`
+}
+
+type openAPIOperation struct {
+	OperationID string                      `json:"operationId"`
+	Summary     string                      `json:"summary,omitempty"`
+	Description string                      `json:"description,omitempty"`
+	Tags        []string                    `json:"tags,omitempty"`
+	Parameters  []*openAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *openAPIRequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*openAPIResponse `json:"responses"`
+	// an empty list makes the operation public, nil keeps the security of the document
+	Security *[]map[string][]string `json:"security,omitempty"`
+}
+
+type openAPIParameter struct {
+	Name        string         `json:"name"`
+	In          string         `json:"in"`
+	Description string         `json:"description,omitempty"`
+	Required    bool           `json:"required,omitempty"`
+	Schema      *openAPISchema `json:"schema"`
+}
+
+type openAPIRequestBody struct {
+	Required bool                         `json:"required,omitempty"`
+	Content  map[string]*openAPIMediaType `json:"content"`
+}
+
+type openAPIResponse struct {
+	Ref         string                       `json:"$ref,omitempty"`
+	Description string                       `json:"description,omitempty"`
+	Content     map[string]*openAPIMediaType `json:"content,omitempty"`
+}
+
+type openAPIMediaType struct {
+	Schema *openAPISchema `json:"schema"`
+}
+
+type openAPISchema struct {
+	Ref                  string                    `json:"$ref,omitempty"`
+	AllOf                []*openAPISchema          `json:"allOf,omitempty"`
+	Type                 string                    `json:"type,omitempty"`
+	Format               string                    `json:"format,omitempty"`
+	Description          string                    `json:"description,omitempty"`
+	Nullable             bool                      `json:"nullable,omitempty"`
+	Enum                 []string                  `json:"enum,omitempty"`
+	Pattern              string                    `json:"pattern,omitempty"`
+	Minimum              *float64                  `json:"minimum,omitempty"`
+	ExclusiveMinimum     bool                      `json:"exclusiveMinimum,omitempty"`
+	Maximum              *float64                  `json:"maximum,omitempty"`
+	MinLength            *uint64                   `json:"minLength,omitempty"`
+	MaxLength            *uint64                   `json:"maxLength,omitempty"`
+	Items                *openAPISchema            `json:"items,omitempty"`
+	Properties           map[string]*openAPISchema `json:"properties,omitempty"`
+	Required             []string                  `json:"required,omitempty"`
+	AdditionalProperties *openAPISchema            `json:"additionalProperties,omitempty"`
+}
+
+// the body of every error the gin handlers answer with, see errorResponse
+type apiError struct {
+	Error string `json:"error"`
+}
+
+// routes that are not part of the document
+func undocumentedRoute(path string) bool {
+	return path == openAPIPath ||
+		path == swaggerUIPath ||
+		strings.HasPrefix(path, swaggerUIPath+"/") ||
+		strings.HasPrefix(path, gatewayPrefix+"/")
+}
+
+// builds the OpenAPI document of the routes from their operations.
+// Fails when a route has no operation, an operation has no route or a binding tag can't be described
+func newOpenAPIDocument(routes gin.RoutesInfo, operations map[string]apiOperation) (*openAPIDocument, error) {
+	builder := &openAPIBuilder{
+		schemas: map[string]*openAPISchema{},
+		types:   map[string]reflect.Type{},
+	}
+
+	document := &openAPIDocument{
+		OpenAPI: "3.0.3",
+		Info: openAPIInfo{
+			Title:       "Bank API",
+			Description: "The gin routes of the bank. The REST API generated from the protos is described at " + gatewayPrefix + "/swagger.json",
+			Version:     "1.0",
+		},
+		Paths: map[string]map[string]*openAPIOperation{},
+		Components: openAPIComponents{
+			Schemas: builder.schemas,
+			Responses: map[string]*openAPIResponse{
+				"BadRequest":   errorResponseDoc("the request did not bind: a parameter is missing or breaks its constraints"),
+				"Unauthorized": errorResponseDoc("the access token is missing, invalid, expired or revoked"),
+				"Forbidden":    errorResponseDoc("the access token does not carry a role allowed to call the operation"),
+				"Error":        errorResponseDoc("the request failed, the status tells why"),
+			},
+			SecuritySchemes: map[string]*openAPISecurityScheme{
+				"bearerAuth": {
+					Type:         "http",
+					Scheme:       "bearer",
+					BearerFormat: "PASETO",
+					Description:  "access token returned by POST /users/login",
+				},
+			},
+		},
+		Security: []map[string][]string{{"bearerAuth": {}}},
+	}
+	builder.schemas["Error"], _ = builder.object(reflect.TypeOf(apiError{}))
+
+	described := map[string]bool{}
+	for _, route := range routes {
+		if undocumentedRoute(route.Path) {
+			continue
+		}
+		key := route.Method + " " + route.Path
+		operation, ok := operations[key]
+		if !ok {
+			return nil, fmt.Errorf("route %s has no operation", key)
+		}
+		described[key] = true
+
+		doc, err := builder.operation(route, operation)
+		if err != nil {
+			return nil, fmt.Errorf("route %s: %w", key, err)
+		}
+
+		path := openAPIRoutePath(route.Path)
+		if document.Paths[path] == nil {
+			document.Paths[path] = map[string]*openAPIOperation{}
+		}
+		document.Paths[path][strings.ToLower(route.Method)] = doc
+	}
+
+	for key := range operations {
+		if !described[key] {
+			return nil, fmt.Errorf("operation %s has no route", key)
+		}
+	}
+	return document, nil
+}
+
+func errorResponseDoc(description string) *openAPIResponse {
+	return &openAPIResponse{
+		Description: description,
+		Content: map[string]*openAPIMediaType{
+			"application/json": {Schema: &openAPISchema{Ref: "#/components/schemas/Error"}},
+		},
+	}
+}
+
+// turns the :param and *param segments of a gin path into {param}
+func openAPIRoutePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// the :param and *param names of a gin path
+func routeParams(path string) []string {
+	var params []string
+	for _, segment := range strings.Split(path, "/") {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			params = append(params, segment[1:])
+		}
+	}
+	return params
+}
+
+// the method name of a handler, "BankAppGo/api.(*Server).createTransfer-fm" is createTransfer
+func handlerName(handler string) string {
+	name := handler[strings.LastIndex(handler, ".")+1:]
+	return strings.TrimSuffix(name, "-fm")
+}
+
+type openAPIBuilder struct {
+	// component schemas by name
+	schemas map[string]*openAPISchema
+	// the type each component schema was built from
+	types map[string]reflect.Type
+}
+
+func (builder *openAPIBuilder) operation(route gin.RouteInfo, operation apiOperation) (*openAPIOperation, error) {
+	doc := &openAPIOperation{
+		OperationID: handlerName(route.Handler),
+		Summary:     operation.summary,
+		Tags:        []string{strings.Split(strings.TrimPrefix(route.Path, "/"), "/")[0]},
+		Responses:   map[string]*openAPIResponse{},
+	}
+
+	// every path parameter of the route must be bound, and only those
+	pathParams, err := builder.parameters(operation.uri, "uri", "path")
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, param := range pathParams {
+		names = append(names, param.Name)
+	}
+	sort.Strings(names)
+	expected := routeParams(route.Path)
+	sort.Strings(expected)
+	if strings.Join(names, ",") != strings.Join(expected, ",") {
+		return nil, fmt.Errorf("binds path parameters %v but the route has %v", names, expected)
+	}
+	doc.Parameters = append(doc.Parameters, pathParams...)
+
+	queryParams, err := builder.parameters(operation.query, "form", "query")
+	if err != nil {
+		return nil, err
+	}
+	doc.Parameters = append(doc.Parameters, queryParams...)
+
+	if operation.idempotent {
+		doc.Parameters = append(doc.Parameters, &openAPIParameter{
+			Name:        idempotencyKeyHeader,
+			In:          "header",
+			Description: "retries with the same key answer the response of the first request",
+			Schema:      &openAPISchema{Type: "string", MaxLength: uint64Ptr(maxIdempotencyKeyLength)},
+		})
+	}
+
+	switch {
+	case operation.form != nil:
+		schema, err := builder.bindingSchema(reflect.TypeOf(operation.form), "form")
+		if err != nil {
+			return nil, err
+		}
+		doc.RequestBody = &openAPIRequestBody{
+			Required: len(schema.Required) > 0,
+			Content: map[string]*openAPIMediaType{
+				"application/x-www-form-urlencoded": {Schema: schema},
+			},
+		}
+	case operation.json != nil:
+		schema, err := builder.bindingSchema(reflect.TypeOf(operation.json), "json")
+		if err != nil {
+			return nil, err
+		}
+		doc.RequestBody = &openAPIRequestBody{
+			Required: len(schema.Required) > 0,
+			Content: map[string]*openAPIMediaType{
+				"application/json": {Schema: schema},
+			},
+		}
+	}
+
+	responses := operation.responses
+	if len(responses) == 0 {
+		return nil, fmt.Errorf("has no response")
+	}
+	for status, body := range responses {
+		response := &openAPIResponse{
+			Description: http.StatusText(status),
+			Content:     map[string]*openAPIMediaType{},
+		}
+		if len(operation.produces) > 0 {
+			for _, mediaType := range operation.produces {
+				response.Content[mediaType] = &openAPIMediaType{Schema: &openAPISchema{Type: "string", Format: "binary"}}
+			}
+		} else {
+			schema, err := builder.schema(reflect.TypeOf(body))
+			if err != nil {
+				return nil, err
+			}
+			response.Content["application/json"] = &openAPIMediaType{Schema: schema}
+		}
+		doc.Responses[strconv.Itoa(status)] = response
+	}
+
+	if len(doc.Parameters) > 0 || doc.RequestBody != nil {
+		doc.Responses["400"] = &openAPIResponse{Ref: "#/components/responses/BadRequest"}
+	}
+	switch operation.auth {
+	case authPublic:
+		doc.Security = &[]map[string][]string{}
+	case authStaff, authAdmin:
+		doc.Description = "Requires the " + strings.Join(operation.auth.roles(), " or ") + " role."
+		doc.Responses["403"] = &openAPIResponse{Ref: "#/components/responses/Forbidden"}
+		fallthrough
+	case authUser:
+		doc.Responses["401"] = &openAPIResponse{Ref: "#/components/responses/Unauthorized"}
+	}
+	doc.Responses["default"] = &openAPIResponse{Ref: "#/components/responses/Error"}
+	return doc, nil
+}
+
+// the parameters bound from the fields of req, named by their tag
+func (builder *openAPIBuilder) parameters(req any, tag string, in string) ([]*openAPIParameter, error) {
+	if req == nil {
+		return nil, nil
+	}
+	schema, err := builder.bindingSchema(reflect.TypeOf(req), tag)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	params := make([]*openAPIParameter, 0, len(names))
+	for _, name := range names {
+		property := schema.Properties[name]
+		description := property.Description
+		property.Description = ""
+		params = append(params, &openAPIParameter{
+			Name:        name,
+			In:          in,
+			Description: description,
+			// path parameters are always required
+			Required: in == "path" || slices.Contains(schema.Required, name),
+			Schema:   property,
+		})
+	}
+	return params, nil
+}
+
+// the object schema of a request struct, with the constraints of its binding tags
+func (builder *openAPIBuilder) bindingSchema(t reflect.Type, tag string) (*openAPISchema, error) {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("request %s is not a struct", t)
+	}
+
+	schema := &openAPISchema{Type: "object", Properties: map[string]*openAPISchema{}}
+	// the tag names of the fields, for the gtfield and gtefield constraints
+	names := map[string]string{}
+	for _, field := range reflect.VisibleFields(t) {
+		if !field.IsExported() || field.Anonymous {
+			continue
+		}
+		name := strings.Split(field.Tag.Get(tag), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		names[field.Name] = name
+	}
+
+	for _, field := range reflect.VisibleFields(t) {
+		name, ok := names[field.Name]
+		if !ok {
+			continue
+		}
+		property, err := builder.schema(field.Type)
+		if err != nil {
+			return nil, err
+		}
+		required, err := applyBinding(property, field, names)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		schema.Properties[name] = property
+		if required {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	sort.Strings(schema.Required)
+	return schema, nil
+}
+
+// sets the constraints of the binding tag of a field on its schema, and tells whether the field is required.
+// Tags the validator knows but this function does not are reported, so the document never drops a rule silently
+func applyBinding(schema *openAPISchema, field reflect.StructField, names map[string]string) (bool, error) {
+	binding := field.Tag.Get("binding")
+	if binding == "" {
+		return false, nil
+	}
+
+	numeric := schema.Type == "integer" || schema.Type == "number"
+	required := false
+	for _, rule := range strings.Split(binding, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "required":
+			required = true
+		case "omitempty":
+		case "min", "max", "gt":
+			value, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				return false, fmt.Errorf("invalid %s", rule)
+			}
+			switch {
+			case numeric && name == "max":
+				schema.Maximum = &value
+			case numeric:
+				schema.Minimum = &value
+				schema.ExclusiveMinimum = name == "gt"
+			case name == "min":
+				schema.MinLength = uint64Ptr(uint64(value))
+			case name == "max":
+				schema.MaxLength = uint64Ptr(uint64(value))
+			default:
+				return false, fmt.Errorf("%s on a %s", rule, schema.Type)
+			}
+		case "oneof":
+			schema.Enum = strings.Fields(param)
+		case "email":
+			schema.Format = "email"
+		case "alphanum":
+			schema.Pattern = "^[a-zA-Z0-9]+$"
+		case "currency":
+			schema.Enum = util.SupportedCurrencies
+		case "gtfield", "gtefield":
+			other, ok := names[param]
+			if !ok {
+				return false, fmt.Errorf("%s names an unbound field", rule)
+			}
+			comparison := "after"
+			if name == "gtefield" {
+				comparison = "at or after"
+			}
+			if numeric {
+				comparison = map[string]string{"gtfield": "greater than", "gtefield": "at least"}[name]
+			}
+			schema.Description = fmt.Sprintf("%s %s when both are set", comparison, other)
+		default:
+			return false, fmt.Errorf("binding %s is not described", rule)
+		}
+	}
+	return required, nil
+}
+
+func uint64Ptr(value uint64) *uint64 {
+	return &value
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	uuidType       = reflect.TypeOf(uuid.UUID{})
+	nullUUIDType   = reflect.TypeOf(uuid.NullUUID{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+	jsonMarshaler  = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// the schema of the json encoding/json writes for a value of type t, named structs become components
+func (builder *openAPIBuilder) schema(t reflect.Type) (*openAPISchema, error) {
+	switch t {
+	case timeType:
+		return &openAPISchema{Type: "string", Format: "date-time"}, nil
+	case uuidType:
+		return &openAPISchema{Type: "string", Format: "uuid"}, nil
+	case nullUUIDType:
+		return &openAPISchema{Type: "string", Format: "uuid", Nullable: true}, nil
+	case rawMessageType:
+		// any json value
+		return &openAPISchema{}, nil
+	}
+	if t.Implements(jsonMarshaler) {
+		return nil, fmt.Errorf("%s marshals itself to json", t)
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &openAPISchema{Type: "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &openAPISchema{Type: "integer", Format: "int32"}, nil
+	case reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &openAPISchema{Type: "integer", Format: "int64"}, nil
+	case reflect.Float32:
+		return &openAPISchema{Type: "number", Format: "float"}, nil
+	case reflect.Float64:
+		return &openAPISchema{Type: "number", Format: "double"}, nil
+	case reflect.String:
+		return &openAPISchema{Type: "string"}, nil
+	case reflect.Pointer:
+		schema, err := builder.schema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		if schema.Ref != "" {
+			// siblings of $ref are ignored, so a nullable reference is wrapped
+			return &openAPISchema{Nullable: true, AllOf: []*openAPISchema{schema}}, nil
+		}
+		schema.Nullable = true
+		return schema, nil
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// encoding/json writes bytes as base64
+			return &openAPISchema{Type: "string", Format: "byte"}, nil
+		}
+		items, err := builder.schema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &openAPISchema{Type: "array", Items: items}, nil
+	case reflect.Map:
+		values, err := builder.schema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &openAPISchema{Type: "object", AdditionalProperties: values}, nil
+	case reflect.Interface:
+		return &openAPISchema{}, nil
+	case reflect.Struct:
+		return builder.component(t)
+	}
+	return nil, fmt.Errorf("%s can't be described", t)
+}
+
+// the reference to the component schema of a struct, built on first use.
+// Fields are named and made optional as encoding/json does, embedded structs without a json name are flattened
+func (builder *openAPIBuilder) component(t reflect.Type) (*openAPISchema, error) {
+	name := componentName(t)
+	if name != "" {
+		ref := &openAPISchema{Ref: "#/components/schemas/" + name}
+		if existing, ok := builder.types[name]; ok {
+			if existing != t {
+				return nil, fmt.Errorf("%s and %s are both named %s", existing, t, name)
+			}
+			return ref, nil
+		}
+		builder.types[name] = t
+		// registered before its fields are described, so recursive types end
+		builder.schemas[name] = &openAPISchema{}
+		schema, err := builder.object(t)
+		if err != nil {
+			return nil, err
+		}
+		builder.schemas[name] = schema
+		return ref, nil
+	}
+	return builder.object(t)
+}
+
+func (builder *openAPIBuilder) object(t reflect.Type) (*openAPISchema, error) {
+	schema := &openAPISchema{Type: "object", Properties: map[string]*openAPISchema{}}
+	for _, field := range reflect.VisibleFields(t) {
+		name, omitEmpty, ok := jsonField(t, field)
+		if !ok {
+			continue
+		}
+		property, err := builder.schema(field.Type)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", t, field.Name, err)
+		}
+		schema.Properties[name] = property
+		if !omitEmpty {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	sort.Strings(schema.Required)
+	return schema, nil
+}
+
+// the json name of a visible field of t, and whether encoding/json writes it at all
+func jsonField(t reflect.Type, field reflect.StructField) (name string, omitEmpty bool, ok bool) {
+	// fields promoted through an embedded struct that has a json name are nested under it instead
+	for i := 1; i < len(field.Index); i++ {
+		parent := t.FieldByIndex(field.Index[:i])
+		if parentName, _, _ := strings.Cut(parent.Tag.Get("json"), ","); parentName != "" {
+			return "", false, false
+		}
+	}
+
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false, false
+	}
+	name, options, _ := strings.Cut(tag, ",")
+
+	embedded := field.Type
+	if embedded.Kind() == reflect.Pointer {
+		embedded = embedded.Elem()
+	}
+	if field.Anonymous && name == "" && embedded.Kind() == reflect.Struct {
+		// flattened, its fields are visible on their own
+		return "", false, false
+	}
+	if !field.IsExported() {
+		return "", false, false
+	}
+	if name == "" {
+		name = field.Name
+	}
+	return name, strings.Contains(options, "omitempty"), true
+}
+
+// the component name of a named struct, "userResponse" becomes UserResponse
+func componentName(t reflect.Type) string {
+	name := t.Name()
+	if name == "" {
+		return ""
+	}
+	runes := []rune(name)
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+// the Swagger UI page, its scripts and styles are the swagger-ui dist embedded by swaggo/files
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="UTF-8">
+  <title>Bank API</title>
+  <link rel="stylesheet" href="` + swaggerUIPath + `/assets/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="` + swaggerUIPath + `/assets/swagger-ui-bundle.js"></script>
+  <script src="` + swaggerUIPath + `/assets/swagger-ui-standalone-preset.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({
+      url: "` + openAPIPath + `",
+      dom_id: "#swagger-ui",
+      presets: [SwaggerUIBundle.presets.apis, SwaggerUIStandalonePreset],
+      layout: "StandaloneLayout"
+    });
+  </script>
+</body>
+</html>
+`
+
+// serves the OpenAPI document of the routes registered so far, and the Swagger UI reading it
+func (server *Server) setupDocs(router *gin.Engine) error {
+	document, err := newOpenAPIDocument(router.Routes(), apiOperations)
+	if err != nil {
+		return err
+	}
+	spec, err := json.Marshal(document)
+	if err != nil {
+		return err
+	}
+
+	router.GET(openAPIPath, func(ctx *gin.Context) {
+		ctx.Data(http.StatusOK, "application/json", spec)
+	})
+	router.GET(swaggerUIPath, func(ctx *gin.Context) {
+		ctx.Data(http.StatusOK, "text/html; charset=utf-8", []byte(swaggerUIPage))
+	})
+	router.StaticFS(swaggerUIPath+"/assets", http.FS(swaggerFiles.FS))
+	return nil
+}
@@ -0,0 +1,270 @@
+package api
+
+import (
+	db "BankAppGo/db/sqlc"
+	"BankAppGo/reconcile"
+	"net/http"
+)
+
+// the operation of every gin route, by method and path as setupRouter registers them.
+// The OpenAPI document is built from this table, a route missing from it keeps the server from starting
+var apiOperations = map[string]apiOperation{
+	"POST /users": {
+		summary:   "Create a user",
+		form:      createUserRequest{},
+		responses: ok(userResponse{}),
+	},
+	"POST /users/login": {
+		summary:   "Log in, returning an access and a refresh token",
+		form:      loginUserRequest{},
+		responses: ok(loginUserResponse{}),
+	},
+	"POST /tokens/renew_access": {
+		summary:   "Get a new access token for a refresh token",
+		form:      renewAccessTokenRequest{},
+		responses: ok(renewAccessTokenResponse{}),
+	},
+
+	"POST /users/logout": {
+		summary:   "Revoke the access token, and the refresh token and its session when given",
+		auth:      authUser,
+		form:      logoutUserRequest{},
+		responses: ok(logoutUserResponse{}),
+	},
+	"POST /users/:username/revoke-sessions": {
+		summary:   "Block every session of a user and revoke all tokens issued to them so far",
+		auth:      authAdmin,
+		uri:       revokeUserSessionsURI{},
+		responses: ok(db.RevokeUserSessionsTxResult{}),
+	},
+	"PUT /users/:username/transfer-limits/:currency": {
+		summary:   "Set the transfer limits of a user in a currency",
+		auth:      authAdmin,
+		uri:       transferLimitURI{},
+		form:      setTransferLimitRequest{},
+		responses: ok(db.TransferLimit{}),
+	},
+	"DELETE /users/:username/transfer-limits/:currency": {
+		summary:   "Put a user back on the default transfer limits of a currency",
+		auth:      authAdmin,
+		uri:       transferLimitURI{},
+		responses: ok(&db.TransferLimits{}),
+	},
+
+	"POST /accounts": {
+		summary:    "Open an account",
+		auth:       authUser,
+		json:       createAccountRequest{},
+		idempotent: true,
+		responses:  ok(db.Account{}),
+	},
+	"GET /accounts/:id": {
+		summary:   "Get an account",
+		auth:      authUser,
+		uri:       getAccountRequest{},
+		responses: ok(db.Account{}),
+	},
+	"GET /accounts/:id/entries": {
+		summary:   "List the entries of an account between two dates, with the running balance",
+		auth:      authUser,
+		uri:       listEntriesURI{},
+		query:     listEntriesRequest{},
+		responses: ok(listEntriesResponse{}),
+	},
+	"GET /accounts/:id/statement": {
+		summary:   "Download the monthly statement of an account",
+		auth:      authUser,
+		uri:       getStatementURI{},
+		query:     getStatementRequest{},
+		responses: ok(nil),
+		produces:  []string{"text/csv", "application/pdf"},
+	},
+	"GET /accounts": {
+		summary:   "List the accounts of the authenticated user",
+		auth:      authUser,
+		query:     listAccountsRequest{},
+		responses: ok([]db.Account{}),
+	},
+	"POST /accounts/:id/adjustments": {
+		summary:   "Credit or debit an account by hand",
+		auth:      authAdmin,
+		uri:       adjustBalanceURI{},
+		form:      adjustBalanceRequest{},
+		responses: ok(db.AdjustBalanceTxResult{}),
+	},
+	"POST /accounts/:id/deposits": {
+		summary:   "Pay money into an account from outside the bank",
+		auth:      authStaff,
+		uri:       cashMovementURI{},
+		form:      cashMovementRequest{},
+		responses: ok(db.CashTxResult{}),
+	},
+	"POST /accounts/:id/withdrawals": {
+		summary:   "Pay money out of an account to outside the bank",
+		auth:      authStaff,
+		uri:       cashMovementURI{},
+		form:      cashMovementRequest{},
+		responses: ok(db.CashTxResult{}),
+	},
+	"POST /accounts/:id/status": {
+		summary:   "Freeze, unfreeze or close an account",
+		auth:      authAdmin,
+		uri:       setAccountStatusURI{},
+		form:      setAccountStatusRequest{},
+		responses: ok(db.Account{}),
+	},
+	"DELETE /accounts/:id": {
+		summary:   "Close an empty account",
+		auth:      authUser,
+		uri:       closeAccountRequest{},
+		responses: ok(db.Account{}),
+	},
+
+	"POST /transfers": {
+		summary:    "Transfer money between two accounts, converting it when their currencies differ",
+		auth:       authUser,
+		form:       transferRequest{},
+		idempotent: true,
+		responses:  ok(db.TransferTxResult{}),
+	},
+	"GET /transfers": {
+		summary:   "Search the transfers of the authenticated user",
+		auth:      authUser,
+		query:     listTransfersRequest{},
+		responses: ok(listTransfersResponse{}),
+	},
+	"GET /transfers/quote": {
+		summary:   "Quote the fee and converted amount of a transfer without making it",
+		auth:      authUser,
+		query:     transferRequest{},
+		responses: ok(transferQuoteResponse{}),
+	},
+	"GET /transfers/:id": {
+		summary:   "Get a transfer",
+		auth:      authUser,
+		uri:       getTransferRequest{},
+		responses: ok(db.Transfer{}),
+	},
+	"POST /transfers/authorize": {
+		summary:    "Hold an amount on an account until it is captured or voided",
+		auth:       authUser,
+		form:       authorizeTransferRequest{},
+		idempotent: true,
+		responses:  ok(db.Hold{}),
+	},
+	"POST /transfers/:id/capture": {
+		summary:   "Transfer the whole or a part of a hold, as its payee or a banker",
+		auth:      authUser,
+		uri:       holdURI{},
+		form:      captureHoldRequest{},
+		responses: ok(db.CaptureHoldTxResult{}),
+	},
+	"POST /transfers/:id/void": {
+		summary:   "Release a hold, as its payer or a banker",
+		auth:      authUser,
+		uri:       holdURI{},
+		responses: ok(db.Hold{}),
+	},
+	"POST /transfers/:id/reverse": {
+		summary: "Send a transfer back, or ask its recipient to once the grace period is over",
+		auth:    authUser,
+		uri:     reverseTransferURI{},
+		form:    reverseTransferRequest{},
+		responses: map[int]any{
+			http.StatusOK:       db.TransferReversalTxResult{},
+			http.StatusAccepted: db.TransferReversal{},
+		},
+	},
+	"POST /transfers/:id/reverse/approve": {
+		summary:   "Consent, as the recipient, to the pending reversal of a transfer within its grace period",
+		auth:      authUser,
+		uri:       reverseTransferURI{},
+		responses: ok(db.TransferReversalTxResult{}),
+	},
+
+	"POST /scheduled-transfers": {
+		summary:   "Schedule a transfer once or on a cadence",
+		auth:      authUser,
+		form:      createScheduledTransferRequest{},
+		responses: ok(db.ScheduledTransfer{}),
+	},
+	"GET /scheduled-transfers": {
+		summary:   "List the scheduled transfers of the authenticated user",
+		auth:      authUser,
+		query:     listScheduledTransfersRequest{},
+		responses: ok([]db.ScheduledTransfer{}),
+	},
+	"GET /scheduled-transfers/:id": {
+		summary:   "Get a scheduled transfer",
+		auth:      authUser,
+		uri:       scheduledTransferURI{},
+		responses: ok(db.ScheduledTransfer{}),
+	},
+	"GET /scheduled-transfers/:id/runs": {
+		summary:   "List the runs of a scheduled transfer",
+		auth:      authUser,
+		uri:       scheduledTransferURI{},
+		query:     listScheduledTransferRunsRequest{},
+		responses: ok([]db.ScheduledTransferRun{}),
+	},
+	"PATCH /scheduled-transfers/:id": {
+		summary:   "Change the amount or end of a scheduled transfer, or pause and resume it",
+		auth:      authUser,
+		uri:       scheduledTransferURI{},
+		form:      updateScheduledTransferRequest{},
+		responses: ok(db.ScheduledTransfer{}),
+	},
+	"DELETE /scheduled-transfers/:id": {
+		summary:   "Cancel a scheduled transfer",
+		auth:      authUser,
+		uri:       scheduledTransferURI{},
+		responses: ok(db.ScheduledTransfer{}),
+	},
+
+	"POST /fee-schedules": {
+		summary:   "Set the fee charged on transfers in a currency",
+		auth:      authAdmin,
+		form:      createFeeScheduleRequest{},
+		responses: ok(db.FeeSchedule{}),
+	},
+	"GET /fee-schedules/:currency": {
+		summary:   "Get the fee schedule of a currency",
+		auth:      authUser,
+		uri:       getFeeScheduleRequest{},
+		responses: ok(db.FeeSchedule{}),
+	},
+
+	"PUT /interest-rates/:currency/:kind": {
+		summary:   "Set the interest rate of an account kind in a currency",
+		auth:      authAdmin,
+		uri:       interestRateURI{},
+		form:      setInterestRateRequest{},
+		responses: ok(db.InterestRate{}),
+	},
+	"GET /interest-rates/:currency/:kind": {
+		summary:   "Get the interest rate of an account kind in a currency",
+		auth:      authUser,
+		uri:       interestRateURI{},
+		responses: ok(db.InterestRate{}),
+	},
+
+	"POST /reconciliations": {
+		summary:   "Check the balances and transfers against the ledger",
+		auth:      authAdmin,
+		form:      reconcileLedgerRequest{},
+		responses: ok(reconcile.Report{}),
+	},
+	"GET /reconciliations": {
+		summary:   "List the recorded reconciliation reports",
+		auth:      authAdmin,
+		query:     listReconciliationReportsRequest{},
+		responses: ok([]db.ReconciliationReport{}),
+	},
+
+	"GET /audit": {
+		summary:   "Search the audit log",
+		auth:      authAdmin,
+		query:     listAuditEventsRequest{},
+		responses: ok(listAuditEventsResponse{}),
+	},
+}
@@ -0,0 +1,154 @@
+package api
+
+import (
+	mockdb "BankAppGo/db/mock"
+	"BankAppGo/db/util"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+// fails when a gin route and the operations of the document drift apart
+func TestOpenAPIMatchesRoutes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := newTestServer(t, mockdb.NewMockStore(ctrl))
+	routes := server.router.Routes()
+
+	document, err := newOpenAPIDocument(routes, apiOperations)
+	require.NoError(t, err)
+
+	documented := 0
+	for _, route := range routes {
+		if undocumentedRoute(route.Path) {
+			continue
+		}
+		documented++
+
+		operation := document.Paths[openAPIRoutePath(route.Path)][strings.ToLower(route.Method)]
+		require.NotNil(t, operation, "%s %s", route.Method, route.Path)
+		require.Equal(t, handlerName(route.Handler), operation.OperationID)
+	}
+	require.Equal(t, len(apiOperations), documented)
+
+	// a route without operation
+	router := gin.New()
+	router.GET("/accounts/:id/interest", func(ctx *gin.Context) {})
+	_, err = newOpenAPIDocument(router.Routes(), map[string]apiOperation{})
+	require.ErrorContains(t, err, "GET /accounts/:id/interest has no operation")
+
+	// an operation without route
+	_, err = newOpenAPIDocument(gin.New().Routes(), map[string]apiOperation{
+		"GET /accounts/:id": apiOperations["GET /accounts/:id"],
+	})
+	require.ErrorContains(t, err, "GET /accounts/:id has no route")
+
+	// an operation binding other path parameters than its route has
+	router = gin.New()
+	router.GET("/accounts/:account_id", func(ctx *gin.Context) {})
+	_, err = newOpenAPIDocument(router.Routes(), map[string]apiOperation{
+		"GET /accounts/:account_id": apiOperations["GET /accounts/:id"],
+	})
+	require.ErrorContains(t, err, "binds path parameters [id] but the route has [account_id]")
+}
+
+// fails when a binding tag the document does not describe is used
+func TestOpenAPIBindingTags(t *testing.T) {
+	type request struct {
+		Name string `form:"name" binding:"required,startswith=a"`
+	}
+
+	router := gin.New()
+	router.POST("/names", func(ctx *gin.Context) {})
+	_, err := newOpenAPIDocument(router.Routes(), map[string]apiOperation{
+		"POST /names": {form: request{}, responses: ok(apiError{})},
+	})
+	require.ErrorContains(t, err, "binding startswith=a is not described")
+}
+
+// the security of every operation is the one its route enforces
+func TestOpenAPIAuth(t *testing.T) {
+	user, _ := randomUser(t)
+
+	for key, operation := range apiOperations {
+		method, path, _ := strings.Cut(key, " ")
+		// any value reaches the middlewares, they run before the parameters are bound
+		url := regexp.MustCompile(`[:*][a-z_]+`).ReplaceAllString(path, "1")
+
+		t.Run(key, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// the requests are refused before the store is used, except for the public ones
+			store := mockdb.NewMockStore(ctrl)
+			server := newTestServer(t, store)
+
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(method, url, nil)
+			server.router.ServeHTTP(recorder, request)
+			if operation.auth == authPublic {
+				require.NotEqual(t, http.StatusUnauthorized, recorder.Code)
+				return
+			}
+			require.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+			if operation.auth == authStaff || operation.auth == authAdmin {
+				recorder = httptest.NewRecorder()
+				request = httptest.NewRequest(method, url, nil)
+				addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+				server.router.ServeHTTP(recorder, request)
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			}
+		})
+	}
+}
+
+func TestOpenAPIServed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := newTestServer(t, mockdb.NewMockStore(ctrl))
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, openAPIPath, nil)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var document struct {
+		OpenAPI string                                  `json:"openapi"`
+		Paths   map[string]map[string]*openAPIOperation `json:"paths"`
+	}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &document))
+	require.True(t, strings.HasPrefix(document.OpenAPI, "3."))
+
+	login := document.Paths["/users/login"]["post"]
+	require.NotNil(t, login)
+	require.NotNil(t, login.Security)
+	require.Empty(t, *login.Security)
+
+	transfer := document.Paths["/transfers"]["post"]
+	require.NotNil(t, transfer)
+	require.Nil(t, transfer.Security)
+	require.Contains(t, transfer.Responses, "401")
+	require.Contains(t, transfer.Responses, "default")
+
+	recorder = httptest.NewRecorder()
+	request = httptest.NewRequest(http.MethodGet, swaggerUIPath, nil)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Contains(t, recorder.Body.String(), openAPIPath)
+
+	recorder = httptest.NewRecorder()
+	request = httptest.NewRequest(http.MethodGet, swaggerUIPath+"/assets/swagger-ui-bundle.js", nil)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+}
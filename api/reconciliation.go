@@ -0,0 +1,65 @@
+package api
+
+import (
+	db "BankAppGo/db/sqlc"
+	"BankAppGo/reconcile"
+	"BankAppGo/token"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type reconcileLedgerRequest struct {
+	BatchSize int64 `form:"batch_size" binding:"min=0,max=10000"`
+	Record    bool  `form:"record"`
+}
+
+// checks the ledger invariants and reports the discrepancies found
+func (server *Server) reconcileLedger(ctx *gin.Context) {
+	var req reconcileLedgerRequest
+
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	report, err := reconcile.Run(ctx, server.store, reconcile.Options{
+		BatchSize: req.BatchSize,
+		Record:    req.Record,
+		RunBy:     authPayload.Username,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
+
+type listReconciliationReportsRequest struct {
+	Limit  int64 `form:"page_size" binding:"required,min=5,max=10"`
+	Offset int64 `form:"page_id" binding:"required,min=1"`
+}
+
+// lists recorded reconciliation reports, most recent first
+func (server *Server) listReconciliationReports(ctx *gin.Context) {
+	var req listReconciliationReportsRequest
+
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	reports, err := server.store.ListReconciliationReports(ctx, db.ListReconciliationReportsParams{
+		Limit:  req.Limit,
+		Offset: (req.Offset - 1) * req.Limit,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, reports)
+}
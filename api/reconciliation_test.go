@@ -0,0 +1,143 @@
+package api
+
+import (
+	mockdb "BankAppGo/db/mock"
+	db "BankAppGo/db/sqlc"
+	"BankAppGo/db/util"
+	"BankAppGo/reconcile"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconcileLedgerAPI(t *testing.T) {
+	testCases := []struct {
+		name          string
+		body          gin.H
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"record": true},
+			role: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListAccountEntryTotals(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.ListAccountEntryTotalsRow{{ID: 1, Balance: 10, EntriesTotal: 0}}, nil)
+				store.EXPECT().
+					ListTransferEntryCounts(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.ListTransferEntryCountsRow{}, nil)
+				store.EXPECT().
+					ListCurrencyTotals(gomock.Any()).
+					Times(1).
+					Return([]db.ListCurrencyTotalsRow{}, nil)
+				store.EXPECT().
+					CreateReconciliationReport(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReconciliationReport{ID: 4}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var report reconcile.Report
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &report))
+				require.Equal(t, int64(4), report.ID)
+				require.Len(t, report.Discrepancies, 1)
+				require.Equal(t, reconcile.BalanceMismatch, report.Discrepancies[0].Kind)
+			},
+		},
+		{
+			name: "NotAdmin",
+			body: gin.H{},
+			role: util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccountEntryTotals(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidBatchSize",
+			body: gin.H{"batch_size": -1},
+			role: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccountEntryTotals(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			body: gin.H{},
+			role: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListAccountEntryTotals(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, sql.ErrConnDone)
+				store.EXPECT().ListTransferEntryCounts(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data := GinHToURLValues(tc.body)
+			request, err := http.NewRequest(http.MethodPost, "/reconciliations", strings.NewReader(data.Encode()))
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "admin", tc.role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListReconciliationReportsAPI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		ListReconciliationReports(gomock.Any(), gomock.Eq(db.ListReconciliationReportsParams{Limit: 5, Offset: 5})).
+		Times(1).
+		Return([]db.ReconciliationReport{{ID: 2, Discrepancies: json.RawMessage(`[]`)}}, nil)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/reconciliations?page_id=2&page_size=5", nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "admin", util.AdminRole, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+}
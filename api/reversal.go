@@ -0,0 +1,183 @@
+package api
+
+import (
+	db "BankAppGo/db/sqlc"
+	"BankAppGo/db/util"
+	"BankAppGo/token"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+type reverseTransferURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type reverseTransferRequest struct {
+	// everything left of the transfer when empty
+	Amount int64  `form:"amount" binding:"min=0"`
+	Reason string `form:"reason" binding:"required"`
+}
+
+// Admins reverse a transfer right away. Its sender may ask for a reversal within the grace
+// period after the transfer, which is made once the recipient approves it within the same period
+func (server *Server) reverseTransfer(ctx *gin.Context) {
+	var uri reverseTransferURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req reverseTransferRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	transfer, valid := server.lookupTransfer(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	arg := db.TransferReversalTxParams{
+		TransferID:  uri.ID,
+		Amount:      req.Amount,
+		Reason:      req.Reason,
+		RequestedBy: authPayload.Username,
+	}
+
+	if hasRole(authPayload, util.AdminRole) {
+		result, err := server.store.ReverseTransferTx(ctx, arg)
+		if err != nil {
+			reversalError(ctx, err)
+			return
+		}
+		ctx.JSON(http.StatusOK, result)
+		return
+	}
+
+	fromAccount, valid := server.lookupAccount(ctx, transfer.FromAccountID)
+	if !valid {
+		return
+	}
+
+	if fromAccount.Owner != authPayload.Username {
+		err := errors.New("only the sender of a transfer or an admin can reverse it")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	if !server.checkReversalGracePeriod(ctx, transfer) {
+		return
+	}
+
+	reversal, err := server.store.RequestTransferReversalTx(ctx, arg)
+	if err != nil {
+		reversalError(ctx, err)
+		return
+	}
+
+	// nothing moves until the recipient approves
+	ctx.JSON(http.StatusAccepted, reversal)
+}
+
+// lets the recipient of a transfer consent to its pending reversal, which is then made
+func (server *Server) approveTransferReversal(ctx *gin.Context) {
+	var uri reverseTransferURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	transfer, valid := server.lookupTransfer(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	toAccount, valid := server.lookupAccount(ctx, transfer.ToAccountID)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if toAccount.Owner != authPayload.Username {
+		err := errors.New("only the recipient of a transfer can approve its reversal")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	// the money may have been spent since, a late reversal is left to an admin
+	if !server.checkReversalGracePeriod(ctx, transfer) {
+		return
+	}
+
+	result, err := server.store.ApproveTransferReversalTx(ctx, uri.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err := errors.New("transfer has no pending reversal")
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		reversalError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// reversals are requested and approved within the grace period after the transfer
+func (server *Server) checkReversalGracePeriod(ctx *gin.Context, transfer db.Transfer) bool {
+	grace := server.config.ReversalGracePeriod
+	if grace <= 0 {
+		grace = defaultReversalGracePeriod
+	}
+	if time.Since(transfer.CreatedAt) > grace {
+		err := errors.New("the grace period to reverse this transfer has passed")
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return false
+	}
+	return true
+}
+
+// maps the errors of reversing a transfer to a response
+func reversalError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, db.ErrTransferReversed):
+		ctx.JSON(http.StatusConflict, errorResponse(err))
+		return
+	case errors.Is(err, db.ErrReversalExceedsTransfer), errors.Is(err, db.ErrReversalNotAllowed), errors.Is(err, db.ErrInsufficientFunds):
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+		return
+	case errors.Is(err, db.ErrAccountClosed):
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return
+	case errors.Is(err, db.ErrAccountFrozen):
+		ctx.JSON(http.StatusLocked, errorResponse(err))
+		return
+	}
+	if pqError, ok := err.(*pq.Error); ok && pqError.Code.Name() == "unique_violation" {
+		err := errors.New("the transfer already has a pending reversal")
+		ctx.JSON(http.StatusConflict, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+}
+
+func (server *Server) lookupTransfer(ctx *gin.Context, transferID int64) (db.Transfer, bool) {
+	transfer, err := server.store.GetTransferFromId(ctx, transferID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return db.Transfer{}, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return db.Transfer{}, false
+	}
+
+	return transfer, true
+}
@@ -0,0 +1,310 @@
+package api
+
+import (
+	mockdb "BankAppGo/db/mock"
+	db "BankAppGo/db/sqlc"
+	"BankAppGo/db/util"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReverseTransferAPI(t *testing.T) {
+	sender, _ := randomUser(t)
+	recipient, _ := randomUser(t)
+
+	fromAccount := randomAccount(sender.Username)
+	toAccount := randomAccount(recipient.Username)
+	transfer := db.Transfer{
+		ID:            util.RandomInt(1, 1000),
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        100,
+		ToAmount:      100,
+		ExchangeRate:  "1",
+		CreatedAt:     time.Now().Add(-time.Hour),
+	}
+	body := gin.H{"amount": 40, "reason": "wrong recipient"}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		username      string
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "AdminReversesNow",
+			body:     body,
+			username: "admin",
+			role:     util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.TransferReversalTxParams{
+					TransferID:  transfer.ID,
+					Amount:      40,
+					Reason:      "wrong recipient",
+					RequestedBy: "admin",
+				}
+				store.EXPECT().GetTransferFromId(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
+				store.EXPECT().ReverseTransferTx(gomock.Any(), gomock.Eq(arg)).Times(1)
+				store.EXPECT().RequestTransferReversalTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "AlreadyReversed",
+			body:     body,
+			username: "admin",
+			role:     util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferFromId(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
+				store.EXPECT().ReverseTransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferReversalTxResult{}, db.ErrTransferReversed)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:     "ExceedsTransfer",
+			body:     gin.H{"amount": 101, "reason": "wrong recipient"},
+			username: "admin",
+			role:     util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferFromId(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
+				store.EXPECT().ReverseTransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferReversalTxResult{}, db.ErrReversalExceedsTransfer)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name:     "SenderRequests",
+			body:     body,
+			username: sender.Username,
+			role:     util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferFromId(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().
+					RequestTransferReversalTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferReversal{TransferID: transfer.ID, Amount: 40, Status: util.ReversalPending}, nil)
+				store.EXPECT().ReverseTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+			},
+		},
+		{
+			name:     "SenderAlreadyRequested",
+			body:     body,
+			username: sender.Username,
+			role:     util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferFromId(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().
+					RequestTransferReversalTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferReversal{}, &pq.Error{Code: "23505"})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:     "GracePeriodPassed",
+			body:     body,
+			username: sender.Username,
+			role:     util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				old := transfer
+				old.CreatedAt = time.Now().Add(-defaultReversalGracePeriod - time.Minute)
+
+				store.EXPECT().GetTransferFromId(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(old, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().RequestTransferReversalTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "RecipientCannotRequest",
+			body:     body,
+			username: recipient.Username,
+			role:     util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferFromId(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().RequestTransferReversalTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "MissingReason",
+			body:     gin.H{"amount": 40},
+			username: "admin",
+			role:     util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferFromId(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "NotFound",
+			body:     body,
+			username: "admin",
+			role:     util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferFromId(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(db.Transfer{}, sql.ErrNoRows)
+				store.EXPECT().ReverseTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data := GinHToURLValues(tc.body)
+			url := fmt.Sprintf("/transfers/%d/reverse", transfer.ID)
+			request, err := http.NewRequest(http.MethodPost, url, strings.NewReader(data.Encode()))
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, tc.role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestApproveTransferReversalAPI(t *testing.T) {
+	sender, _ := randomUser(t)
+	recipient, _ := randomUser(t)
+
+	fromAccount := randomAccount(sender.Username)
+	toAccount := randomAccount(recipient.Username)
+	transfer := db.Transfer{
+		ID:            util.RandomInt(1, 1000),
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        100,
+		ToAmount:      100,
+		CreatedAt:     time.Now(),
+	}
+
+	testCases := []struct {
+		name     string
+		username string
+		// the transfer was made before the grace period started
+		late       bool
+		buildStubs func(store *mockdb.MockStore)
+		status     int
+	}{
+		{
+			name:     "OK",
+			username: recipient.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ApproveTransferReversalTx(gomock.Any(), gomock.Eq(transfer.ID)).Times(1)
+			},
+			status: http.StatusOK,
+		},
+		{
+			name:     "NothingPending",
+			username: recipient.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ApproveTransferReversalTx(gomock.Any(), gomock.Eq(transfer.ID)).
+					Times(1).
+					Return(db.TransferReversalTxResult{}, sql.ErrNoRows)
+			},
+			status: http.StatusNotFound,
+		},
+		{
+			name:     "RecipientSpentTheMoney",
+			username: recipient.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ApproveTransferReversalTx(gomock.Any(), gomock.Eq(transfer.ID)).
+					Times(1).
+					Return(db.TransferReversalTxResult{}, db.ErrInsufficientFunds)
+			},
+			status: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "GracePeriodPassed",
+			username: recipient.Username,
+			late:     true,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ApproveTransferReversalTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			status: http.StatusForbidden,
+		},
+		{
+			name:     "SenderCannotApprove",
+			username: sender.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ApproveTransferReversalTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			status: http.StatusUnauthorized,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			transfer := transfer
+			if tc.late {
+				transfer.CreatedAt = time.Now().Add(-defaultReversalGracePeriod - time.Minute)
+			}
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetTransferFromId(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/transfers/%d/reverse/approve", transfer.ID)
+			request, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, util.DepositorRole, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, tc.status, recorder.Code)
+		})
+	}
+}
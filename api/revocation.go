@@ -0,0 +1,114 @@
+package api
+
+import (
+	db "BankAppGo/db/sqlc"
+	"BankAppGo/token"
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// revocationCache keeps the active token revocations in memory so that
+// checking a token never hits the database. Revocations made by this server
+// apply immediately, the ones made elsewhere are picked up on the next sync
+type revocationCache struct {
+	mu sync.RWMutex
+	// expiry of each revoked token
+	tokens map[uuid.UUID]time.Time
+	// latest revocation of all tokens of each user
+	users map[string]db.TokenRevocation
+}
+
+func newRevocationCache() *revocationCache {
+	return &revocationCache{
+		tokens: make(map[uuid.UUID]time.Time),
+		users:  make(map[string]db.TokenRevocation),
+	}
+}
+
+// reports whether the token was revoked on its own or by a revocation of all its user's tokens
+func (cache *revocationCache) isRevoked(payload *token.Payload) bool {
+	cache.mu.RLock()
+	defer cache.mu.RUnlock()
+
+	if _, ok := cache.tokens[payload.ID]; ok {
+		return true
+	}
+
+	revocation, ok := cache.users[payload.Username]
+	return ok && !payload.IssuedAt.After(revocation.RevokedAt)
+}
+
+// adds a revocation to the cache
+func (cache *revocationCache) add(revocation db.TokenRevocation) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	cache.addLocked(revocation)
+}
+
+func (cache *revocationCache) addLocked(revocation db.TokenRevocation) {
+	if revocation.TokenID.Valid {
+		cache.tokens[revocation.TokenID.UUID] = revocation.ExpiresAt
+		return
+	}
+
+	if revocation.RevokedAt.After(cache.users[revocation.Username].RevokedAt) {
+		cache.users[revocation.Username] = revocation
+	}
+}
+
+// deletes expired revocations from the store and the cache, and loads the active ones.
+// Revocations are never lifted, so entries are only dropped once they expire
+func (cache *revocationCache) refresh(ctx context.Context, store db.Store) error {
+	_, err := store.DeleteExpiredTokenRevocations(ctx)
+	if err != nil {
+		return err
+	}
+
+	revocations, err := store.ListActiveTokenRevocations(ctx)
+	if err != nil {
+		return err
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	for _, revocation := range revocations {
+		cache.addLocked(revocation)
+	}
+
+	now := time.Now()
+	for id, expiresAt := range cache.tokens {
+		if !expiresAt.After(now) {
+			delete(cache.tokens, id)
+		}
+	}
+	for username, revocation := range cache.users {
+		if !revocation.ExpiresAt.After(now) {
+			delete(cache.users, username)
+		}
+	}
+	return nil
+}
+
+// refreshes the cache every interval until ctx is done
+func (cache *revocationCache) sync(ctx context.Context, store db.Store, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := cache.refresh(ctx, store)
+			if err != nil {
+				log.Println("cannot refresh token revocations:", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package api
+
+import (
+	mockdb "BankAppGo/db/mock"
+	db "BankAppGo/db/sqlc"
+	"BankAppGo/db/util"
+	"BankAppGo/token"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRevocationMiddleware(t *testing.T) {
+	testCases := []struct {
+		name          string
+		revoke        func(cache *revocationCache, payload *token.Payload)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "OK",
+			revoke: func(cache *revocationCache, payload *token.Payload) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "TokenRevoked",
+			revoke: func(cache *revocationCache, payload *token.Payload) {
+				cache.add(db.TokenRevocation{
+					Username:  payload.Username,
+					TokenID:   uuid.NullUUID{UUID: payload.ID, Valid: true},
+					ExpiresAt: payload.ExpiredAt,
+					RevokedAt: time.Now(),
+				})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "OtherTokenRevoked",
+			revoke: func(cache *revocationCache, payload *token.Payload) {
+				cache.add(db.TokenRevocation{
+					Username:  payload.Username,
+					TokenID:   uuid.NullUUID{UUID: uuid.New(), Valid: true},
+					ExpiresAt: payload.ExpiredAt,
+					RevokedAt: time.Now(),
+				})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "AllUserTokensRevoked",
+			revoke: func(cache *revocationCache, payload *token.Payload) {
+				cache.add(db.TokenRevocation{
+					Username:  payload.Username,
+					ExpiresAt: time.Now().Add(time.Hour),
+					RevokedAt: payload.IssuedAt.Add(time.Second),
+				})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "IssuedAfterUserRevocation",
+			revoke: func(cache *revocationCache, payload *token.Payload) {
+				cache.add(db.TokenRevocation{
+					Username:  payload.Username,
+					ExpiresAt: time.Now().Add(time.Hour),
+					RevokedAt: payload.IssuedAt.Add(-time.Second),
+				})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			server := newTestServer(t, nil)
+
+			authPath := "/auth"
+			server.router.GET(
+				authPath,
+				authMiddleware(server.tokenMaker),
+				revocationMiddleware(server.revocations),
+				func(ctx *gin.Context) {
+					ctx.JSON(http.StatusOK, gin.H{})
+				},
+			)
+
+			accessToken, payload, err := server.tokenMaker.CreateToken("user", util.DepositorRole, token.TokenTypeAccess, time.Minute)
+			require.NoError(t, err)
+			tc.revoke(server.revocations, payload)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, authPath, nil)
+			require.NoError(t, err)
+
+			request.Header.Set(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationTypeBearer, accessToken))
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestRevocationCacheRefresh(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	active := db.TokenRevocation{
+		Username:  "user",
+		TokenID:   uuid.NullUUID{UUID: uuid.New(), Valid: true},
+		ExpiresAt: time.Now().Add(time.Minute),
+		RevokedAt: time.Now(),
+	}
+	expiredID := uuid.New()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		DeleteExpiredTokenRevocations(gomock.Any()).
+		Times(1).
+		Return(int64(1), nil)
+	store.EXPECT().
+		ListActiveTokenRevocations(gomock.Any()).
+		Times(1).
+		Return([]db.TokenRevocation{active}, nil)
+
+	cache := newRevocationCache()
+	cache.add(db.TokenRevocation{
+		Username:  "user",
+		TokenID:   uuid.NullUUID{UUID: expiredID, Valid: true},
+		ExpiresAt: time.Now().Add(-time.Minute),
+	})
+
+	err := cache.refresh(context.Background(), store)
+	require.NoError(t, err)
+
+	require.True(t, cache.isRevoked(&token.Payload{ID: active.TokenID.UUID, Username: "user"}))
+	require.NotContains(t, cache.tokens, expiredID)
+}
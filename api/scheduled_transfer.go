@@ -0,0 +1,283 @@
+package api
+
+import (
+	db "BankAppGo/db/sqlc"
+	"BankAppGo/db/util"
+	"BankAppGo/token"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+type createScheduledTransferRequest struct {
+	FromAccountID int64     `form:"from_account_id" binding:"required,min=1"`
+	ToAccountID   int64     `form:"to_account_id" binding:"required,min=1"`
+	Amount        int64     `form:"amount" binding:"required,gt=0"`
+	Currency      string    `form:"currency" binding:"required,currency"`
+	Cadence       string    `form:"cadence" binding:"required,oneof=once daily weekly monthly"`
+	StartAt       time.Time `form:"start_at" time_format:"2006-01-02T15:04:05Z07:00" binding:"required"`
+	// runs forever if empty
+	EndAt time.Time `form:"end_at" time_format:"2006-01-02T15:04:05Z07:00" binding:"omitempty,gtefield=StartAt"`
+}
+
+func (server *Server) createScheduledTransfer(ctx *gin.Context) {
+	var req createScheduledTransferRequest
+
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if !req.StartAt.After(time.Now()) {
+		err := errors.New("start_at must be in the future")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	account, valid := server.validate_account(ctx, req.FromAccountID, req.Currency)
+	if !valid {
+		return
+	}
+
+	if account.Owner != authPayload.Username {
+		err := errors.New("from account doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	toAccount, valid := server.lookupAccount(ctx, req.ToAccountID)
+	if !valid {
+		return
+	}
+
+	// the executor moves money at no quoted rate, so both sides must share a currency
+	if toAccount.Currency != req.Currency {
+		err := fmt.Errorf("account [%d] currency mismatch: %s vs %s, scheduled transfers cannot convert currencies", req.ToAccountID, toAccount.Currency, req.Currency)
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	for _, a := range []db.Account{account, toAccount} {
+		if !ensureActive(ctx, a) {
+			return
+		}
+	}
+
+	scheduled, err := server.store.CreateScheduledTransferTx(ctx, db.CreateScheduledTransferParams{
+		Owner:         authPayload.Username,
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+		Cadence:       req.Cadence,
+		StartAt:       req.StartAt,
+		EndAt:         sql.NullTime{Time: req.EndAt, Valid: !req.EndAt.IsZero()},
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrSystemAccount) {
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+		if pqError, ok := err.(*pq.Error); ok {
+			switch pqError.Code.Name() {
+			case "foreign_key_violation", "check_violation":
+				ctx.JSON(http.StatusForbidden, errorResponse(err))
+				return
+			}
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, scheduled)
+}
+
+type listScheduledTransfersRequest struct {
+	Limit  int64 `form:"page_size" binding:"required,min=5,max=10"`
+	Offset int64 `form:"page_id" binding:"required,min=1"`
+}
+
+func (server *Server) listScheduledTransfers(ctx *gin.Context) {
+	var req listScheduledTransfersRequest
+
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	scheduled, err := server.store.ListScheduledTransfers(ctx, db.ListScheduledTransfersParams{
+		Owner:  authPayload.Username,
+		Limit:  req.Limit,
+		Offset: (req.Offset - 1) * req.Limit,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, scheduled)
+}
+
+type scheduledTransferURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) getScheduledTransfer(ctx *gin.Context) {
+	var uri scheduledTransferURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	scheduled, valid := server.lookupScheduledTransfer(ctx, uri.ID, true)
+	if !valid {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, scheduled)
+}
+
+type listScheduledTransferRunsRequest struct {
+	Limit  int64 `form:"page_size" binding:"required,min=5,max=50"`
+	Offset int64 `form:"page_id" binding:"required,min=1"`
+}
+
+// lists the runs of a scheduled transfer, latest first
+func (server *Server) listScheduledTransferRuns(ctx *gin.Context) {
+	var uri scheduledTransferURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req listScheduledTransferRunsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	_, valid := server.lookupScheduledTransfer(ctx, uri.ID, true)
+	if !valid {
+		return
+	}
+
+	runs, err := server.store.ListScheduledTransferRuns(ctx, db.ListScheduledTransferRunsParams{
+		ScheduledTransferID: uri.ID,
+		Limit:               req.Limit,
+		Offset:              (req.Offset - 1) * req.Limit,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, runs)
+}
+
+type updateScheduledTransferRequest struct {
+	// zero values leave a field unchanged
+	Amount int64     `form:"amount" binding:"min=0"`
+	EndAt  time.Time `form:"end_at" time_format:"2006-01-02T15:04:05Z07:00"`
+	// pauses or resumes the schedule
+	Status string `form:"status" binding:"omitempty,oneof=active paused"`
+}
+
+func (server *Server) updateScheduledTransfer(ctx *gin.Context) {
+	var uri scheduledTransferURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req updateScheduledTransferRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	scheduled, valid := server.lookupScheduledTransfer(ctx, uri.ID, false)
+	if !valid {
+		return
+	}
+
+	if !req.EndAt.IsZero() && req.EndAt.Before(scheduled.NextRunAt) {
+		err := errors.New("end_at must not be before the next run")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	server.writeScheduledTransfer(ctx, db.UpdateScheduledTransferParams{
+		ID:     uri.ID,
+		Amount: sql.NullInt64{Int64: req.Amount, Valid: req.Amount != 0},
+		EndAt:  sql.NullTime{Time: req.EndAt, Valid: !req.EndAt.IsZero()},
+		Status: sql.NullString{String: req.Status, Valid: req.Status != ""},
+	})
+}
+
+// cancels a scheduled transfer, its runs are kept
+func (server *Server) cancelScheduledTransfer(ctx *gin.Context) {
+	var uri scheduledTransferURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	_, valid := server.lookupScheduledTransfer(ctx, uri.ID, false)
+	if !valid {
+		return
+	}
+
+	server.writeScheduledTransfer(ctx, db.UpdateScheduledTransferParams{
+		ID:     uri.ID,
+		Status: sql.NullString{String: util.ScheduleCancelled, Valid: true},
+	})
+}
+
+// updates a scheduled transfer that has not finished and writes it to the response
+func (server *Server) writeScheduledTransfer(ctx *gin.Context, arg db.UpdateScheduledTransferParams) {
+	scheduled, err := server.store.UpdateScheduledTransferTx(ctx, arg)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// the schedule exists, so it was completed or cancelled
+			err := errors.New("scheduled transfer is completed or cancelled")
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, scheduled)
+}
+
+// loads a scheduled transfer the caller owns, bankers and admins may also read other users' ones
+func (server *Server) lookupScheduledTransfer(ctx *gin.Context, id int64, read bool) (db.ScheduledTransfer, bool) {
+	scheduled, err := server.store.GetScheduledTransfer(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return db.ScheduledTransfer{}, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return db.ScheduledTransfer{}, false
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	allowed := scheduled.Owner == authPayload.Username ||
+		(read && hasRole(authPayload, util.BankerRole, util.AdminRole))
+	if !allowed {
+		err := errors.New("scheduled transfer doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return db.ScheduledTransfer{}, false
+	}
+
+	return scheduled, true
+}
@@ -0,0 +1,529 @@
+package api
+
+import (
+	mockdb "BankAppGo/db/mock"
+	db "BankAppGo/db/sqlc"
+	"BankAppGo/db/util"
+	"BankAppGo/token"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateScheduledTransferAPI(t *testing.T) {
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+
+	account1 := randomAccount(user1.Username)
+	account1.Currency = util.USD
+	account2 := randomAccount(user2.Username)
+	account2.Currency = util.USD
+
+	startAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	scheduled := randomScheduledTransfer(user1.Username, account1.ID, account2.ID)
+
+	body := func(changes gin.H) gin.H {
+		data := gin.H{
+			"from_account_id": account1.ID,
+			"to_account_id":   account2.ID,
+			"amount":          10,
+			"currency":        account1.Currency,
+			"cadence":         util.MonthlyCadence,
+			"start_at":        startAt.Format(time.RFC3339),
+		}
+		for key, value := range changes {
+			data[key] = value
+		}
+		return data
+	}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: body(nil),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().
+					CreateScheduledTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.CreateScheduledTransferParams) (db.ScheduledTransfer, error) {
+						require.Equal(t, user1.Username, arg.Owner)
+						require.Equal(t, account1.ID, arg.FromAccountID)
+						require.Equal(t, account2.ID, arg.ToAccountID)
+						require.Equal(t, int64(10), arg.Amount)
+						require.Equal(t, util.MonthlyCadence, arg.Cadence)
+						require.True(t, startAt.Equal(arg.StartAt))
+						require.False(t, arg.EndAt.Valid)
+						return scheduled, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "WithEndDate",
+			body: body(gin.H{"end_at": startAt.AddDate(1, 0, 0).Format(time.RFC3339)}),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(2).Return(account1, nil)
+				store.EXPECT().
+					CreateScheduledTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.CreateScheduledTransferParams) (db.ScheduledTransfer, error) {
+						require.True(t, arg.EndAt.Valid)
+						require.True(t, startAt.AddDate(1, 0, 0).Equal(arg.EndAt.Time))
+						return scheduled, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "StartInPast",
+			body: body(gin.H{"start_at": time.Now().Add(-time.Hour).Format(time.RFC3339)}),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateScheduledTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "EndBeforeStart",
+			body: body(gin.H{"end_at": startAt.Add(-time.Minute).Format(time.RFC3339)}),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateScheduledTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "UnsupportedCadence",
+			body: body(gin.H{"cadence": "hourly"}),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateScheduledTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "UnauthorizedUser",
+			body: body(nil),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user2.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().CreateScheduledTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "CurrencyMismatch",
+			body: body(nil),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				other := account2
+				other.Currency = util.EUR
+
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(other, nil)
+				store.EXPECT().CreateScheduledTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "AccountClosed",
+			body: body(nil),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				closed := account2
+				closed.Status = util.AccountClosed
+
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(closed, nil)
+				store.EXPECT().CreateScheduledTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "AccountFrozen",
+			body: body(nil),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				frozen := account1
+				frozen.Status = util.AccountFrozen
+
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(frozen, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().CreateScheduledTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusLocked, recorder.Code)
+			},
+		},
+		{
+			name: "NoAuthorization",
+			body: body(nil),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateScheduledTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data := GinHToURLValues(tc.body)
+			request, err := http.NewRequest(http.MethodPost, "/scheduled-transfers", strings.NewReader(data.Encode()))
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestUpdateScheduledTransferAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	scheduled := randomScheduledTransfer(user.Username, util.RandomInt(1, 1000), util.RandomInt(1, 1000))
+
+	testCases := []struct {
+		name          string
+		method        string
+		body          gin.H
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "Pause",
+			method: http.MethodPatch,
+			body:   gin.H{"status": util.SchedulePaused},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.UpdateScheduledTransferParams{
+					ID:     scheduled.ID,
+					Status: sql.NullString{String: util.SchedulePaused, Valid: true},
+				}
+				paused := scheduled
+				paused.Status = util.SchedulePaused
+
+				store.EXPECT().GetScheduledTransfer(gomock.Any(), gomock.Eq(scheduled.ID)).Times(1).Return(scheduled, nil)
+				store.EXPECT().UpdateScheduledTransferTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(paused, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "ChangeAmount",
+			method: http.MethodPatch,
+			body:   gin.H{"amount": 25},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.UpdateScheduledTransferParams{
+					ID:     scheduled.ID,
+					Amount: sql.NullInt64{Int64: 25, Valid: true},
+				}
+				store.EXPECT().GetScheduledTransfer(gomock.Any(), gomock.Eq(scheduled.ID)).Times(1).Return(scheduled, nil)
+				store.EXPECT().UpdateScheduledTransferTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(scheduled, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "EndBeforeNextRun",
+			method: http.MethodPatch,
+			body:   gin.H{"end_at": scheduled.NextRunAt.Add(-time.Hour).Format(time.RFC3339)},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetScheduledTransfer(gomock.Any(), gomock.Eq(scheduled.ID)).Times(1).Return(scheduled, nil)
+				store.EXPECT().UpdateScheduledTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "CannotComplete",
+			method: http.MethodPatch,
+			body:   gin.H{"status": util.ScheduleCompleted},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetScheduledTransfer(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "Cancel",
+			method: http.MethodDelete,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.UpdateScheduledTransferParams{
+					ID:     scheduled.ID,
+					Status: sql.NullString{String: util.ScheduleCancelled, Valid: true},
+				}
+				store.EXPECT().GetScheduledTransfer(gomock.Any(), gomock.Eq(scheduled.ID)).Times(1).Return(scheduled, nil)
+				store.EXPECT().UpdateScheduledTransferTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(scheduled, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "AlreadyFinished",
+			method: http.MethodDelete,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetScheduledTransfer(gomock.Any(), gomock.Eq(scheduled.ID)).Times(1).Return(scheduled, nil)
+				store.EXPECT().UpdateScheduledTransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.ScheduledTransfer{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:   "BankerCannotCancel",
+			method: http.MethodDelete,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "banker", util.BankerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetScheduledTransfer(gomock.Any(), gomock.Eq(scheduled.ID)).Times(1).Return(scheduled, nil)
+				store.EXPECT().UpdateScheduledTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:   "NotFound",
+			method: http.MethodDelete,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetScheduledTransfer(gomock.Any(), gomock.Eq(scheduled.ID)).Times(1).Return(db.ScheduledTransfer{}, sql.ErrNoRows)
+				store.EXPECT().UpdateScheduledTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data := GinHToURLValues(tc.body)
+			url := fmt.Sprintf("/scheduled-transfers/%d", scheduled.ID)
+			request, err := http.NewRequest(tc.method, url, strings.NewReader(data.Encode()))
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestGetScheduledTransferAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	scheduled := randomScheduledTransfer(user.Username, util.RandomInt(1, 1000), util.RandomInt(1, 1000))
+
+	testCases := []struct {
+		name     string
+		username string
+		role     string
+		status   int
+	}{
+		{name: "Owner", username: user.Username, role: util.DepositorRole, status: http.StatusOK},
+		{name: "Banker", username: "banker", role: util.BankerRole, status: http.StatusOK},
+		{name: "OtherDepositor", username: "other", role: util.DepositorRole, status: http.StatusUnauthorized},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetScheduledTransfer(gomock.Any(), gomock.Eq(scheduled.ID)).Times(1).Return(scheduled, nil)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/scheduled-transfers/%d", scheduled.ID)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, tc.role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, tc.status, recorder.Code)
+		})
+	}
+}
+
+func TestRunDueScheduledTransfers(t *testing.T) {
+	now := time.Now()
+	limits := &db.TransferLimits{PerTransfer: 1000, Daily: 5000}
+	arg := db.RunScheduledTransferTxParams{Now: now, Limits: limits}
+
+	testCases := []struct {
+		name       string
+		buildStubs func(store *mockdb.MockStore)
+		runs       int
+		wantErr    bool
+	}{
+		{
+			name: "RunsUntilNoneDue",
+			buildStubs: func(store *mockdb.MockStore) {
+				failed := db.RunScheduledTransferTxResult{
+					Run: db.ScheduledTransferRun{Error: sql.NullString{String: "insufficient funds", Valid: true}},
+				}
+				gomock.InOrder(
+					store.EXPECT().RunScheduledTransferTx(gomock.Any(), gomock.Eq(arg)).Return(db.RunScheduledTransferTxResult{}, nil),
+					store.EXPECT().RunScheduledTransferTx(gomock.Any(), gomock.Eq(arg)).Return(failed, nil),
+					store.EXPECT().RunScheduledTransferTx(gomock.Any(), gomock.Eq(arg)).Return(db.RunScheduledTransferTxResult{}, sql.ErrNoRows),
+				)
+			},
+			runs: 2,
+		},
+		{
+			name: "StopsOnError",
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					store.EXPECT().RunScheduledTransferTx(gomock.Any(), gomock.Eq(arg)).Return(db.RunScheduledTransferTxResult{}, nil),
+					store.EXPECT().RunScheduledTransferTx(gomock.Any(), gomock.Eq(arg)).Return(db.RunScheduledTransferTxResult{}, errors.New("connection reset")),
+				)
+			},
+			runs:    1,
+			wantErr: true,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			runs, err := runDueScheduledTransfers(context.Background(), store, now, limits)
+			require.Equal(t, tc.runs, runs)
+			if tc.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func randomScheduledTransfer(owner string, fromAccountID int64, toAccountID int64) db.ScheduledTransfer {
+	startAt := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	return db.ScheduledTransfer{
+		ID:            util.RandomInt(1, 1000),
+		Owner:         owner,
+		FromAccountID: fromAccountID,
+		ToAccountID:   toAccountID,
+		Amount:        util.RandomMoney() + 1,
+		Cadence:       util.MonthlyCadence,
+		StartAt:       startAt,
+		NextRunAt:     startAt,
+		Status:        util.ScheduleActive,
+	}
+}
@@ -0,0 +1,51 @@
+package api
+
+import (
+	db "BankAppGo/db/sqlc"
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+)
+
+// runs every scheduled transfer that is due, until none is left or one cannot be run.
+// Several servers may do this at once, each claims different schedules
+func runDueScheduledTransfers(ctx context.Context, store db.Store, now time.Time, limits *db.TransferLimits) (int, error) {
+	runs := 0
+	for {
+		result, err := store.RunScheduledTransferTx(ctx, db.RunScheduledTransferTxParams{
+			Now:    now,
+			Limits: limits,
+		})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return runs, nil
+			}
+			return runs, err
+		}
+		runs++
+
+		if result.Run.Error.Valid {
+			log.Printf("scheduled transfer %d failed: %s", result.Run.ScheduledTransferID, result.Run.Error.String)
+		}
+	}
+}
+
+// runs the due scheduled transfers every interval until ctx is done
+func executeScheduledTransfers(ctx context.Context, store db.Store, interval time.Duration, limits *db.TransferLimits) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_, err := runDueScheduledTransfers(ctx, store, time.Now(), limits)
+			if err != nil {
+				log.Println("cannot run scheduled transfers:", err)
+			}
+		}
+	}
+}
@@ -3,8 +3,12 @@ package api
 import (
 	db "BankAppGo/db/sqlc"
 	"BankAppGo/db/util"
+	"BankAppGo/fx"
 	token_maker "BankAppGo/token"
+	"context"
 	"fmt"
+	"net"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
@@ -13,55 +17,189 @@ import (
 
 // Server serves HTTP requests for banking service
 type Server struct {
-	config     util.Config
-	store      db.Store
-	tokenMaker token_maker.TokenMaker
-	router     *gin.Engine
+	config       util.Config
+	store        db.Store
+	tokenMaker   token_maker.TokenMaker
+	rateProvider fx.FXRateProvider
+	revocations  *revocationCache
+	router       *gin.Engine
 }
 
+// used when the config does not set how often token revocations are synced
+const defaultRevocationSyncInterval = 10 * time.Second
+
+// used when the config does not set how often due scheduled transfers are looked for
+const defaultScheduledTransferInterval = time.Minute
+
+// used when the config does not set how long a hold may wait for its capture
+const defaultHoldTTL = 7 * 24 * time.Hour
+
+// how often expired holds are released
+const holdExpiryInterval = time.Minute
+
+// used when the config does not set how long senders may ask to reverse a transfer
+const defaultReversalGracePeriod = 24 * time.Hour
+
 // NewServer creaetes a new HTTP server and sets up routing
 func NewServer(config util.Config, store db.Store) (*Server, error) {
 	tokenMaker, err := token_maker.NewPasetoMaker(config.TokenSymmetricKey)
 	if err != nil {
 		return nil, fmt.Errorf("cannot create token maker: %w", err)
 	}
+	rateProvider, err := newRateProvider(config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create rate provider: %w", err)
+	}
+
 	server := &Server{
-		config:     config,
-		store:      store,
-		tokenMaker: tokenMaker,
+		config:       config,
+		store:        store,
+		tokenMaker:   tokenMaker,
+		rateProvider: rateProvider,
+		revocations:  newRevocationCache(),
 	}
 
 	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
 		v.RegisterValidation("currency", validCurrency)
 	}
 
-	server.setupRouter()
+	err = server.setupRouter()
+	if err != nil {
+		return nil, fmt.Errorf("cannot set up routes: %w", err)
+	}
 	return server, nil
 }
 
-func (server *Server) setupRouter() {
+func (server *Server) setupRouter() error {
 	router := gin.Default()
+	// handlers pass the gin context to the store, the audit of the request is read from its request context
+	router.ContextWithFallback = true
 
-	router.POST("/users", server.createUser)
-	router.POST("/users/login", server.loginUser)
+	publicRoutes := router.Group("/").Use(auditMiddleware())
 
-	authRoutes := router.Group("/").Use(authMiddleware(server.tokenMaker))
+	publicRoutes.POST("/users", server.createUser)
+	publicRoutes.POST("/users/login", server.loginUser)
+	publicRoutes.POST("/tokens/renew_access", server.renewAccessToken)
+
+	authRoutes := router.Group("/").Use(
+		authMiddleware(server.tokenMaker),
+		revocationMiddleware(server.revocations),
+		auditMiddleware(),
+	)
+
+	authRoutes.POST("/users/logout", server.logoutUser)
+	authRoutes.POST("/users/:username/revoke-sessions", requireRoles(util.AdminRole), server.revokeUserSessions)
+	authRoutes.PUT("/users/:username/transfer-limits/:currency", requireRoles(util.AdminRole), server.setTransferLimit)
+	authRoutes.DELETE("/users/:username/transfer-limits/:currency", requireRoles(util.AdminRole), server.deleteTransferLimit)
 
 	authRoutes.POST("/accounts", server.createAccount)
 	authRoutes.GET("/accounts/:id", server.getAccount)
+	authRoutes.GET("/accounts/:id/entries", server.listEntries)
+	authRoutes.GET("/accounts/:id/statement", server.getStatement)
 	authRoutes.GET("/accounts", server.listAccounts)
-	authRoutes.PATCH("/accounts", server.updateAccount)
-	authRoutes.DELETE("/accounts/:id", server.deleteAccount)
+	authRoutes.POST("/accounts/:id/adjustments", requireRoles(util.AdminRole), server.adjustBalance)
+	authRoutes.POST("/accounts/:id/deposits", requireRoles(util.BankerRole, util.AdminRole), server.createDeposit)
+	authRoutes.POST("/accounts/:id/withdrawals", requireRoles(util.BankerRole, util.AdminRole), server.createWithdrawal)
+	authRoutes.POST("/accounts/:id/status", requireRoles(util.AdminRole), server.setAccountStatus)
+	authRoutes.DELETE("/accounts/:id", server.closeAccount)
 
 	authRoutes.POST("/transfers", server.createTransfer)
+	authRoutes.GET("/transfers", server.listTransfers)
+	authRoutes.GET("/transfers/quote", server.quoteTransfer)
+	authRoutes.GET("/transfers/:id", server.getTransfer)
+	authRoutes.POST("/transfers/authorize", server.authorizeTransfer)
+	authRoutes.POST("/transfers/:id/capture", server.captureHold)
+	authRoutes.POST("/transfers/:id/void", server.voidHold)
+	authRoutes.POST("/transfers/:id/reverse", server.reverseTransfer)
+	authRoutes.POST("/transfers/:id/reverse/approve", server.approveTransferReversal)
+
+	authRoutes.POST("/scheduled-transfers", server.createScheduledTransfer)
+	authRoutes.GET("/scheduled-transfers", server.listScheduledTransfers)
+	authRoutes.GET("/scheduled-transfers/:id", server.getScheduledTransfer)
+	authRoutes.GET("/scheduled-transfers/:id/runs", server.listScheduledTransferRuns)
+	authRoutes.PATCH("/scheduled-transfers/:id", server.updateScheduledTransfer)
+	authRoutes.DELETE("/scheduled-transfers/:id", server.cancelScheduledTransfer)
+
+	authRoutes.POST("/fee-schedules", requireRoles(util.AdminRole), server.createFeeSchedule)
+	authRoutes.GET("/fee-schedules/:currency", server.getFeeSchedule)
+
+	authRoutes.PUT("/interest-rates/:currency/:kind", requireRoles(util.AdminRole), server.setInterestRate)
+	authRoutes.GET("/interest-rates/:currency/:kind", server.getInterestRate)
+
+	authRoutes.POST("/reconciliations", requireRoles(util.AdminRole), server.reconcileLedger)
+	authRoutes.GET("/reconciliations", requireRoles(util.AdminRole), server.listReconciliationReports)
+
+	authRoutes.GET("/audit", requireRoles(util.AdminRole), server.listAuditEvents)
+
+	// the REST API generated from the protos, it authenticates and audits its calls itself
+	gateway, err := server.newGateway()
+	if err != nil {
+		return err
+	}
+	router.Any(gatewayPrefix+"/*path", gin.WrapH(gateway))
+
+	// registered last, the document describes the routes above
+	err = server.setupDocs(router)
+	if err != nil {
+		return err
+	}
 
 	server.router = router
+	return nil
+}
 
+// loads the exchange rate table, without one only same-currency transfers are possible
+func newRateProvider(config util.Config) (fx.FXRateProvider, error) {
+	if len(config.FXRatesFile) == 0 {
+		return fx.NewStaticRateProvider(nil)
+	}
+	return fx.LoadStaticRateProvider(config.FXRatesFile)
 }
 
 // Start runs the HTTP server on a specific address
 func (server *Server) Start(address string) error {
-	return server.router.Run(address)
+	return server.Serve(address, "")
+}
+
+// Serve runs the HTTP server, and the gRPC server as well unless grpcAddress is empty.
+// Both share the store, token maker and revocations. Returns when either of them stops
+func (server *Server) Serve(address string, grpcAddress string) error {
+	// revocations are loaded before serving so revoked tokens are refused from the first request
+	err := server.revocations.refresh(context.Background(), server.store)
+	if err != nil {
+		return fmt.Errorf("cannot load token revocations: %w", err)
+	}
+
+	interval := server.config.RevocationSyncInterval
+	if interval <= 0 {
+		interval = defaultRevocationSyncInterval
+	}
+	go server.revocations.sync(context.Background(), server.store, interval)
+
+	scheduledInterval := server.config.ScheduledTransferInterval
+	if scheduledInterval <= 0 {
+		scheduledInterval = defaultScheduledTransferInterval
+	}
+	go executeScheduledTransfers(context.Background(), server.store, scheduledInterval, server.defaultTransferLimits())
+	go expireHolds(context.Background(), server.store, holdExpiryInterval)
+
+	if len(grpcAddress) == 0 {
+		return server.router.Run(address)
+	}
+
+	listener, err := net.Listen("tcp", grpcAddress)
+	if err != nil {
+		return fmt.Errorf("cannot listen for gRPC on %s: %w", grpcAddress, err)
+	}
+
+	errs := make(chan error, 2)
+	go func() {
+		errs <- server.newGRPCServer().Serve(listener)
+	}()
+	go func() {
+		errs <- server.router.Run(address)
+	}()
+	return <-errs
 }
 
 func errorResponse(err error) gin.H {
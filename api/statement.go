@@ -0,0 +1,83 @@
+package api
+
+import (
+	db "BankAppGo/db/sqlc"
+	"BankAppGo/statement"
+	"BankAppGo/token"
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type getStatementURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type getStatementRequest struct {
+	Format string `form:"format" binding:"required,oneof=csv pdf"`
+	// YYYY-MM
+	Month string `form:"month" binding:"required"`
+}
+
+func (server *Server) getStatement(ctx *gin.Context) {
+	var uri getStatementURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req getStatementRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	from, to, err := statement.MonthRange(req.Month)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	result, err := server.store.StatementTx(ctx, db.StatementTxParams{
+		AccountID: uri.ID,
+		From:      from,
+		To:        to,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if !canReadAccount(authPayload, result.Account) {
+		err := errors.New("account does not belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	// rendered in full before anything is sent so a failure can still be reported as JSON
+	var buf bytes.Buffer
+	contentType := "text/csv"
+	if req.Format == "pdf" {
+		contentType = "application/pdf"
+		err = statement.WritePDF(&buf, result)
+	} else {
+		err = statement.WriteCSV(&buf, result)
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	filename := fmt.Sprintf("statement-%d-%s.%s", uri.ID, req.Month, req.Format)
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	ctx.Data(http.StatusOK, contentType, buf.Bytes())
+}
@@ -0,0 +1,207 @@
+package api
+
+import (
+	mockdb "BankAppGo/db/mock"
+	db "BankAppGo/db/sqlc"
+	"BankAppGo/db/util"
+	"BankAppGo/token"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenewAccessTokenAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore, refreshToken string, payload *token.Payload)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder, server *Server)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore, refreshToken string, payload *token.Payload) {
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Eq(payload.ID)).
+					Times(1).
+					Return(db.Session{
+						ID:           payload.ID,
+						Username:     user.Username,
+						RefreshToken: refreshToken,
+						ExpiresAt:    payload.ExpiredAt,
+					}, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, server *Server) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				data, err := io.ReadAll(recorder.Body)
+				require.NoError(t, err)
+
+				var response renewAccessTokenResponse
+				err = json.Unmarshal(data, &response)
+				require.NoError(t, err)
+
+				payload, err := server.tokenMaker.VerifyToken(response.AccessToken)
+				require.NoError(t, err)
+				require.Equal(t, user.Username, payload.Username)
+				require.WithinDuration(t, payload.ExpiredAt, response.AccessTokenExpiresAt, time.Second)
+			},
+		},
+		{
+			name: "SessionNotFound",
+			buildStubs: func(store *mockdb.MockStore, refreshToken string, payload *token.Payload) {
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Eq(payload.ID)).
+					Times(1).
+					Return(db.Session{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, server *Server) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "BlockedSession",
+			buildStubs: func(store *mockdb.MockStore, refreshToken string, payload *token.Payload) {
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Eq(payload.ID)).
+					Times(1).
+					Return(db.Session{
+						ID:           payload.ID,
+						Username:     user.Username,
+						RefreshToken: refreshToken,
+						IsBlocked:    true,
+						ExpiresAt:    payload.ExpiredAt,
+					}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, server *Server) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "MismatchedToken",
+			buildStubs: func(store *mockdb.MockStore, refreshToken string, payload *token.Payload) {
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Eq(payload.ID)).
+					Times(1).
+					Return(db.Session{
+						ID:           payload.ID,
+						Username:     user.Username,
+						RefreshToken: "another token",
+						ExpiresAt:    payload.ExpiredAt,
+					}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, server *Server) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+
+			//start test server and send request
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			refreshToken, payload, err := server.tokenMaker.CreateToken(user.Username, util.DepositorRole, token.TokenTypeRefresh, time.Hour)
+			require.NoError(t, err)
+
+			// builds stub
+			tc.buildStubs(store, refreshToken, payload)
+
+			data := GinHToURLValues(gin.H{"refresh_token": refreshToken})
+
+			url := "/tokens/renew_access"
+			request, err := http.NewRequest(http.MethodPost, url, strings.NewReader(data.Encode()))
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			server.router.ServeHTTP(recorder, request)
+			// check response
+			tc.checkResponse(t, recorder, server)
+
+		})
+	}
+}
+
+// an access token can't renew itself, only the refresh token of a session can
+func TestRenewAccessTokenWithAccessToken(t *testing.T) {
+	user, _ := randomUser(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetSession(gomock.Any(), gomock.Any()).Times(0)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	accessToken, _, err := server.tokenMaker.CreateToken(user.Username, util.DepositorRole, token.TokenTypeAccess, time.Hour)
+	require.NoError(t, err)
+
+	data := GinHToURLValues(gin.H{"refresh_token": accessToken})
+	request, err := http.NewRequest(http.MethodPost, "/tokens/renew_access", strings.NewReader(data.Encode()))
+	require.NoError(t, err)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
+
+// the renewed access token carries the role the user has now, not the one the refresh token was issued with
+func TestRenewAccessTokenAfterDemotion(t *testing.T) {
+	user, _ := randomUser(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	refreshToken, payload, err := server.tokenMaker.CreateToken(user.Username, util.AdminRole, token.TokenTypeRefresh, time.Hour)
+	require.NoError(t, err)
+
+	store.EXPECT().
+		GetSession(gomock.Any(), gomock.Eq(payload.ID)).
+		Times(1).
+		Return(db.Session{
+			ID:           payload.ID,
+			Username:     user.Username,
+			RefreshToken: refreshToken,
+			ExpiresAt:    payload.ExpiredAt,
+		}, nil)
+	store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+
+	data := GinHToURLValues(gin.H{"refresh_token": refreshToken})
+	request, err := http.NewRequest(http.MethodPost, "/tokens/renew_access", strings.NewReader(data.Encode()))
+	require.NoError(t, err)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var response renewAccessTokenResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+
+	accessPayload, err := server.tokenMaker.VerifyToken(response.AccessToken)
+	require.NoError(t, err)
+	require.Equal(t, util.DepositorRole, accessPayload.Role)
+}
@@ -2,11 +2,17 @@ package api
 
 import (
 	db "BankAppGo/db/sqlc"
+	"BankAppGo/db/util"
+	"BankAppGo/fx"
 	"BankAppGo/token"
+	"context"
 	"database/sql"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -28,6 +34,13 @@ func (server *Server) createTransfer(ctx *gin.Context) {
 
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
 
+	// a malformed key is refused before the store is touched
+	idempotency, err := newIdempotency(ctx, authPayload.Username, req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
 	// check currency transfer will occur in
 	account, valid := server.validate_account(ctx, req.FromAccountID, req.Currency)
 	if !valid {
@@ -40,20 +53,49 @@ func (server *Server) createTransfer(ctx *gin.Context) {
 		return
 	}
 
-	if _, valid = server.validate_account(ctx, req.ToAccountID, req.Currency); !valid {
+	toAccount, valid := server.lookupAccount(ctx, req.ToAccountID)
+	if !valid {
 		return
 	}
 
-	// prepare create transfer params
-	arg := db.TransferTxParams{
-		FromAccountID: req.FromAccountID,
-		ToAccountID:   req.ToAccountID,
-		Amount:        req.Amount,
+	var transfer db.TransferTxResult
+	if toAccount.Currency == req.Currency {
+		// prepare create transfer params
+		arg := db.TransferTxParams{
+			FromAccountID: req.FromAccountID,
+			ToAccountID:   req.ToAccountID,
+			Amount:        req.Amount,
+			Limits:        server.defaultTransferLimits(),
+			Idempotency:   idempotency,
+		}
+		// creates transfer
+		transfer, err = server.store.TransferTx(ctx, arg)
+	} else {
+		// converts the amount into the destination currency at the current rate
+		arg, valid := server.bindExchangeTransferParams(ctx, req, toAccount.Currency)
+		if !valid {
+			return
+		}
+		arg.Limits = server.defaultTransferLimits()
+		arg.Idempotency = idempotency
+		transfer, err = server.store.ExchangeTransferTx(ctx, arg)
 	}
-	// creates transfer
-	transfer, err := server.store.TransferTx(ctx, arg)
 
 	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrInsufficientFunds), errors.Is(err, db.ErrTransferLimitExceeded), errors.Is(err, db.ErrAmountBelowFee):
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+			return
+		case errors.Is(err, db.ErrAccountClosed), errors.Is(err, db.ErrSystemAccount):
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		case errors.Is(err, db.ErrAccountFrozen):
+			ctx.JSON(http.StatusLocked, errorResponse(err))
+			return
+		case errors.Is(err, db.ErrIdempotencyKeyReused):
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
@@ -62,7 +104,140 @@ func (server *Server) createTransfer(ctx *gin.Context) {
 
 }
 
-func (server *Server) validate_account(ctx *gin.Context, accountID int64, currency string) (db.Account, bool) {
+type transferQuoteResponse struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+	Fee      int64  `json:"fee"`
+	// what is left of the amount once the fee is taken, in the source currency
+	NetAmount int64 `json:"net_amount"`
+	// credited to the destination account, in its currency
+	ToAmount     int64  `json:"to_amount"`
+	ToCurrency   string `json:"to_currency"`
+	ExchangeRate string `json:"exchange_rate"`
+}
+
+// previews the fee and the amount credited by a transfer without moving money
+func (server *Server) quoteTransfer(ctx *gin.Context) {
+	var req transferRequest
+
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	account, valid := server.validate_account(ctx, req.FromAccountID, req.Currency)
+	if !valid {
+		return
+	}
+
+	if account.Owner != authPayload.Username {
+		err := errors.New("from account doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	toAccount, valid := server.lookupAccount(ctx, req.ToAccountID)
+	if !valid {
+		return
+	}
+
+	fee, valid := server.transferFee(ctx, req.Currency, req.Amount)
+	if !valid {
+		return
+	}
+
+	response := transferQuoteResponse{
+		Amount:       req.Amount,
+		Currency:     req.Currency,
+		Fee:          fee,
+		NetAmount:    req.Amount - fee,
+		ToAmount:     req.Amount - fee,
+		ToCurrency:   toAccount.Currency,
+		ExchangeRate: "1",
+	}
+
+	if toAccount.Currency != req.Currency {
+		// the fee is taken before the rest is converted
+		net := req
+		net.Amount = response.NetAmount
+		arg, valid := server.bindExchangeTransferParams(ctx, net, toAccount.Currency)
+		if !valid {
+			return
+		}
+		response.ToAmount = arg.ToAmount
+		response.ExchangeRate = arg.ExchangeRate
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// returns the fee the schedule of currency charges on amount, zero without a schedule
+func (server *Server) transferFee(ctx *gin.Context, currency string, amount int64) (int64, bool) {
+	schedule, err := server.store.GetFeeSchedule(ctx, currency)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, true
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return 0, false
+	}
+
+	fee, err := db.TransferFee(schedule, amount)
+	if err != nil {
+		if errors.Is(err, db.ErrAmountBelowFee) {
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+			return 0, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return 0, false
+	}
+	return fee, true
+}
+
+func (server *Server) bindExchangeTransferParams(ctx *gin.Context, req transferRequest, toCurrency string) (db.ExchangeTransferTxParams, bool) {
+	arg, err := server.exchangeTransferParams(ctx, req, toCurrency)
+	if err != nil {
+		if errors.Is(err, fx.ErrRateNotFound) || errors.Is(err, errAmountTooSmallToConvert) {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return arg, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return arg, false
+	}
+	return arg, true
+}
+
+// converted amounts below one minor unit of the destination currency cannot be transferred
+var errAmountTooSmallToConvert = errors.New("amount is too small to convert")
+
+// converts the amount of a transfer into the destination currency at the current rate
+func (server *Server) exchangeTransferParams(ctx context.Context, req transferRequest, toCurrency string) (db.ExchangeTransferTxParams, error) {
+	quote, err := server.rateProvider.GetQuote(ctx, req.Currency, toCurrency)
+	if err != nil {
+		return db.ExchangeTransferTxParams{}, err
+	}
+
+	toAmount, err := quote.Convert(req.Amount)
+	if err != nil {
+		return db.ExchangeTransferTxParams{}, err
+	}
+	if toAmount <= 0 {
+		return db.ExchangeTransferTxParams{}, fmt.Errorf("%w: %d %s to %s", errAmountTooSmallToConvert, req.Amount, req.Currency, toCurrency)
+	}
+
+	return db.ExchangeTransferTxParams{
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+		ToAmount:      toAmount,
+		ExchangeRate:  quote.Rate,
+		QuotedAt:      quote.QuotedAt,
+	}, nil
+}
+
+func (server *Server) lookupAccount(ctx *gin.Context, accountID int64) (db.Account, bool) {
 	account, err := server.store.GetAccount(ctx, int64(accountID))
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -73,6 +248,15 @@ func (server *Server) validate_account(ctx *gin.Context, accountID int64, curren
 		return db.Account{}, false
 	}
 
+	return account, true
+}
+
+func (server *Server) validate_account(ctx *gin.Context, accountID int64, currency string) (db.Account, bool) {
+	account, valid := server.lookupAccount(ctx, accountID)
+	if !valid {
+		return db.Account{}, false
+	}
+
 	if account.Currency != currency {
 		err := fmt.Errorf("account [%d] currency mismatch: %s vs %s", accountID, account.Currency, currency)
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
@@ -82,3 +266,156 @@ func (server *Server) validate_account(ctx *gin.Context, accountID int64, curren
 	return account, true
 
 }
+
+type listTransfersRequest struct {
+	// one of the caller's accounts, all of them if empty
+	AccountID             int64     `form:"account_id" binding:"min=0"`
+	Direction             string    `form:"direction" binding:"omitempty,oneof=in out"`
+	CounterpartyAccountID int64     `form:"counterparty_account_id" binding:"min=0"`
+	MinAmount             int64     `form:"min_amount" binding:"min=0"`
+	MaxAmount             int64     `form:"max_amount" binding:"omitempty,gtefield=MinAmount"`
+	From                  time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To                    time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00" binding:"omitempty,gtfield=From"`
+	Sort                  string    `form:"sort" binding:"omitempty,oneof=date amount"`
+	// newest or largest first unless asc
+	Order string `form:"order" binding:"omitempty,oneof=asc desc"`
+	// next_cursor of the previous page, empty for the first one
+	Cursor   string `form:"cursor"`
+	PageSize int64  `form:"page_size" binding:"required,min=1,max=100"`
+}
+
+type listTransfersResponse struct {
+	Transfers []db.Transfer `json:"transfers"`
+	// passed as cursor to get the next page, omitted on the last one
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+func (server *Server) listTransfers(ctx *gin.Context) {
+	var req listTransfersRequest
+
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	afterID, afterAmount, err := decodeTransferCursor(req.Cursor)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	accountIDs, valid := server.transferAccountIDs(ctx, authPayload, req.AccountID)
+	if !valid {
+		return
+	}
+
+	// one extra transfer tells whether there is a next page
+	transfers, err := server.store.FilterTransfers(ctx, db.FilterTransfersParams{
+		AccountIDs:            accountIDs,
+		Direction:             req.Direction,
+		CounterpartyAccountID: req.CounterpartyAccountID,
+		MinAmount:             req.MinAmount,
+		MaxAmount:             req.MaxAmount,
+		From:                  req.From,
+		To:                    req.To,
+		SortBy:                req.Sort,
+		Descending:            req.Order != "asc",
+		AfterID:               afterID,
+		AfterAmount:           afterAmount,
+		Limit:                 req.PageSize + 1,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	response := listTransfersResponse{Transfers: transfers}
+	if int64(len(transfers)) > req.PageSize {
+		response.Transfers = transfers[:req.PageSize]
+		response.NextCursor = encodeTransferCursor(response.Transfers[req.PageSize-1])
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+type getTransferRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) getTransfer(ctx *gin.Context) {
+	var req getTransferRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	transfer, valid := server.lookupTransfer(ctx, req.ID)
+	if !valid {
+		return
+	}
+
+	// bankers and admins may read any transfer, depositors only the ones touching their accounts
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if !hasRole(authPayload, util.BankerRole, util.AdminRole) {
+		accountIDs, err := server.store.ListAccountIDsByOwner(ctx, authPayload.Username)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		if !slices.Contains(accountIDs, transfer.FromAccountID) && !slices.Contains(accountIDs, transfer.ToAccountID) {
+			err := errors.New("transfer does not involve an account of the authenticated user")
+			ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+	}
+
+	ctx.JSON(http.StatusOK, transfer)
+}
+
+// returns the accounts whose transfers the caller may list, narrowed to accountID if set
+func (server *Server) transferAccountIDs(ctx *gin.Context, authPayload *token.Payload, accountID int64) ([]int64, bool) {
+	if accountID != 0 && hasRole(authPayload, util.BankerRole, util.AdminRole) {
+		return []int64{accountID}, true
+	}
+
+	accountIDs, err := server.store.ListAccountIDsByOwner(ctx, authPayload.Username)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return nil, false
+	}
+
+	if accountID == 0 {
+		return accountIDs, true
+	}
+
+	if !slices.Contains(accountIDs, accountID) {
+		err := errors.New("account does not belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return nil, false
+	}
+	return []int64{accountID}, true
+}
+
+// the cursor holds the keyset of the last transfer of a page, its id and amount
+func encodeTransferCursor(transfer db.Transfer) string {
+	keyset := fmt.Sprintf("%d:%d", transfer.ID, transfer.Amount)
+	return base64.RawURLEncoding.EncodeToString([]byte(keyset))
+}
+
+func decodeTransferCursor(cursor string) (id int64, amount int64, err error) {
+	if cursor == "" {
+		return 0, 0, nil
+	}
+
+	keyset, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err == nil {
+		_, err = fmt.Sscanf(string(keyset), "%d:%d", &id, &amount)
+	}
+	if err != nil || id <= 0 {
+		return 0, 0, errors.New("invalid cursor")
+	}
+	return id, amount, nil
+}
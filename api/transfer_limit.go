@@ -0,0 +1,91 @@
+package api
+
+import (
+	db "BankAppGo/db/sqlc"
+	"BankAppGo/token"
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type transferLimitURI struct {
+	Username string `uri:"username" binding:"required,alphanum"`
+	Currency string `uri:"currency" binding:"required,currency"`
+}
+
+type setTransferLimitRequest struct {
+	// zero lifts the limit
+	PerTransferLimit int64 `form:"per_transfer_limit" binding:"min=0"`
+	DailyLimit       int64 `form:"daily_limit" binding:"min=0"`
+}
+
+// overrides the default transfer limits of a user in one currency
+func (server *Server) setTransferLimit(ctx *gin.Context) {
+	var uri transferLimitURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req setTransferLimitRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	_, err := server.store.GetUser(ctx, uri.Username)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	limit, err := server.store.SetTransferLimitTx(ctx, db.UpsertTransferLimitParams{
+		Username:         uri.Username,
+		Currency:         uri.Currency,
+		PerTransferLimit: req.PerTransferLimit,
+		DailyLimit:       req.DailyLimit,
+		UpdatedBy:        authPayload.Username,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, limit)
+}
+
+// removes the override, the user falls back to the default transfer limits
+func (server *Server) deleteTransferLimit(ctx *gin.Context) {
+	var uri transferLimitURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	err := server.store.DeleteTransferLimitTx(ctx, db.DeleteTransferLimitParams{
+		Username: uri.Username,
+		Currency: uri.Currency,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, server.defaultTransferLimits())
+}
+
+// limits applied to users without an override, from the config
+func (server *Server) defaultTransferLimits() *db.TransferLimits {
+	return &db.TransferLimits{
+		PerTransfer: server.config.TransferLimit,
+		Daily:       server.config.DailyTransferLimit,
+	}
+}
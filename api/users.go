@@ -3,11 +3,14 @@ package api
 import (
 	db "BankAppGo/db/sqlc"
 	"BankAppGo/db/util"
+	"BankAppGo/token"
 	"database/sql"
+	"errors"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/lib/pq"
 )
 
@@ -32,8 +35,12 @@ type loginUserRequest struct {
 }
 
 type loginUserResponse struct {
-	AccessToken string       `json:"access_token"`
-	User        userResponse `json:"user"`
+	SessionID             uuid.UUID    `json:"session_id"`
+	AccessToken           string       `json:"access_token"`
+	AccessTokenExpiresAt  time.Time    `json:"access_token_expires_at"`
+	RefreshToken          string       `json:"refresh_token"`
+	RefreshTokenExpiresAt time.Time    `json:"refresh_token_expires_at"`
+	User                  userResponse `json:"user"`
 }
 
 func newUserResponse(user db.User) userResponse {
@@ -69,7 +76,7 @@ func (server *Server) createUser(ctx *gin.Context) {
 	}
 
 	// creates account
-	user, err := server.store.CreateUser(ctx, arg)
+	user, err := server.store.CreateUserTx(ctx, arg)
 
 	if err != nil {
 		if pqError, ok := err.(*pq.Error); ok {
@@ -114,8 +121,10 @@ func (server *Server) loginUser(ctx *gin.Context) {
 		return
 	}
 
-	accessToken, err := server.tokenMaker.CreateToken(
+	accessToken, accessPayload, err := server.tokenMaker.CreateToken(
 		user.Username,
+		user.Role,
+		token.TokenTypeAccess,
 		server.config.AccessTokenDuration,
 	)
 
@@ -124,11 +133,149 @@ func (server *Server) loginUser(ctx *gin.Context) {
 		return
 	}
 
+	refreshToken, refreshPayload, err := server.tokenMaker.CreateToken(
+		user.Username,
+		user.Role,
+		token.TokenTypeRefresh,
+		server.config.RefreshTokenDuration,
+	)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// the refresh token is only honoured while its session exists and is not blocked
+	session, err := server.store.CreateSessionTx(ctx, db.CreateSessionParams{
+		ID:           refreshPayload.ID,
+		Username:     user.Username,
+		RefreshToken: refreshToken,
+		UserAgent:    ctx.Request.UserAgent(),
+		ClientIp:     ctx.ClientIP(),
+		IsBlocked:    false,
+		ExpiresAt:    refreshPayload.ExpiredAt,
+	})
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
 	response := loginUserResponse{
-		AccessToken: accessToken,
-		User:        newUserResponse(user),
+		SessionID:             session.ID,
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  accessPayload.ExpiredAt,
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: refreshPayload.ExpiredAt,
+		User:                  newUserResponse(user),
 	}
 
 	ctx.JSON(http.StatusOK, response)
 
 }
+
+type logoutUserRequest struct {
+	// optional, the session of this refresh token is blocked as well
+	RefreshToken string `form:"refresh_token"`
+}
+
+type logoutUserResponse struct {
+	RevokedUntil time.Time `json:"revoked_until"`
+}
+
+func (server *Server) logoutUser(ctx *gin.Context) {
+	var req logoutUserRequest
+
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	arg := db.LogoutTxParams{
+		Username:             authPayload.Username,
+		AccessTokenID:        authPayload.ID,
+		AccessTokenExpiresAt: authPayload.ExpiredAt,
+	}
+
+	if req.RefreshToken != "" {
+		refreshPayload, err := server.tokenMaker.VerifyToken(req.RefreshToken)
+		if err != nil {
+			ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+
+		if err := refreshPayload.CheckType(token.TokenTypeRefresh); err != nil {
+			ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+
+		if refreshPayload.Username != authPayload.Username {
+			err := errors.New("refresh token does not belong to the authenticated user")
+			ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+		arg.SessionID = uuid.NullUUID{UUID: refreshPayload.ID, Valid: true}
+		arg.SessionExpiresAt = refreshPayload.ExpiredAt
+	}
+
+	result, err := server.store.LogoutTx(ctx, arg)
+	if err != nil {
+		// another server revoked the token before this one synced it
+		if pqError, ok := err.(*pq.Error); ok {
+			switch pqError.Code.Name() {
+			case "unique_violation":
+				err := errors.New("token has been revoked")
+				ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+				return
+			}
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	server.revocations.add(result.AccessTokenRevocation)
+	if result.RefreshTokenRevocation != nil {
+		server.revocations.add(*result.RefreshTokenRevocation)
+	}
+
+	ctx.JSON(http.StatusOK, logoutUserResponse{RevokedUntil: result.AccessTokenRevocation.ExpiresAt})
+}
+
+type revokeUserSessionsURI struct {
+	Username string `uri:"username" binding:"required,alphanum"`
+}
+
+// blocks every session of a user and revokes all tokens issued to them so far, as the revoke-sessions command does.
+// The revocation applies at once on this server, the others pick it up on their next sync
+func (server *Server) revokeUserSessions(ctx *gin.Context) {
+	var uri revokeUserSessionsURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	_, err := server.store.GetUser(ctx, uri.Username)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	result, err := server.store.RevokeUserSessionsTx(ctx, db.RevokeUserSessionsTxParams{
+		Username:  uri.Username,
+		ExpiresAt: time.Now().Add(server.config.TokenLifetime()),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	server.revocations.add(result.Revocation)
+
+	ctx.JSON(http.StatusOK, result)
+}
@@ -4,6 +4,7 @@ import (
 	mockdb "BankAppGo/db/mock"
 	db "BankAppGo/db/sqlc"
 	"BankAppGo/db/util"
+	"BankAppGo/token"
 	"bytes"
 	"database/sql"
 	"encoding/json"
@@ -15,9 +16,11 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
 	"github.com/lib/pq"
 	"github.com/stretchr/testify/require"
 )
@@ -74,7 +77,7 @@ func TestCreateUserAPI(t *testing.T) {
 					Email:    user.Email,
 				}
 				store.EXPECT().
-					CreateUser(gomock.Any(), EqCreateUserParams(arg, password)).
+					CreateUserTx(gomock.Any(), EqCreateUserParams(arg, password)).
 					Times(1).
 					Return(user, nil)
 			},
@@ -95,7 +98,7 @@ func TestCreateUserAPI(t *testing.T) {
 				"email":    user.Email,
 			},
 			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateUserTx(gomock.Any(), gomock.Any()).Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				//check response from http request
@@ -112,7 +115,7 @@ func TestCreateUserAPI(t *testing.T) {
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					CreateUser(gomock.Any(), gomock.Any()).
+					CreateUserTx(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
@@ -130,7 +133,7 @@ func TestCreateUserAPI(t *testing.T) {
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					CreateUser(gomock.Any(), gomock.Any()).
+					CreateUserTx(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
@@ -153,7 +156,7 @@ func TestCreateUserAPI(t *testing.T) {
 					Email:    user.Email,
 				}
 				store.EXPECT().
-					CreateUser(gomock.Any(), EqCreateUserParams(arg, password)).
+					CreateUserTx(gomock.Any(), EqCreateUserParams(arg, password)).
 					Times(1).Return(db.User{}, &pq.Error{Code: "23505"})
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
@@ -222,6 +225,14 @@ func TestUserLogin(t *testing.T) {
 					GetUser(gomock.Any(), gomock.Eq(arg.Username)).
 					Times(1).
 					Return(user, nil)
+				store.EXPECT().
+					CreateSessionTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.CreateSessionParams) (db.Session, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.False(t, arg.IsBlocked)
+						return db.Session{ID: arg.ID, Username: arg.Username}, nil
+					})
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, server *Server) {
 				//check response from http request
@@ -233,8 +244,10 @@ func TestUserLogin(t *testing.T) {
 				require.NoError(t, err)
 
 				var LoginData struct {
-					AccessToken string  `json:"access_token"`
-					User        db.User `json:"user"`
+					SessionID    string  `json:"session_id"`
+					AccessToken  string  `json:"access_token"`
+					RefreshToken string  `json:"refresh_token"`
+					User         db.User `json:"user"`
 				}
 				err = json.Unmarshal(data, &LoginData)
 				require.NoError(t, err)
@@ -248,6 +261,11 @@ func TestUserLogin(t *testing.T) {
 				require.NoError(t, err)
 				require.NoError(t, payload.Valid())
 				require.Equal(t, payload.Username, user.Username)
+				require.Equal(t, user.Role, payload.Role)
+				// verify refresh token is bound to the session
+				refreshPayload, err := server.tokenMaker.VerifyToken(LoginData.RefreshToken)
+				require.NoError(t, err)
+				require.Equal(t, refreshPayload.ID.String(), LoginData.SessionID)
 
 			},
 		},
@@ -269,6 +287,26 @@ func TestUserLogin(t *testing.T) {
 
 			},
 		},
+		{
+			name: "CreateSessionError",
+			body: gin.H{
+				"username": user.Username,
+				"password": password,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					CreateSessionTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Session{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, server *Server) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
 		{
 			name: "UnauthorizedLoginAttempt",
 			body: gin.H{
@@ -332,6 +370,7 @@ func randomUser(t *testing.T) (user db.User, password string) {
 		Email:          util.RandomEmail(),
 		HashedPassword: hashed_pwd,
 		FullName:       util.RandomOwner(),
+		Role:           util.DepositorRole,
 	}
 	return
 }
@@ -357,3 +396,290 @@ func GinHToURLValues(data gin.H) url.Values {
 	}
 	return values
 }
+
+func TestLogoutUserAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		body          func(t *testing.T, server *Server) gin.H
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder, server *Server)
+	}{
+		{
+			name: "OK",
+			body: func(t *testing.T, server *Server) gin.H {
+				return gin.H{}
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					LogoutTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.LogoutTxParams) (db.LogoutTxResult, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.False(t, arg.SessionID.Valid)
+						return db.LogoutTxResult{
+							AccessTokenRevocation: db.TokenRevocation{
+								Username:  arg.Username,
+								TokenID:   uuid.NullUUID{UUID: arg.AccessTokenID, Valid: true},
+								ExpiresAt: arg.AccessTokenExpiresAt,
+								RevokedAt: time.Now(),
+							},
+						}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, server *Server) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Len(t, server.revocations.tokens, 1)
+			},
+		},
+		{
+			name: "BlocksSession",
+			body: func(t *testing.T, server *Server) gin.H {
+				refreshToken, _, err := server.tokenMaker.CreateToken(user.Username, util.DepositorRole, token.TokenTypeRefresh, time.Hour)
+				require.NoError(t, err)
+				return gin.H{"refresh_token": refreshToken}
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					LogoutTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.LogoutTxParams) (db.LogoutTxResult, error) {
+						require.True(t, arg.SessionID.Valid)
+						require.False(t, arg.SessionExpiresAt.IsZero())
+						return db.LogoutTxResult{
+							AccessTokenRevocation: db.TokenRevocation{
+								Username:  arg.Username,
+								TokenID:   uuid.NullUUID{UUID: arg.AccessTokenID, Valid: true},
+								ExpiresAt: arg.AccessTokenExpiresAt,
+							},
+							RefreshTokenRevocation: &db.TokenRevocation{
+								Username:  arg.Username,
+								TokenID:   uuid.NullUUID{UUID: arg.SessionID.UUID, Valid: true},
+								ExpiresAt: arg.SessionExpiresAt,
+							},
+						}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, server *Server) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				// the refresh token is revoked along with the access token
+				require.Len(t, server.revocations.tokens, 2)
+			},
+		},
+		{
+			name: "RefreshTokenOfOtherUser",
+			body: func(t *testing.T, server *Server) gin.H {
+				refreshToken, _, err := server.tokenMaker.CreateToken("otheruser", util.DepositorRole, token.TokenTypeRefresh, time.Hour)
+				require.NoError(t, err)
+				return gin.H{"refresh_token": refreshToken}
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().LogoutTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, server *Server) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "NoAuthorization",
+			body: func(t *testing.T, server *Server) gin.H {
+				return gin.H{}
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().LogoutTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, server *Server) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "AlreadyRevoked",
+			body: func(t *testing.T, server *Server) gin.H {
+				return gin.H{}
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					LogoutTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.LogoutTxResult{}, &pq.Error{Code: "23505"})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, server *Server) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			body: func(t *testing.T, server *Server) gin.H {
+				return gin.H{}
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					LogoutTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.LogoutTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, server *Server) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data := GinHToURLValues(tc.body(t, server))
+
+			url := "/users/logout"
+			request, err := http.NewRequest(http.MethodPost, url, strings.NewReader(data.Encode()))
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder, server)
+		})
+	}
+}
+
+func TestRevokeUserSessionsAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder, server *Server)
+	}{
+		{
+			name: "OK",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "admin", util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().
+					RevokeUserSessionsTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.RevokeUserSessionsTxParams) (db.RevokeUserSessionsTxResult, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.True(t, arg.ExpiresAt.After(time.Now().Add(time.Minute)))
+						return db.RevokeUserSessionsTxResult{
+							Revocation: db.TokenRevocation{
+								Username:  arg.Username,
+								ExpiresAt: arg.ExpiresAt,
+								RevokedAt: time.Now(),
+							},
+							BlockedSessions: 2,
+						}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, server *Server) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var result db.RevokeUserSessionsTxResult
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+				require.Equal(t, int64(2), result.BlockedSessions)
+
+				// tokens issued before are refused by this server at once
+				_, payload, err := server.tokenMaker.CreateToken(user.Username, util.DepositorRole, token.TokenTypeAccess, time.Minute)
+				require.NoError(t, err)
+				payload.IssuedAt = payload.IssuedAt.Add(-time.Second)
+				require.True(t, server.revocations.isRevoked(payload))
+			},
+		},
+		{
+			name: "Forbidden",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().RevokeUserSessionsTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, server *Server) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "UserNotFound",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "admin", util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(db.User{}, sql.ErrNoRows)
+				store.EXPECT().RevokeUserSessionsTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, server *Server) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "admin", util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().
+					RevokeUserSessionsTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.RevokeUserSessionsTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, server *Server) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				require.Empty(t, server.revocations.users)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/users/%s/revoke-sessions", user.Username)
+			request, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder, server)
+		})
+	}
+}
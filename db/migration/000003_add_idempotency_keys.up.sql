@@ -0,0 +1,13 @@
+CREATE TABLE "idempotency_keys" (
+  "username" varchar NOT NULL,
+  "key" varchar NOT NULL,
+  "request_path" varchar NOT NULL,
+  "request_hash" varchar NOT NULL,
+  "response_body" jsonb NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT 'now()',
+  PRIMARY KEY ("username", "key")
+);
+
+COMMENT ON COLUMN "idempotency_keys"."request_hash" IS 'sha256 fingerprint of the request body';
+
+ALTER TABLE "idempotency_keys" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
@@ -0,0 +1,2 @@
+ALTER TABLE IF EXISTS "account" DROP CONSTRAINT IF EXISTS "overdraft_limit_non_negative";
+ALTER TABLE IF EXISTS "account" DROP COLUMN IF EXISTS "overdraft_limit";
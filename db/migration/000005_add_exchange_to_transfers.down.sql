@@ -0,0 +1,3 @@
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "rate_quoted_at";
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "exchange_rate";
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "to_amount";
@@ -0,0 +1,13 @@
+ALTER TABLE "transfers" ADD COLUMN "to_amount" bigint;
+
+UPDATE "transfers" SET "to_amount" = "amount";
+
+ALTER TABLE "transfers" ALTER COLUMN "to_amount" SET NOT NULL;
+
+ALTER TABLE "transfers" ADD COLUMN "exchange_rate" numeric NOT NULL DEFAULT 1;
+
+ALTER TABLE "transfers" ADD COLUMN "rate_quoted_at" timestamptz;
+
+COMMENT ON COLUMN "transfers"."to_amount" IS 'credited to the destination account, in its currency';
+
+COMMENT ON COLUMN "transfers"."exchange_rate" IS 'destination minor units per source minor unit';
@@ -0,0 +1,15 @@
+CREATE TABLE "token_revocations" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "token_id" uuid UNIQUE,
+  "expires_at" timestamptz NOT NULL,
+  "revoked_at" timestamptz NOT NULL DEFAULT 'now()'
+);
+
+CREATE INDEX ON "token_revocations" ("expires_at");
+
+COMMENT ON COLUMN "token_revocations"."token_id" IS 'null revokes every token of the user issued at or before revoked_at';
+
+COMMENT ON COLUMN "token_revocations"."expires_at" IS 'when the revoked tokens expire on their own and the row can be deleted';
+
+ALTER TABLE "token_revocations" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "balance_adjustments";
+ALTER TABLE IF EXISTS "account" DROP COLUMN IF EXISTS "closed_at";
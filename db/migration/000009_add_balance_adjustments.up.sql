@@ -0,0 +1,27 @@
+ALTER TABLE "account" ADD COLUMN "closed_at" timestamptz;
+
+COMMENT ON COLUMN "account"."closed_at" IS 'set when the account is closed, its entries and transfers are kept';
+
+CREATE TABLE "balance_adjustments" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "entry_id" bigint UNIQUE NOT NULL,
+  "amount" bigint NOT NULL,
+  "reason" varchar NOT NULL,
+  "adjusted_by" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT 'now()'
+);
+
+CREATE INDEX ON "balance_adjustments" ("account_id");
+
+ALTER TABLE "balance_adjustments" ADD CONSTRAINT "adjustment_amount_non_zero" CHECK ("amount" <> 0);
+
+COMMENT ON COLUMN "balance_adjustments"."amount" IS 'positive credits the account, negative debits it';
+
+COMMENT ON COLUMN "balance_adjustments"."adjusted_by" IS 'admin who made the adjustment';
+
+ALTER TABLE "balance_adjustments" ADD FOREIGN KEY ("account_id") REFERENCES "account" ("id");
+
+ALTER TABLE "balance_adjustments" ADD FOREIGN KEY ("entry_id") REFERENCES "entries" ("id");
+
+ALTER TABLE "balance_adjustments" ADD FOREIGN KEY ("adjusted_by") REFERENCES "users" ("username");
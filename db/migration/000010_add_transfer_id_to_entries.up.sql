@@ -0,0 +1,25 @@
+ALTER TABLE "entries" ADD COLUMN "transfer_id" bigint;
+
+CREATE INDEX ON "entries" ("transfer_id");
+
+COMMENT ON COLUMN "entries"."transfer_id" IS 'set for entries booked by a transfer';
+
+ALTER TABLE "entries" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+-- a transfer and its two entries were written in one transaction, so they share its timestamp.
+-- Older entries are linked to the transfer matching them on account, amount and time,
+-- only when the entry matches that one transfer and the transfer matches exactly its two entries
+WITH "candidates" AS (
+  SELECT e."id" AS "entry_id", t."id" AS "transfer_id",
+         count(*) OVER (PARTITION BY e."id") AS "transfers_matched",
+         count(*) OVER (PARTITION BY t."id") AS "entries_matched"
+  FROM "transfers" t
+  JOIN "entries" e ON e."created_at" = t."created_at" AND (
+    (e."account_id" = t."from_account_id" AND e."amount" = -t."amount") OR
+    (e."account_id" = t."to_account_id" AND e."amount" = t."to_amount")
+  )
+)
+UPDATE "entries" e
+SET "transfer_id" = c."transfer_id"
+FROM "candidates" c
+WHERE e."id" = c."entry_id" AND c."transfers_matched" = 1 AND c."entries_matched" = 2;
@@ -0,0 +1,58 @@
+CREATE TABLE "scheduled_transfers" (
+  "id" bigserial PRIMARY KEY,
+  "owner" varchar NOT NULL,
+  "from_account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "cadence" varchar NOT NULL,
+  "start_at" timestamptz NOT NULL,
+  "next_run_at" timestamptz NOT NULL,
+  "end_at" timestamptz,
+  "status" varchar NOT NULL DEFAULT 'active',
+  "created_at" timestamptz NOT NULL DEFAULT 'now()'
+);
+
+CREATE TABLE "scheduled_transfer_runs" (
+  "id" bigserial PRIMARY KEY,
+  "scheduled_transfer_id" bigint NOT NULL,
+  "scheduled_for" timestamptz NOT NULL,
+  "transfer_id" bigint,
+  "error" varchar,
+  "created_at" timestamptz NOT NULL DEFAULT 'now()'
+);
+
+CREATE INDEX ON "scheduled_transfers" ("owner");
+
+CREATE INDEX ON "scheduled_transfers" ("next_run_at") WHERE "status" = 'active';
+
+CREATE UNIQUE INDEX ON "scheduled_transfer_runs" ("scheduled_transfer_id", "scheduled_for");
+
+ALTER TABLE "scheduled_transfers" ADD CONSTRAINT "scheduled_amount_positive" CHECK ("amount" > 0);
+
+ALTER TABLE "scheduled_transfers" ADD CONSTRAINT "scheduled_cadence_supported" CHECK ("cadence" IN ('once', 'daily', 'weekly', 'monthly'));
+
+ALTER TABLE "scheduled_transfers" ADD CONSTRAINT "scheduled_status_supported" CHECK ("status" IN ('active', 'paused', 'completed', 'cancelled'));
+
+COMMENT ON COLUMN "scheduled_transfers"."cadence" IS 'once, daily, weekly or monthly';
+
+COMMENT ON COLUMN "scheduled_transfers"."start_at" IS 'first run, monthly runs keep its day of the month';
+
+COMMENT ON COLUMN "scheduled_transfers"."end_at" IS 'no run is made after it, runs forever when null';
+
+COMMENT ON COLUMN "scheduled_transfers"."status" IS 'active, paused, completed or cancelled';
+
+COMMENT ON COLUMN "scheduled_transfer_runs"."scheduled_for" IS 'the next_run_at the run was made for';
+
+COMMENT ON COLUMN "scheduled_transfer_runs"."transfer_id" IS 'set when the transfer was made';
+
+COMMENT ON COLUMN "scheduled_transfer_runs"."error" IS 'set when the transfer failed';
+
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("from_account_id") REFERENCES "account" ("id");
+
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("to_account_id") REFERENCES "account" ("id");
+
+ALTER TABLE "scheduled_transfer_runs" ADD FOREIGN KEY ("scheduled_transfer_id") REFERENCES "scheduled_transfers" ("id");
+
+ALTER TABLE "scheduled_transfer_runs" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
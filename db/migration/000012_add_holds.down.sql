@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "holds";
+ALTER TABLE IF EXISTS "account" DROP COLUMN IF EXISTS "held_amount";
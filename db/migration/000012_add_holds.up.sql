@@ -0,0 +1,39 @@
+ALTER TABLE "account" ADD COLUMN "held_amount" bigint NOT NULL DEFAULT 0;
+
+ALTER TABLE "account" ADD CONSTRAINT "held_amount_non_negative" CHECK ("held_amount" >= 0);
+
+COMMENT ON COLUMN "account"."held_amount" IS 'reserved by pending holds, taken off the available but not the ledger balance';
+
+CREATE TABLE "holds" (
+  "id" bigserial PRIMARY KEY,
+  "from_account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "expires_at" timestamptz NOT NULL,
+  "transfer_id" bigint,
+  "settled_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT 'now()'
+);
+
+CREATE INDEX ON "holds" ("from_account_id");
+
+CREATE INDEX ON "holds" ("expires_at") WHERE "status" = 'pending';
+
+ALTER TABLE "holds" ADD CONSTRAINT "hold_amount_positive" CHECK ("amount" > 0);
+
+ALTER TABLE "holds" ADD CONSTRAINT "hold_status_supported" CHECK ("status" IN ('pending', 'captured', 'voided', 'expired'));
+
+COMMENT ON COLUMN "holds"."amount" IS 'reserved on the source account, the most that can be captured';
+
+COMMENT ON COLUMN "holds"."status" IS 'pending, captured, voided or expired';
+
+COMMENT ON COLUMN "holds"."transfer_id" IS 'set when the hold is captured';
+
+COMMENT ON COLUMN "holds"."settled_at" IS 'when the hold stopped being pending';
+
+ALTER TABLE "holds" ADD FOREIGN KEY ("from_account_id") REFERENCES "account" ("id");
+
+ALTER TABLE "holds" ADD FOREIGN KEY ("to_account_id") REFERENCES "account" ("id");
+
+ALTER TABLE "holds" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
@@ -0,0 +1,45 @@
+ALTER TABLE "transfers" ADD COLUMN "reversal_of" bigint;
+
+ALTER TABLE "transfers" ADD COLUMN "reversed_amount" bigint NOT NULL DEFAULT 0;
+
+CREATE INDEX ON "transfers" ("reversal_of");
+
+ALTER TABLE "transfers" ADD CONSTRAINT "reversed_amount_within_amount" CHECK ("reversed_amount" >= 0 AND "reversed_amount" <= "amount");
+
+COMMENT ON COLUMN "transfers"."reversal_of" IS 'set on a reversal, the transfer it sends money back for';
+
+COMMENT ON COLUMN "transfers"."reversed_amount" IS 'how much of the amount was sent back by reversals';
+
+ALTER TABLE "transfers" ADD FOREIGN KEY ("reversal_of") REFERENCES "transfers" ("id");
+
+CREATE TABLE "transfer_reversals" (
+  "id" bigserial PRIMARY KEY,
+  "transfer_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "reason" varchar NOT NULL,
+  "requested_by" varchar NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "reversal_transfer_id" bigint,
+  "created_at" timestamptz NOT NULL DEFAULT 'now()',
+  "completed_at" timestamptz
+);
+
+CREATE INDEX ON "transfer_reversals" ("transfer_id");
+
+CREATE UNIQUE INDEX ON "transfer_reversals" ("transfer_id") WHERE "status" = 'pending';
+
+ALTER TABLE "transfer_reversals" ADD CONSTRAINT "reversal_amount_positive" CHECK ("amount" > 0);
+
+ALTER TABLE "transfer_reversals" ADD CONSTRAINT "reversal_status_supported" CHECK ("status" IN ('pending', 'completed'));
+
+COMMENT ON COLUMN "transfer_reversals"."amount" IS 'sent back to the source account, in its currency';
+
+COMMENT ON COLUMN "transfer_reversals"."status" IS 'pending until the recipient consents, then completed';
+
+COMMENT ON COLUMN "transfer_reversals"."reversal_transfer_id" IS 'set once the money was sent back';
+
+ALTER TABLE "transfer_reversals" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "transfer_reversals" ADD FOREIGN KEY ("requested_by") REFERENCES "users" ("username");
+
+ALTER TABLE "transfer_reversals" ADD FOREIGN KEY ("reversal_transfer_id") REFERENCES "transfers" ("id");
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "unlinked_transfers";
+DROP TABLE IF EXISTS "reconciliation_reports";
@@ -0,0 +1,34 @@
+CREATE TABLE "reconciliation_reports" (
+  "id" bigserial PRIMARY KEY,
+  "started_at" timestamptz NOT NULL,
+  "finished_at" timestamptz NOT NULL,
+  "accounts_checked" bigint NOT NULL,
+  "transfers_checked" bigint NOT NULL,
+  "transfers_unverifiable" bigint NOT NULL DEFAULT 0,
+  "discrepancy_count" bigint NOT NULL,
+  "discrepancies" jsonb NOT NULL,
+  "run_by" varchar,
+  "created_at" timestamptz NOT NULL DEFAULT 'now()'
+);
+
+CREATE INDEX ON "reconciliation_reports" ("created_at");
+
+COMMENT ON COLUMN "reconciliation_reports"."transfers_unverifiable" IS 'checked transfers listed in unlinked_transfers, not counted as discrepancies';
+
+COMMENT ON COLUMN "reconciliation_reports"."discrepancies" IS 'every discrepancy found by the run, as reported';
+
+COMMENT ON COLUMN "reconciliation_reports"."run_by" IS 'admin who ran the check, empty when run from the command line';
+
+ALTER TABLE "reconciliation_reports" ADD FOREIGN KEY ("run_by") REFERENCES "users" ("username");
+
+-- transfers made before entries recorded their transfer, whose entries migration 000010 could not tell apart.
+-- Their entries can't be checked, reconcile counts them apart from the discrepancies
+CREATE TABLE "unlinked_transfers" (
+  "transfer_id" bigint PRIMARY KEY
+);
+
+ALTER TABLE "unlinked_transfers" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+INSERT INTO "unlinked_transfers" ("transfer_id")
+SELECT t."id" FROM "transfers" t
+WHERE NOT EXISTS (SELECT 1 FROM "entries" e WHERE e."transfer_id" = t."id");
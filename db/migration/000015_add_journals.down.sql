@@ -0,0 +1,12 @@
+-- the opening entries of customer accounts were written by the up migration, in the transaction the journal was created in.
+-- The entries it adopted are older and stay
+DELETE FROM "entries" e
+USING "journals" j
+WHERE e."journal_id" = j."id" AND j."kind" = 'opening' AND e."created_at" >= j."created_at"
+  AND e."transfer_id" IS NULL;
+DROP TABLE IF EXISTS "system_accounts";
+DELETE FROM "entries" WHERE "account_id" IN (SELECT "id" FROM "account" WHERE "owner" IN ('cash_clearing', 'fees_income', 'fx_suspense'));
+DELETE FROM "account" WHERE "owner" IN ('cash_clearing', 'fees_income', 'fx_suspense');
+DELETE FROM "users" WHERE "username" IN ('cash_clearing', 'fees_income', 'fx_suspense');
+ALTER TABLE IF EXISTS "entries" DROP COLUMN IF EXISTS "journal_id";
+DROP TABLE IF EXISTS "journals";
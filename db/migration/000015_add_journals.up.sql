@@ -0,0 +1,96 @@
+CREATE TABLE "journals" (
+  "id" bigserial PRIMARY KEY,
+  "kind" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT 'now()'
+);
+
+ALTER TABLE "journals" ADD CONSTRAINT "journal_kind_supported" CHECK ("kind" IN ('opening', 'transfer', 'deposit', 'withdrawal', 'adjustment'));
+
+COMMENT ON COLUMN "journals"."kind" IS 'opening, transfer, deposit, withdrawal or adjustment';
+
+ALTER TABLE "entries" ADD COLUMN "journal_id" bigint;
+
+CREATE INDEX ON "entries" ("journal_id");
+
+COMMENT ON COLUMN "entries"."journal_id" IS 'posting the entry belongs to, the entries of a posting sum to zero per currency';
+
+ALTER TABLE "entries" ADD FOREIGN KEY ("journal_id") REFERENCES "journals" ("id");
+
+CREATE TABLE "system_accounts" (
+  "kind" varchar NOT NULL,
+  "currency" varchar NOT NULL,
+  "account_id" bigint UNIQUE NOT NULL,
+  PRIMARY KEY ("kind", "currency")
+);
+
+ALTER TABLE "system_accounts" ADD CONSTRAINT "system_account_kind_supported" CHECK ("kind" IN ('cash_clearing', 'fees_income', 'fx_suspense'));
+
+COMMENT ON COLUMN "system_accounts"."kind" IS 'cash_clearing, fees_income or fx_suspense';
+
+ALTER TABLE "system_accounts" ADD FOREIGN KEY ("account_id") REFERENCES "account" ("id");
+
+-- an owner has one account per currency, so each kind of system account has its own owner.
+-- The password hash matches no password, so nobody can log in as a system user
+INSERT INTO "users" ("username", "hashed_password", "full_name", "email") VALUES
+  ('cash_clearing', '!', 'Cash-in clearing', 'cash_clearing@system.invalid'),
+  ('fees_income', '!', 'Fees income', 'fees_income@system.invalid'),
+  ('fx_suspense', '!', 'FX suspense', 'fx_suspense@system.invalid');
+
+-- system accounts are opened here for the currencies already held, the store opens
+-- the others the first time it posts in them
+INSERT INTO "account" ("owner", "balance", "currency")
+SELECT k.kind, 0, c.currency
+FROM (VALUES ('cash_clearing'), ('fees_income'), ('fx_suspense')) AS k (kind)
+CROSS JOIN (SELECT DISTINCT "currency" FROM "account") AS c;
+
+INSERT INTO "system_accounts" ("kind", "currency", "account_id")
+SELECT "owner", "currency", "id" FROM "account"
+WHERE "owner" IN ('cash_clearing', 'fees_income', 'fx_suspense');
+
+-- the ledger so far is booked as one opening posting that sums to zero per currency:
+-- each account is posted the part of its balance no entry explains, as accounts used to be
+-- opened with a balance, and cash-in clearing the opposite. Exchanges made so far moved money
+-- between currencies without a suspense leg, so their entries join the posting and what they
+-- left in each currency is booked on FX suspense
+INSERT INTO "journals" ("kind") VALUES ('opening');
+
+WITH "opening" AS (
+  SELECT "id" FROM "journals" WHERE "kind" = 'opening'
+), "unexplained" AS (
+  SELECT a."id", a."currency", a."balance" - COALESCE(SUM(e."amount"), 0) AS "amount"
+  FROM "account" a
+  LEFT JOIN "entries" e ON e."account_id" = a."id"
+  GROUP BY a."id"
+), "exchanged" AS (
+  SELECT a."currency", SUM(e."amount") AS "amount"
+  FROM "entries" e
+  JOIN "account" a ON a."id" = e."account_id"
+  GROUP BY a."currency"
+)
+INSERT INTO "entries" ("account_id", "amount", "journal_id")
+SELECT l."account_id", l."amount", o."id"
+FROM (
+  SELECT "id" AS "account_id", "amount" FROM "unexplained"
+  UNION ALL
+  SELECT sa."account_id", -SUM(u."amount")
+  FROM "unexplained" u
+  JOIN "system_accounts" sa ON sa."kind" = 'cash_clearing' AND sa."currency" = u."currency"
+  GROUP BY sa."account_id"
+  UNION ALL
+  SELECT sa."account_id", -x."amount"
+  FROM "exchanged" x
+  JOIN "system_accounts" sa ON sa."kind" = 'fx_suspense' AND sa."currency" = x."currency"
+) AS l
+CROSS JOIN "opening" o
+WHERE l."amount" <> 0;
+
+UPDATE "entries"
+SET "journal_id" = (SELECT "id" FROM "journals" WHERE "kind" = 'opening')
+WHERE "journal_id" IS NULL;
+
+-- customer balances already include their opening entries
+UPDATE "account" a
+SET "balance" = e."amount"
+FROM "entries" e
+JOIN "journals" j ON j."id" = e."journal_id" AND j."kind" = 'opening'
+WHERE e."account_id" = a."id" AND a."id" IN (SELECT "account_id" FROM "system_accounts");
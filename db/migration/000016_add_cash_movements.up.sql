@@ -0,0 +1,32 @@
+CREATE TABLE "cash_movements" (
+  "id" bigserial PRIMARY KEY,
+  "kind" varchar NOT NULL,
+  "account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "external_reference" varchar NOT NULL,
+  "entry_id" bigint UNIQUE,
+  "created_by" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT 'now()'
+);
+
+CREATE INDEX ON "cash_movements" ("account_id");
+
+CREATE UNIQUE INDEX ON "cash_movements" ("kind", "external_reference");
+
+ALTER TABLE "cash_movements" ADD CONSTRAINT "cash_movement_kind_supported" CHECK ("kind" IN ('deposit', 'withdrawal'));
+
+ALTER TABLE "cash_movements" ADD CONSTRAINT "cash_movement_amount_positive" CHECK ("amount" > 0);
+
+COMMENT ON COLUMN "cash_movements"."kind" IS 'deposit or withdrawal';
+
+COMMENT ON COLUMN "cash_movements"."external_reference" IS 'id given by the payment processor, a movement is booked at most once per reference';
+
+COMMENT ON COLUMN "cash_movements"."entry_id" IS 'entry booked on the account, set once the movement is posted';
+
+COMMENT ON COLUMN "cash_movements"."created_by" IS 'user who requested the movement';
+
+ALTER TABLE "cash_movements" ADD FOREIGN KEY ("account_id") REFERENCES "account" ("id");
+
+ALTER TABLE "cash_movements" ADD FOREIGN KEY ("entry_id") REFERENCES "entries" ("id");
+
+ALTER TABLE "cash_movements" ADD FOREIGN KEY ("created_by") REFERENCES "users" ("username");
@@ -0,0 +1,5 @@
+ALTER TABLE IF EXISTS "account" ADD COLUMN IF NOT EXISTS "closed_at" timestamptz;
+UPDATE "account" SET "closed_at" = "status_changed_at" WHERE "status" = 'closed';
+ALTER TABLE IF EXISTS "account" DROP COLUMN IF EXISTS "status_changed_at";
+ALTER TABLE IF EXISTS "account" DROP COLUMN IF EXISTS "status_reason";
+ALTER TABLE IF EXISTS "account" DROP COLUMN IF EXISTS "status";
@@ -0,0 +1,17 @@
+ALTER TABLE "account" ADD COLUMN "status" varchar NOT NULL DEFAULT 'active';
+
+ALTER TABLE "account" ADD COLUMN "status_reason" varchar;
+
+ALTER TABLE "account" ADD COLUMN "status_changed_at" timestamptz;
+
+UPDATE "account" SET "status" = 'closed', "status_changed_at" = "closed_at" WHERE "closed_at" IS NOT NULL;
+
+ALTER TABLE "account" DROP COLUMN "closed_at";
+
+ALTER TABLE "account" ADD CONSTRAINT "account_status_supported" CHECK ("status" IN ('active', 'frozen', 'closed'));
+
+COMMENT ON COLUMN "account"."status" IS 'active, frozen or closed. Only active accounts are debited or credited, entries and transfers are kept';
+
+COMMENT ON COLUMN "account"."status_reason" IS 'why the account was frozen, unfrozen or closed';
+
+COMMENT ON COLUMN "account"."status_changed_at" IS 'when the status last changed';
@@ -0,0 +1,23 @@
+CREATE TABLE "transfer_limits" (
+  "username" varchar NOT NULL,
+  "currency" varchar NOT NULL,
+  "per_transfer_limit" bigint NOT NULL,
+  "daily_limit" bigint NOT NULL,
+  "updated_by" varchar NOT NULL,
+  "updated_at" timestamptz NOT NULL DEFAULT 'now()',
+  PRIMARY KEY ("username", "currency")
+);
+
+ALTER TABLE "transfer_limits" ADD CONSTRAINT "transfer_limits_not_negative" CHECK ("per_transfer_limit" >= 0 AND "daily_limit" >= 0);
+
+COMMENT ON COLUMN "transfer_limits"."per_transfer_limit" IS 'most a single transfer may move, unlimited when zero';
+
+COMMENT ON COLUMN "transfer_limits"."daily_limit" IS 'most the user may send in the currency per UTC day, unlimited when zero';
+
+COMMENT ON COLUMN "transfer_limits"."updated_by" IS 'admin who set the limits';
+
+ALTER TABLE "transfer_limits" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "transfer_limits" ADD FOREIGN KEY ("updated_by") REFERENCES "users" ("username");
+
+CREATE INDEX ON "transfers" ("from_account_id", "created_at");
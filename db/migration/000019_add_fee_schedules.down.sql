@@ -0,0 +1,5 @@
+ALTER TABLE IF EXISTS "transfers" DROP CONSTRAINT IF EXISTS "transfer_fee_below_amount";
+
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "fee";
+
+DROP TABLE IF EXISTS "fee_schedules";
@@ -0,0 +1,38 @@
+CREATE TABLE "fee_schedules" (
+  "id" bigserial PRIMARY KEY,
+  "currency" varchar NOT NULL,
+  "kind" varchar NOT NULL,
+  "flat_fee" bigint NOT NULL DEFAULT 0,
+  "rate_bps" bigint NOT NULL DEFAULT 0,
+  "tiers" jsonb NOT NULL DEFAULT '[]',
+  "min_fee" bigint NOT NULL DEFAULT 0,
+  "max_fee" bigint NOT NULL DEFAULT 0,
+  "created_by" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT 'now()'
+);
+
+CREATE INDEX ON "fee_schedules" ("currency", "id");
+
+ALTER TABLE "fee_schedules" ADD CONSTRAINT "fee_schedule_kind_supported" CHECK ("kind" IN ('flat', 'percentage', 'tiered'));
+
+ALTER TABLE "fee_schedules" ADD CONSTRAINT "fee_schedule_not_negative" CHECK ("flat_fee" >= 0 AND "rate_bps" >= 0 AND "min_fee" >= 0 AND "max_fee" >= 0);
+
+ALTER TABLE "fee_schedules" ADD CONSTRAINT "fee_schedule_caps_ordered" CHECK ("max_fee" = 0 OR "max_fee" >= "min_fee");
+
+COMMENT ON COLUMN "fee_schedules"."kind" IS 'flat, percentage or tiered';
+
+COMMENT ON COLUMN "fee_schedules"."rate_bps" IS 'basis points of the amount';
+
+COMMENT ON COLUMN "fee_schedules"."tiers" IS 'used by tiered schedules, the first tier whose up_to covers the amount applies';
+
+COMMENT ON COLUMN "fee_schedules"."max_fee" IS 'no cap when zero';
+
+COMMENT ON COLUMN "fee_schedules"."created_by" IS 'admin who set the schedule';
+
+ALTER TABLE "fee_schedules" ADD FOREIGN KEY ("created_by") REFERENCES "users" ("username");
+
+ALTER TABLE "transfers" ADD COLUMN "fee" bigint NOT NULL DEFAULT 0;
+
+ALTER TABLE "transfers" ADD CONSTRAINT "transfer_fee_below_amount" CHECK ("fee" >= 0 AND "fee" < "amount");
+
+COMMENT ON COLUMN "transfers"."fee" IS 'taken from the amount for the bank, in the source currency';
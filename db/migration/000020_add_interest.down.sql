@@ -0,0 +1,35 @@
+-- owner_currency_key can't be put back once an owner keeps a checking and a savings account in the same currency.
+-- Their entries, transfers and holds can't be merged into one account here, so the migration stops before changing anything
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM "account" GROUP BY "owner", "currency" HAVING count(*) > 1) THEN
+    RAISE EXCEPTION 'an owner has several accounts in one currency, close or merge them before migrating down';
+  END IF;
+END
+$$;
+DROP TABLE IF EXISTS "interest_accruals";
+DROP TABLE IF EXISTS "interest_rates";
+DELETE FROM "system_accounts" WHERE "kind" = 'interest_expense';
+-- interest paid is taken back out of the balances before its entries go, so every balance still
+-- equals the sum of its entries
+UPDATE "account" a
+SET "balance" = a."balance" - s."total"
+FROM (
+  SELECT e."account_id", SUM(e."amount") AS "total"
+  FROM "entries" e
+  JOIN "journals" j ON j."id" = e."journal_id"
+  WHERE j."kind" = 'interest'
+  GROUP BY e."account_id"
+) s
+WHERE a."id" = s."account_id";
+DELETE FROM "entries" WHERE "journal_id" IN (SELECT "id" FROM "journals" WHERE "kind" = 'interest');
+DELETE FROM "journals" WHERE "kind" = 'interest';
+DELETE FROM "account" WHERE "owner" = 'interest_expense';
+DELETE FROM "users" WHERE "username" = 'interest_expense';
+ALTER TABLE "system_accounts" DROP CONSTRAINT IF EXISTS "system_account_kind_supported";
+ALTER TABLE "system_accounts" ADD CONSTRAINT "system_account_kind_supported" CHECK ("kind" IN ('cash_clearing', 'fees_income', 'fx_suspense'));
+ALTER TABLE "journals" DROP CONSTRAINT IF EXISTS "journal_kind_supported";
+ALTER TABLE "journals" ADD CONSTRAINT "journal_kind_supported" CHECK ("kind" IN ('opening', 'transfer', 'deposit', 'withdrawal', 'adjustment'));
+ALTER TABLE "account" DROP CONSTRAINT IF EXISTS "owner_currency_kind_key";
+ALTER TABLE "account" ADD CONSTRAINT "owner_currency_key" UNIQUE ("owner", "currency");
+ALTER TABLE IF EXISTS "account" DROP COLUMN IF EXISTS "kind";
@@ -0,0 +1,80 @@
+ALTER TABLE "account" ADD COLUMN "kind" varchar NOT NULL DEFAULT 'checking';
+
+ALTER TABLE "account" ADD CONSTRAINT "account_kind_supported" CHECK ("kind" IN ('checking', 'savings'));
+
+COMMENT ON COLUMN "account"."kind" IS 'checking or savings, an account earns interest when a rate is set for its kind and currency';
+
+-- an owner may keep a checking and a savings account in the same currency
+ALTER TABLE "account" DROP CONSTRAINT "owner_currency_key";
+
+ALTER TABLE "account" ADD CONSTRAINT "owner_currency_kind_key" UNIQUE ("owner", "currency", "kind");
+
+CREATE TABLE "interest_rates" (
+  "currency" varchar NOT NULL,
+  "account_kind" varchar NOT NULL,
+  "annual_rate" varchar NOT NULL,
+  "updated_by" varchar NOT NULL,
+  "updated_at" timestamptz NOT NULL DEFAULT 'now()',
+  PRIMARY KEY ("currency", "account_kind")
+);
+
+COMMENT ON COLUMN "interest_rates"."annual_rate" IS 'decimal fraction of the balance paid over a year, 0.035 is 3.5%';
+
+COMMENT ON COLUMN "interest_rates"."updated_by" IS 'admin who set the rate';
+
+ALTER TABLE "interest_rates" ADD FOREIGN KEY ("updated_by") REFERENCES "users" ("username");
+
+CREATE TABLE "interest_accruals" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "accrual_date" date NOT NULL,
+  "balance" bigint NOT NULL,
+  "annual_rate" varchar NOT NULL,
+  "amount" bigint NOT NULL,
+  "entry_id" bigint,
+  "capitalized_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT 'now()'
+);
+
+-- a day is accrued at most once per account, so the job can be run again safely
+CREATE UNIQUE INDEX ON "interest_accruals" ("account_id", "accrual_date");
+
+CREATE INDEX ON "interest_accruals" ("accrual_date") WHERE "capitalized_at" IS NULL;
+
+ALTER TABLE "interest_accruals" ADD CONSTRAINT "interest_accrual_amount_not_negative" CHECK ("amount" >= 0);
+
+COMMENT ON COLUMN "interest_accruals"."balance" IS 'balance at the end of the day';
+
+COMMENT ON COLUMN "interest_accruals"."annual_rate" IS 'rate in force on the day';
+
+COMMENT ON COLUMN "interest_accruals"."amount" IS 'interest earned on the day, rounded to the minor unit with ties to even';
+
+COMMENT ON COLUMN "interest_accruals"."entry_id" IS 'entry that paid the interest, null when nothing was paid';
+
+COMMENT ON COLUMN "interest_accruals"."capitalized_at" IS 'when the interest was added to the balance, null until then';
+
+ALTER TABLE "interest_accruals" ADD FOREIGN KEY ("account_id") REFERENCES "account" ("id");
+
+ALTER TABLE "interest_accruals" ADD FOREIGN KEY ("entry_id") REFERENCES "entries" ("id");
+
+ALTER TABLE "journals" DROP CONSTRAINT "journal_kind_supported";
+
+ALTER TABLE "journals" ADD CONSTRAINT "journal_kind_supported" CHECK ("kind" IN ('opening', 'transfer', 'deposit', 'withdrawal', 'adjustment', 'interest'));
+
+COMMENT ON COLUMN "journals"."kind" IS 'opening, transfer, deposit, withdrawal, adjustment or interest';
+
+ALTER TABLE "system_accounts" DROP CONSTRAINT "system_account_kind_supported";
+
+ALTER TABLE "system_accounts" ADD CONSTRAINT "system_account_kind_supported" CHECK ("kind" IN ('cash_clearing', 'fees_income', 'fx_suspense', 'interest_expense'));
+
+COMMENT ON COLUMN "system_accounts"."kind" IS 'cash_clearing, fees_income, fx_suspense or interest_expense';
+
+INSERT INTO "users" ("username", "hashed_password", "full_name", "email") VALUES
+  ('interest_expense', '!', 'Interest expense', 'interest_expense@system.invalid');
+
+INSERT INTO "account" ("owner", "balance", "currency")
+SELECT DISTINCT 'interest_expense', 0, "currency" FROM "account";
+
+INSERT INTO "system_accounts" ("kind", "currency", "account_id")
+SELECT "owner", "currency", "id" FROM "account"
+WHERE "owner" = 'interest_expense';
@@ -0,0 +1,45 @@
+CREATE TABLE "audit_events" (
+  "id" bigserial PRIMARY KEY,
+  "actor" varchar,
+  "action" varchar NOT NULL,
+  "target_type" varchar,
+  "target_id" varchar,
+  "before" jsonb NOT NULL DEFAULT 'null',
+  "after" jsonb NOT NULL DEFAULT 'null',
+  "client_ip" varchar NOT NULL,
+  "request_id" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT 'now()'
+);
+
+CREATE INDEX ON "audit_events" ("actor", "id");
+
+CREATE INDEX ON "audit_events" ("target_type", "target_id", "id");
+
+CREATE INDEX ON "audit_events" ("created_at");
+
+COMMENT ON COLUMN "audit_events"."actor" IS 'user who made the request, null when nobody was authenticated';
+
+COMMENT ON COLUMN "audit_events"."action" IS 'method and route of the request';
+
+COMMENT ON COLUMN "audit_events"."target_type" IS 'kind of record changed, null when the change was not described';
+
+COMMENT ON COLUMN "audit_events"."before" IS 'the record before the change, null when it was created';
+
+COMMENT ON COLUMN "audit_events"."after" IS 'the record after the change';
+
+COMMENT ON COLUMN "audit_events"."request_id" IS 'X-Request-ID of the request, generated when the client sent none';
+
+-- events are written in the transaction of the change they record and never changed afterwards
+CREATE FUNCTION "audit_events_append_only"() RETURNS trigger AS $$
+BEGIN
+  RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "audit_events_no_update_or_delete"
+BEFORE UPDATE OR DELETE ON "audit_events"
+FOR EACH ROW EXECUTE FUNCTION "audit_events_append_only"();
+
+CREATE TRIGGER "audit_events_no_truncate"
+BEFORE TRUNCATE ON "audit_events"
+FOR EACH STATEMENT EXECUTE FUNCTION "audit_events_append_only"();
@@ -0,0 +1,41 @@
+ALTER TABLE "entries" ADD COLUMN "prev_hash" bytea;
+
+ALTER TABLE "entries" ADD COLUMN "hash" bytea;
+
+CREATE INDEX ON "entries" ("account_id", "id");
+
+COMMENT ON COLUMN "entries"."prev_hash" IS 'hash of the previous entry of the account, null on its first entry';
+
+COMMENT ON COLUMN "entries"."hash" IS 'sha256 of the entry contents and prev_hash';
+
+-- chains the entries booked so far, hashing them the way EntryHash in db/sqlc does
+DO $$
+DECLARE
+  e record;
+  prev_account bigint;
+  prev bytea;
+  h bytea;
+BEGIN
+  FOR e IN SELECT * FROM "entries" ORDER BY "account_id", "id" LOOP
+    IF prev_account IS DISTINCT FROM e."account_id" THEN
+      prev := NULL;
+      prev_account := e."account_id";
+    END IF;
+
+    h := sha256(
+      int8send(e."id") ||
+      int8send(e."account_id") ||
+      int8send(e."amount") ||
+      int8send((extract(epoch FROM e."created_at") * 1000000)::bigint) ||
+      CASE WHEN e."transfer_id" IS NULL THEN '\x00'::bytea ELSE '\x01'::bytea || int8send(e."transfer_id") END ||
+      CASE WHEN e."journal_id" IS NULL THEN '\x00'::bytea ELSE '\x01'::bytea || int8send(e."journal_id") END ||
+      COALESCE(prev, ''::bytea)
+    );
+
+    UPDATE "entries" SET "prev_hash" = prev, "hash" = h WHERE "id" = e."id";
+    prev := h;
+  END LOOP;
+END;
+$$;
+
+ALTER TABLE "entries" ALTER COLUMN "hash" SET NOT NULL;
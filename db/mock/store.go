@@ -8,8 +8,10 @@ import (
 	zigibankgo "BankAppGo/db/sqlc"
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
 )
 
 // MockStore is a mock of Store interface.
@@ -35,228 +37,1633 @@ func (m *MockStore) EXPECT() *MockStoreMockRecorder {
 	return m.recorder
 }
 
+// AccrueInterestTx mocks base method.
+func (m *MockStore) AccrueInterestTx(arg0 context.Context, arg1 zigibankgo.AccrueInterestTxParams) (zigibankgo.InterestAccrual, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AccrueInterestTx", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.InterestAccrual)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AccrueInterestTx indicates an expected call of AccrueInterestTx.
+func (mr *MockStoreMockRecorder) AccrueInterestTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AccrueInterestTx", reflect.TypeOf((*MockStore)(nil).AccrueInterestTx), arg0, arg1)
+}
+
 // AddAccountBalance mocks base method.
 func (m *MockStore) AddAccountBalance(arg0 context.Context, arg1 zigibankgo.AddAccountBalanceParams) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AddAccountBalance", arg0, arg1)
-	ret0, _ := ret[0].(int64)
+	ret := m.ctrl.Call(m, "AddAccountBalance", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddAccountBalance indicates an expected call of AddAccountBalance.
+func (mr *MockStoreMockRecorder) AddAccountBalance(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAccountBalance", reflect.TypeOf((*MockStore)(nil).AddAccountBalance), arg0, arg1)
+}
+
+// AddAccountHeldAmount mocks base method.
+func (m *MockStore) AddAccountHeldAmount(arg0 context.Context, arg1 zigibankgo.AddAccountHeldAmountParams) (zigibankgo.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddAccountHeldAmount", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddAccountHeldAmount indicates an expected call of AddAccountHeldAmount.
+func (mr *MockStoreMockRecorder) AddAccountHeldAmount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAccountHeldAmount", reflect.TypeOf((*MockStore)(nil).AddAccountHeldAmount), arg0, arg1)
+}
+
+// AddTransferReversedAmount mocks base method.
+func (m *MockStore) AddTransferReversedAmount(arg0 context.Context, arg1 zigibankgo.AddTransferReversedAmountParams) (zigibankgo.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddTransferReversedAmount", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddTransferReversedAmount indicates an expected call of AddTransferReversedAmount.
+func (mr *MockStoreMockRecorder) AddTransferReversedAmount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddTransferReversedAmount", reflect.TypeOf((*MockStore)(nil).AddTransferReversedAmount), arg0, arg1)
+}
+
+// AdjustBalanceTx mocks base method.
+func (m *MockStore) AdjustBalanceTx(arg0 context.Context, arg1 zigibankgo.AdjustBalanceTxParams) (zigibankgo.AdjustBalanceTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdjustBalanceTx", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.AdjustBalanceTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AdjustBalanceTx indicates an expected call of AdjustBalanceTx.
+func (mr *MockStoreMockRecorder) AdjustBalanceTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdjustBalanceTx", reflect.TypeOf((*MockStore)(nil).AdjustBalanceTx), arg0, arg1)
+}
+
+// AdvanceScheduledTransfer mocks base method.
+func (m *MockStore) AdvanceScheduledTransfer(arg0 context.Context, arg1 zigibankgo.AdvanceScheduledTransferParams) (zigibankgo.ScheduledTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdvanceScheduledTransfer", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.ScheduledTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AdvanceScheduledTransfer indicates an expected call of AdvanceScheduledTransfer.
+func (mr *MockStoreMockRecorder) AdvanceScheduledTransfer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdvanceScheduledTransfer", reflect.TypeOf((*MockStore)(nil).AdvanceScheduledTransfer), arg0, arg1)
+}
+
+// ApproveTransferReversalTx mocks base method.
+func (m *MockStore) ApproveTransferReversalTx(arg0 context.Context, arg1 int64) (zigibankgo.TransferReversalTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApproveTransferReversalTx", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.TransferReversalTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ApproveTransferReversalTx indicates an expected call of ApproveTransferReversalTx.
+func (mr *MockStoreMockRecorder) ApproveTransferReversalTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApproveTransferReversalTx", reflect.TypeOf((*MockStore)(nil).ApproveTransferReversalTx), arg0, arg1)
+}
+
+// AuthorizeTransferTx mocks base method.
+func (m *MockStore) AuthorizeTransferTx(arg0 context.Context, arg1 zigibankgo.AuthorizeTransferTxParams) (zigibankgo.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthorizeTransferTx", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuthorizeTransferTx indicates an expected call of AuthorizeTransferTx.
+func (mr *MockStoreMockRecorder) AuthorizeTransferTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthorizeTransferTx", reflect.TypeOf((*MockStore)(nil).AuthorizeTransferTx), arg0, arg1)
+}
+
+// BlockSession mocks base method.
+func (m *MockStore) BlockSession(arg0 context.Context, arg1 zigibankgo.BlockSessionParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlockSession", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BlockSession indicates an expected call of BlockSession.
+func (mr *MockStoreMockRecorder) BlockSession(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockSession", reflect.TypeOf((*MockStore)(nil).BlockSession), arg0, arg1)
+}
+
+// BlockUserSessions mocks base method.
+func (m *MockStore) BlockUserSessions(arg0 context.Context, arg1 string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlockUserSessions", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BlockUserSessions indicates an expected call of BlockUserSessions.
+func (mr *MockStoreMockRecorder) BlockUserSessions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockUserSessions", reflect.TypeOf((*MockStore)(nil).BlockUserSessions), arg0, arg1)
+}
+
+// CapitalizeInterestAccruals mocks base method.
+func (m *MockStore) CapitalizeInterestAccruals(arg0 context.Context, arg1 zigibankgo.CapitalizeInterestAccrualsParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CapitalizeInterestAccruals", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CapitalizeInterestAccruals indicates an expected call of CapitalizeInterestAccruals.
+func (mr *MockStoreMockRecorder) CapitalizeInterestAccruals(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CapitalizeInterestAccruals", reflect.TypeOf((*MockStore)(nil).CapitalizeInterestAccruals), arg0, arg1)
+}
+
+// CapitalizeInterestTx mocks base method.
+func (m *MockStore) CapitalizeInterestTx(arg0 context.Context, arg1 zigibankgo.CapitalizeInterestTxParams) (zigibankgo.CapitalizeInterestTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CapitalizeInterestTx", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.CapitalizeInterestTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CapitalizeInterestTx indicates an expected call of CapitalizeInterestTx.
+func (mr *MockStoreMockRecorder) CapitalizeInterestTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CapitalizeInterestTx", reflect.TypeOf((*MockStore)(nil).CapitalizeInterestTx), arg0, arg1)
+}
+
+// CaptureHoldTx mocks base method.
+func (m *MockStore) CaptureHoldTx(arg0 context.Context, arg1 zigibankgo.CaptureHoldTxParams) (zigibankgo.CaptureHoldTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CaptureHoldTx", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.CaptureHoldTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CaptureHoldTx indicates an expected call of CaptureHoldTx.
+func (mr *MockStoreMockRecorder) CaptureHoldTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CaptureHoldTx", reflect.TypeOf((*MockStore)(nil).CaptureHoldTx), arg0, arg1)
+}
+
+// ClaimDueScheduledTransfer mocks base method.
+func (m *MockStore) ClaimDueScheduledTransfer(arg0 context.Context, arg1 time.Time) (zigibankgo.ScheduledTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimDueScheduledTransfer", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.ScheduledTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimDueScheduledTransfer indicates an expected call of ClaimDueScheduledTransfer.
+func (mr *MockStoreMockRecorder) ClaimDueScheduledTransfer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimDueScheduledTransfer", reflect.TypeOf((*MockStore)(nil).ClaimDueScheduledTransfer), arg0, arg1)
+}
+
+// ClaimExpiredHold mocks base method.
+func (m *MockStore) ClaimExpiredHold(arg0 context.Context, arg1 time.Time) (zigibankgo.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimExpiredHold", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimExpiredHold indicates an expected call of ClaimExpiredHold.
+func (mr *MockStoreMockRecorder) ClaimExpiredHold(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimExpiredHold", reflect.TypeOf((*MockStore)(nil).ClaimExpiredHold), arg0, arg1)
+}
+
+// CloseAccountTx mocks base method.
+func (m *MockStore) CloseAccountTx(arg0 context.Context, arg1 int64) (zigibankgo.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseAccountTx", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CloseAccountTx indicates an expected call of CloseAccountTx.
+func (mr *MockStoreMockRecorder) CloseAccountTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseAccountTx", reflect.TypeOf((*MockStore)(nil).CloseAccountTx), arg0, arg1)
+}
+
+// CompleteTransferReversal mocks base method.
+func (m *MockStore) CompleteTransferReversal(arg0 context.Context, arg1 zigibankgo.CompleteTransferReversalParams) (zigibankgo.TransferReversal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteTransferReversal", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.TransferReversal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CompleteTransferReversal indicates an expected call of CompleteTransferReversal.
+func (mr *MockStoreMockRecorder) CompleteTransferReversal(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteTransferReversal", reflect.TypeOf((*MockStore)(nil).CompleteTransferReversal), arg0, arg1)
+}
+
+// CreateAccount mocks base method.
+func (m *MockStore) CreateAccount(arg0 context.Context, arg1 zigibankgo.CreateAccountParams) (zigibankgo.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAccount", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAccount indicates an expected call of CreateAccount.
+func (mr *MockStoreMockRecorder) CreateAccount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccount", reflect.TypeOf((*MockStore)(nil).CreateAccount), arg0, arg1)
+}
+
+// CreateAccountTx mocks base method.
+func (m *MockStore) CreateAccountTx(arg0 context.Context, arg1 zigibankgo.CreateAccountTxParams) (zigibankgo.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAccountTx", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAccountTx indicates an expected call of CreateAccountTx.
+func (mr *MockStoreMockRecorder) CreateAccountTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccountTx", reflect.TypeOf((*MockStore)(nil).CreateAccountTx), arg0, arg1)
+}
+
+// CreateAuditEvent mocks base method.
+func (m *MockStore) CreateAuditEvent(arg0 context.Context, arg1 zigibankgo.CreateAuditEventParams) (zigibankgo.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAuditEvent", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAuditEvent indicates an expected call of CreateAuditEvent.
+func (mr *MockStoreMockRecorder) CreateAuditEvent(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAuditEvent", reflect.TypeOf((*MockStore)(nil).CreateAuditEvent), arg0, arg1)
+}
+
+// CreateBalanceAdjustment mocks base method.
+func (m *MockStore) CreateBalanceAdjustment(arg0 context.Context, arg1 zigibankgo.CreateBalanceAdjustmentParams) (zigibankgo.BalanceAdjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBalanceAdjustment", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.BalanceAdjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateBalanceAdjustment indicates an expected call of CreateBalanceAdjustment.
+func (mr *MockStoreMockRecorder) CreateBalanceAdjustment(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBalanceAdjustment", reflect.TypeOf((*MockStore)(nil).CreateBalanceAdjustment), arg0, arg1)
+}
+
+// CreateCashMovement mocks base method.
+func (m *MockStore) CreateCashMovement(arg0 context.Context, arg1 zigibankgo.CreateCashMovementParams) (zigibankgo.CashMovement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCashMovement", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.CashMovement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCashMovement indicates an expected call of CreateCashMovement.
+func (mr *MockStoreMockRecorder) CreateCashMovement(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCashMovement", reflect.TypeOf((*MockStore)(nil).CreateCashMovement), arg0, arg1)
+}
+
+// CreateEntry mocks base method.
+func (m *MockStore) CreateEntry(arg0 context.Context, arg1 zigibankgo.CreateEntryParams) (zigibankgo.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateEntry", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateEntry indicates an expected call of CreateEntry.
+func (mr *MockStoreMockRecorder) CreateEntry(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEntry", reflect.TypeOf((*MockStore)(nil).CreateEntry), arg0, arg1)
+}
+
+// CreateFeeSchedule mocks base method.
+func (m *MockStore) CreateFeeSchedule(arg0 context.Context, arg1 zigibankgo.CreateFeeScheduleParams) (zigibankgo.FeeSchedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateFeeSchedule", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.FeeSchedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateFeeSchedule indicates an expected call of CreateFeeSchedule.
+func (mr *MockStoreMockRecorder) CreateFeeSchedule(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFeeSchedule", reflect.TypeOf((*MockStore)(nil).CreateFeeSchedule), arg0, arg1)
+}
+
+// CreateFeeScheduleTx mocks base method.
+func (m *MockStore) CreateFeeScheduleTx(arg0 context.Context, arg1 zigibankgo.CreateFeeScheduleParams) (zigibankgo.FeeSchedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateFeeScheduleTx", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.FeeSchedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateFeeScheduleTx indicates an expected call of CreateFeeScheduleTx.
+func (mr *MockStoreMockRecorder) CreateFeeScheduleTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFeeScheduleTx", reflect.TypeOf((*MockStore)(nil).CreateFeeScheduleTx), arg0, arg1)
+}
+
+// CreateHold mocks base method.
+func (m *MockStore) CreateHold(arg0 context.Context, arg1 zigibankgo.CreateHoldParams) (zigibankgo.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateHold", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateHold indicates an expected call of CreateHold.
+func (mr *MockStoreMockRecorder) CreateHold(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateHold", reflect.TypeOf((*MockStore)(nil).CreateHold), arg0, arg1)
+}
+
+// CreateIdempotencyKey mocks base method.
+func (m *MockStore) CreateIdempotencyKey(arg0 context.Context, arg1 zigibankgo.CreateIdempotencyKeyParams) (zigibankgo.IdempotencyKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateIdempotencyKey", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.IdempotencyKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateIdempotencyKey indicates an expected call of CreateIdempotencyKey.
+func (mr *MockStoreMockRecorder) CreateIdempotencyKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateIdempotencyKey", reflect.TypeOf((*MockStore)(nil).CreateIdempotencyKey), arg0, arg1)
+}
+
+// CreateInterestAccrual mocks base method.
+func (m *MockStore) CreateInterestAccrual(arg0 context.Context, arg1 zigibankgo.CreateInterestAccrualParams) (zigibankgo.InterestAccrual, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateInterestAccrual", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.InterestAccrual)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateInterestAccrual indicates an expected call of CreateInterestAccrual.
+func (mr *MockStoreMockRecorder) CreateInterestAccrual(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateInterestAccrual", reflect.TypeOf((*MockStore)(nil).CreateInterestAccrual), arg0, arg1)
+}
+
+// CreateJournal mocks base method.
+func (m *MockStore) CreateJournal(arg0 context.Context, arg1 string) (zigibankgo.Journal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateJournal", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.Journal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateJournal indicates an expected call of CreateJournal.
+func (mr *MockStoreMockRecorder) CreateJournal(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateJournal", reflect.TypeOf((*MockStore)(nil).CreateJournal), arg0, arg1)
+}
+
+// CreateReconciliationReport mocks base method.
+func (m *MockStore) CreateReconciliationReport(arg0 context.Context, arg1 zigibankgo.CreateReconciliationReportParams) (zigibankgo.ReconciliationReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateReconciliationReport", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.ReconciliationReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateReconciliationReport indicates an expected call of CreateReconciliationReport.
+func (mr *MockStoreMockRecorder) CreateReconciliationReport(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateReconciliationReport", reflect.TypeOf((*MockStore)(nil).CreateReconciliationReport), arg0, arg1)
+}
+
+// CreateScheduledTransfer mocks base method.
+func (m *MockStore) CreateScheduledTransfer(arg0 context.Context, arg1 zigibankgo.CreateScheduledTransferParams) (zigibankgo.ScheduledTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateScheduledTransfer", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.ScheduledTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateScheduledTransfer indicates an expected call of CreateScheduledTransfer.
+func (mr *MockStoreMockRecorder) CreateScheduledTransfer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateScheduledTransfer", reflect.TypeOf((*MockStore)(nil).CreateScheduledTransfer), arg0, arg1)
+}
+
+// CreateScheduledTransferRun mocks base method.
+func (m *MockStore) CreateScheduledTransferRun(arg0 context.Context, arg1 zigibankgo.CreateScheduledTransferRunParams) (zigibankgo.ScheduledTransferRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateScheduledTransferRun", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.ScheduledTransferRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateScheduledTransferRun indicates an expected call of CreateScheduledTransferRun.
+func (mr *MockStoreMockRecorder) CreateScheduledTransferRun(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateScheduledTransferRun", reflect.TypeOf((*MockStore)(nil).CreateScheduledTransferRun), arg0, arg1)
+}
+
+// CreateScheduledTransferTx mocks base method.
+func (m *MockStore) CreateScheduledTransferTx(arg0 context.Context, arg1 zigibankgo.CreateScheduledTransferParams) (zigibankgo.ScheduledTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateScheduledTransferTx", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.ScheduledTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateScheduledTransferTx indicates an expected call of CreateScheduledTransferTx.
+func (mr *MockStoreMockRecorder) CreateScheduledTransferTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateScheduledTransferTx", reflect.TypeOf((*MockStore)(nil).CreateScheduledTransferTx), arg0, arg1)
+}
+
+// CreateSession mocks base method.
+func (m *MockStore) CreateSession(arg0 context.Context, arg1 zigibankgo.CreateSessionParams) (zigibankgo.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSession", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSession indicates an expected call of CreateSession.
+func (mr *MockStoreMockRecorder) CreateSession(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSession", reflect.TypeOf((*MockStore)(nil).CreateSession), arg0, arg1)
+}
+
+// CreateSessionTx mocks base method.
+func (m *MockStore) CreateSessionTx(arg0 context.Context, arg1 zigibankgo.CreateSessionParams) (zigibankgo.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSessionTx", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSessionTx indicates an expected call of CreateSessionTx.
+func (mr *MockStoreMockRecorder) CreateSessionTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSessionTx", reflect.TypeOf((*MockStore)(nil).CreateSessionTx), arg0, arg1)
+}
+
+// CreateSystemAccount mocks base method.
+func (m *MockStore) CreateSystemAccount(arg0 context.Context, arg1 zigibankgo.CreateSystemAccountParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSystemAccount", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateSystemAccount indicates an expected call of CreateSystemAccount.
+func (mr *MockStoreMockRecorder) CreateSystemAccount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSystemAccount", reflect.TypeOf((*MockStore)(nil).CreateSystemAccount), arg0, arg1)
+}
+
+// CreateTokenRevocation mocks base method.
+func (m *MockStore) CreateTokenRevocation(arg0 context.Context, arg1 zigibankgo.CreateTokenRevocationParams) (zigibankgo.TokenRevocation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTokenRevocation", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.TokenRevocation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTokenRevocation indicates an expected call of CreateTokenRevocation.
+func (mr *MockStoreMockRecorder) CreateTokenRevocation(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTokenRevocation", reflect.TypeOf((*MockStore)(nil).CreateTokenRevocation), arg0, arg1)
+}
+
+// CreateTransfer mocks base method.
+func (m *MockStore) CreateTransfer(arg0 context.Context, arg1 zigibankgo.CreateTransferParams) (zigibankgo.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTransfer", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTransfer indicates an expected call of CreateTransfer.
+func (mr *MockStoreMockRecorder) CreateTransfer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransfer", reflect.TypeOf((*MockStore)(nil).CreateTransfer), arg0, arg1)
+}
+
+// CreateTransferReversal mocks base method.
+func (m *MockStore) CreateTransferReversal(arg0 context.Context, arg1 zigibankgo.CreateTransferReversalParams) (zigibankgo.TransferReversal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTransferReversal", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.TransferReversal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTransferReversal indicates an expected call of CreateTransferReversal.
+func (mr *MockStoreMockRecorder) CreateTransferReversal(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransferReversal", reflect.TypeOf((*MockStore)(nil).CreateTransferReversal), arg0, arg1)
+}
+
+// CreateUser mocks base method.
+func (m *MockStore) CreateUser(arg0 context.Context, arg1 zigibankgo.CreateUserParams) (zigibankgo.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUser", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUser indicates an expected call of CreateUser.
+func (mr *MockStoreMockRecorder) CreateUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockStore)(nil).CreateUser), arg0, arg1)
+}
+
+// CreateUserTx mocks base method.
+func (m *MockStore) CreateUserTx(arg0 context.Context, arg1 zigibankgo.CreateUserParams) (zigibankgo.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUserTx", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUserTx indicates an expected call of CreateUserTx.
+func (mr *MockStoreMockRecorder) CreateUserTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUserTx", reflect.TypeOf((*MockStore)(nil).CreateUserTx), arg0, arg1)
+}
+
+// DeleteAccount mocks base method.
+func (m *MockStore) DeleteAccount(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAccount", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAccount indicates an expected call of DeleteAccount.
+func (mr *MockStoreMockRecorder) DeleteAccount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccount", reflect.TypeOf((*MockStore)(nil).DeleteAccount), arg0, arg1)
+}
+
+// DeleteExpiredTokenRevocations mocks base method.
+func (m *MockStore) DeleteExpiredTokenRevocations(arg0 context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredTokenRevocations", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpiredTokenRevocations indicates an expected call of DeleteExpiredTokenRevocations.
+func (mr *MockStoreMockRecorder) DeleteExpiredTokenRevocations(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredTokenRevocations", reflect.TypeOf((*MockStore)(nil).DeleteExpiredTokenRevocations), arg0)
+}
+
+// DeleteTransferLimit mocks base method.
+func (m *MockStore) DeleteTransferLimit(arg0 context.Context, arg1 zigibankgo.DeleteTransferLimitParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTransferLimit", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTransferLimit indicates an expected call of DeleteTransferLimit.
+func (mr *MockStoreMockRecorder) DeleteTransferLimit(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTransferLimit", reflect.TypeOf((*MockStore)(nil).DeleteTransferLimit), arg0, arg1)
+}
+
+// DeleteTransferLimitTx mocks base method.
+func (m *MockStore) DeleteTransferLimitTx(arg0 context.Context, arg1 zigibankgo.DeleteTransferLimitParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTransferLimitTx", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTransferLimitTx indicates an expected call of DeleteTransferLimitTx.
+func (mr *MockStoreMockRecorder) DeleteTransferLimitTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTransferLimitTx", reflect.TypeOf((*MockStore)(nil).DeleteTransferLimitTx), arg0, arg1)
+}
+
+// DepositTx mocks base method.
+func (m *MockStore) DepositTx(arg0 context.Context, arg1 zigibankgo.CashTxParams) (zigibankgo.CashTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DepositTx", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.CashTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DepositTx indicates an expected call of DepositTx.
+func (mr *MockStoreMockRecorder) DepositTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DepositTx", reflect.TypeOf((*MockStore)(nil).DepositTx), arg0, arg1)
+}
+
+// ExchangeTransferTx mocks base method.
+func (m *MockStore) ExchangeTransferTx(arg0 context.Context, arg1 zigibankgo.ExchangeTransferTxParams) (zigibankgo.TransferTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExchangeTransferTx", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.TransferTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExchangeTransferTx indicates an expected call of ExchangeTransferTx.
+func (mr *MockStoreMockRecorder) ExchangeTransferTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExchangeTransferTx", reflect.TypeOf((*MockStore)(nil).ExchangeTransferTx), arg0, arg1)
+}
+
+// ExpireHoldTx mocks base method.
+func (m *MockStore) ExpireHoldTx(arg0 context.Context, arg1 time.Time) (zigibankgo.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpireHoldTx", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExpireHoldTx indicates an expected call of ExpireHoldTx.
+func (mr *MockStoreMockRecorder) ExpireHoldTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpireHoldTx", reflect.TypeOf((*MockStore)(nil).ExpireHoldTx), arg0, arg1)
+}
+
+// FilterTransfers mocks base method.
+func (m *MockStore) FilterTransfers(arg0 context.Context, arg1 zigibankgo.FilterTransfersParams) ([]zigibankgo.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FilterTransfers", arg0, arg1)
+	ret0, _ := ret[0].([]zigibankgo.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FilterTransfers indicates an expected call of FilterTransfers.
+func (mr *MockStoreMockRecorder) FilterTransfers(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FilterTransfers", reflect.TypeOf((*MockStore)(nil).FilterTransfers), arg0, arg1)
+}
+
+// GetAccount mocks base method.
+func (m *MockStore) GetAccount(arg0 context.Context, arg1 int64) (zigibankgo.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccount", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccount indicates an expected call of GetAccount.
+func (mr *MockStoreMockRecorder) GetAccount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccount", reflect.TypeOf((*MockStore)(nil).GetAccount), arg0, arg1)
+}
+
+// GetAccountForUpdate mocks base method.
+func (m *MockStore) GetAccountForUpdate(arg0 context.Context, arg1 int64) (zigibankgo.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountForUpdate indicates an expected call of GetAccountForUpdate.
+func (mr *MockStoreMockRecorder) GetAccountForUpdate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountForUpdate", reflect.TypeOf((*MockStore)(nil).GetAccountForUpdate), arg0, arg1)
+}
+
+// GetCashMovementByReference mocks base method.
+func (m *MockStore) GetCashMovementByReference(arg0 context.Context, arg1 zigibankgo.GetCashMovementByReferenceParams) (zigibankgo.CashMovement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCashMovementByReference", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.CashMovement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCashMovementByReference indicates an expected call of GetCashMovementByReference.
+func (mr *MockStoreMockRecorder) GetCashMovementByReference(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCashMovementByReference", reflect.TypeOf((*MockStore)(nil).GetCashMovementByReference), arg0, arg1)
+}
+
+// GetEntry mocks base method.
+func (m *MockStore) GetEntry(arg0 context.Context, arg1 int64) (zigibankgo.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEntry", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEntry indicates an expected call of GetEntry.
+func (mr *MockStoreMockRecorder) GetEntry(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEntry", reflect.TypeOf((*MockStore)(nil).GetEntry), arg0, arg1)
+}
+
+// GetEntryFromId mocks base method.
+func (m *MockStore) GetEntryFromId(arg0 context.Context, arg1 int64) (zigibankgo.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEntryFromId", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEntryFromId indicates an expected call of GetEntryFromId.
+func (mr *MockStoreMockRecorder) GetEntryFromId(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEntryFromId", reflect.TypeOf((*MockStore)(nil).GetEntryFromId), arg0, arg1)
+}
+
+// GetFeeSchedule mocks base method.
+func (m *MockStore) GetFeeSchedule(arg0 context.Context, arg1 string) (zigibankgo.FeeSchedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFeeSchedule", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.FeeSchedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFeeSchedule indicates an expected call of GetFeeSchedule.
+func (mr *MockStoreMockRecorder) GetFeeSchedule(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFeeSchedule", reflect.TypeOf((*MockStore)(nil).GetFeeSchedule), arg0, arg1)
+}
+
+// GetHeldAmountTotal mocks base method.
+func (m *MockStore) GetHeldAmountTotal(arg0 context.Context, arg1 zigibankgo.GetHeldAmountTotalParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHeldAmountTotal", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHeldAmountTotal indicates an expected call of GetHeldAmountTotal.
+func (mr *MockStoreMockRecorder) GetHeldAmountTotal(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHeldAmountTotal", reflect.TypeOf((*MockStore)(nil).GetHeldAmountTotal), arg0, arg1)
+}
+
+// GetHold mocks base method.
+func (m *MockStore) GetHold(arg0 context.Context, arg1 int64) (zigibankgo.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHold", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHold indicates an expected call of GetHold.
+func (mr *MockStoreMockRecorder) GetHold(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHold", reflect.TypeOf((*MockStore)(nil).GetHold), arg0, arg1)
+}
+
+// GetHoldForUpdate mocks base method.
+func (m *MockStore) GetHoldForUpdate(arg0 context.Context, arg1 int64) (zigibankgo.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHoldForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHoldForUpdate indicates an expected call of GetHoldForUpdate.
+func (mr *MockStoreMockRecorder) GetHoldForUpdate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHoldForUpdate", reflect.TypeOf((*MockStore)(nil).GetHoldForUpdate), arg0, arg1)
+}
+
+// GetIdempotencyKey mocks base method.
+func (m *MockStore) GetIdempotencyKey(arg0 context.Context, arg1 zigibankgo.GetIdempotencyKeyParams) (zigibankgo.IdempotencyKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIdempotencyKey", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.IdempotencyKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIdempotencyKey indicates an expected call of GetIdempotencyKey.
+func (mr *MockStoreMockRecorder) GetIdempotencyKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIdempotencyKey", reflect.TypeOf((*MockStore)(nil).GetIdempotencyKey), arg0, arg1)
+}
+
+// GetInterestAccrual mocks base method.
+func (m *MockStore) GetInterestAccrual(arg0 context.Context, arg1 zigibankgo.GetInterestAccrualParams) (zigibankgo.InterestAccrual, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInterestAccrual", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.InterestAccrual)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInterestAccrual indicates an expected call of GetInterestAccrual.
+func (mr *MockStoreMockRecorder) GetInterestAccrual(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInterestAccrual", reflect.TypeOf((*MockStore)(nil).GetInterestAccrual), arg0, arg1)
+}
+
+// GetInterestRate mocks base method.
+func (m *MockStore) GetInterestRate(arg0 context.Context, arg1 zigibankgo.GetInterestRateParams) (zigibankgo.InterestRate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInterestRate", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.InterestRate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInterestRate indicates an expected call of GetInterestRate.
+func (mr *MockStoreMockRecorder) GetInterestRate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInterestRate", reflect.TypeOf((*MockStore)(nil).GetInterestRate), arg0, arg1)
+}
+
+// GetLastAccountEntry mocks base method.
+func (m *MockStore) GetLastAccountEntry(arg0 context.Context, arg1 int64) (zigibankgo.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLastAccountEntry", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLastAccountEntry indicates an expected call of GetLastAccountEntry.
+func (mr *MockStoreMockRecorder) GetLastAccountEntry(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastAccountEntry", reflect.TypeOf((*MockStore)(nil).GetLastAccountEntry), arg0, arg1)
+}
+
+// GetOutboundTransferTotal mocks base method.
+func (m *MockStore) GetOutboundTransferTotal(arg0 context.Context, arg1 zigibankgo.GetOutboundTransferTotalParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOutboundTransferTotal", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOutboundTransferTotal indicates an expected call of GetOutboundTransferTotal.
+func (mr *MockStoreMockRecorder) GetOutboundTransferTotal(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOutboundTransferTotal", reflect.TypeOf((*MockStore)(nil).GetOutboundTransferTotal), arg0, arg1)
+}
+
+// GetPendingTransferReversalForUpdate mocks base method.
+func (m *MockStore) GetPendingTransferReversalForUpdate(arg0 context.Context, arg1 int64) (zigibankgo.TransferReversal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPendingTransferReversalForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.TransferReversal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPendingTransferReversalForUpdate indicates an expected call of GetPendingTransferReversalForUpdate.
+func (mr *MockStoreMockRecorder) GetPendingTransferReversalForUpdate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPendingTransferReversalForUpdate", reflect.TypeOf((*MockStore)(nil).GetPendingTransferReversalForUpdate), arg0, arg1)
+}
+
+// GetScheduledTransfer mocks base method.
+func (m *MockStore) GetScheduledTransfer(arg0 context.Context, arg1 int64) (zigibankgo.ScheduledTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetScheduledTransfer", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.ScheduledTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetScheduledTransfer indicates an expected call of GetScheduledTransfer.
+func (mr *MockStoreMockRecorder) GetScheduledTransfer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetScheduledTransfer", reflect.TypeOf((*MockStore)(nil).GetScheduledTransfer), arg0, arg1)
+}
+
+// GetSession mocks base method.
+func (m *MockStore) GetSession(arg0 context.Context, arg1 uuid.UUID) (zigibankgo.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSession", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSession indicates an expected call of GetSession.
+func (mr *MockStoreMockRecorder) GetSession(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSession", reflect.TypeOf((*MockStore)(nil).GetSession), arg0, arg1)
+}
+
+// GetSystemAccount mocks base method.
+func (m *MockStore) GetSystemAccount(arg0 context.Context, arg1 zigibankgo.GetSystemAccountParams) (zigibankgo.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSystemAccount", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSystemAccount indicates an expected call of GetSystemAccount.
+func (mr *MockStoreMockRecorder) GetSystemAccount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSystemAccount", reflect.TypeOf((*MockStore)(nil).GetSystemAccount), arg0, arg1)
+}
+
+// GetTransfer mocks base method.
+func (m *MockStore) GetTransfer(arg0 context.Context, arg1 zigibankgo.GetTransferParams) (zigibankgo.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransfer", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransfer indicates an expected call of GetTransfer.
+func (mr *MockStoreMockRecorder) GetTransfer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransfer", reflect.TypeOf((*MockStore)(nil).GetTransfer), arg0, arg1)
+}
+
+// GetTransferForUpdate mocks base method.
+func (m *MockStore) GetTransferForUpdate(arg0 context.Context, arg1 int64) (zigibankgo.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransferForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransferForUpdate indicates an expected call of GetTransferForUpdate.
+func (mr *MockStoreMockRecorder) GetTransferForUpdate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransferForUpdate", reflect.TypeOf((*MockStore)(nil).GetTransferForUpdate), arg0, arg1)
+}
+
+// GetTransferFromId mocks base method.
+func (m *MockStore) GetTransferFromId(arg0 context.Context, arg1 int64) (zigibankgo.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransferFromId", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransferFromId indicates an expected call of GetTransferFromId.
+func (mr *MockStoreMockRecorder) GetTransferFromId(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransferFromId", reflect.TypeOf((*MockStore)(nil).GetTransferFromId), arg0, arg1)
+}
+
+// GetTransferLimit mocks base method.
+func (m *MockStore) GetTransferLimit(arg0 context.Context, arg1 zigibankgo.GetTransferLimitParams) (zigibankgo.TransferLimit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransferLimit", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.TransferLimit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransferLimit indicates an expected call of GetTransferLimit.
+func (mr *MockStoreMockRecorder) GetTransferLimit(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransferLimit", reflect.TypeOf((*MockStore)(nil).GetTransferLimit), arg0, arg1)
+}
+
+// GetUser mocks base method.
+func (m *MockStore) GetUser(arg0 context.Context, arg1 string) (zigibankgo.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUser", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUser indicates an expected call of GetUser.
+func (mr *MockStoreMockRecorder) GetUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockStore)(nil).GetUser), arg0, arg1)
+}
+
+// IsSystemAccount mocks base method.
+func (m *MockStore) IsSystemAccount(arg0 context.Context, arg1 int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsSystemAccount", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsSystemAccount indicates an expected call of IsSystemAccount.
+func (mr *MockStoreMockRecorder) IsSystemAccount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsSystemAccount", reflect.TypeOf((*MockStore)(nil).IsSystemAccount), arg0, arg1)
+}
+
+// ListAccountEntries mocks base method.
+func (m *MockStore) ListAccountEntries(arg0 context.Context, arg1 zigibankgo.ListAccountEntriesParams) ([]zigibankgo.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccountEntries", arg0, arg1)
+	ret0, _ := ret[0].([]zigibankgo.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccountEntries indicates an expected call of ListAccountEntries.
+func (mr *MockStoreMockRecorder) ListAccountEntries(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccountEntries", reflect.TypeOf((*MockStore)(nil).ListAccountEntries), arg0, arg1)
+}
+
+// ListAccountEntriesTx mocks base method.
+func (m *MockStore) ListAccountEntriesTx(arg0 context.Context, arg1 zigibankgo.ListAccountEntriesTxParams) (zigibankgo.ListAccountEntriesTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccountEntriesTx", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.ListAccountEntriesTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccountEntriesTx indicates an expected call of ListAccountEntriesTx.
+func (mr *MockStoreMockRecorder) ListAccountEntriesTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccountEntriesTx", reflect.TypeOf((*MockStore)(nil).ListAccountEntriesTx), arg0, arg1)
+}
+
+// ListAccountEntryTotals mocks base method.
+func (m *MockStore) ListAccountEntryTotals(arg0 context.Context, arg1 zigibankgo.ListAccountEntryTotalsParams) ([]zigibankgo.ListAccountEntryTotalsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccountEntryTotals", arg0, arg1)
+	ret0, _ := ret[0].([]zigibankgo.ListAccountEntryTotalsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccountEntryTotals indicates an expected call of ListAccountEntryTotals.
+func (mr *MockStoreMockRecorder) ListAccountEntryTotals(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccountEntryTotals", reflect.TypeOf((*MockStore)(nil).ListAccountEntryTotals), arg0, arg1)
+}
+
+// ListAccountIDsByOwner mocks base method.
+func (m *MockStore) ListAccountIDsByOwner(arg0 context.Context, arg1 string) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccountIDsByOwner", arg0, arg1)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccountIDsByOwner indicates an expected call of ListAccountIDsByOwner.
+func (mr *MockStoreMockRecorder) ListAccountIDsByOwner(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccountIDsByOwner", reflect.TypeOf((*MockStore)(nil).ListAccountIDsByOwner), arg0, arg1)
+}
+
+// ListAccounts mocks base method.
+func (m *MockStore) ListAccounts(arg0 context.Context, arg1 zigibankgo.ListAccountsParams) ([]zigibankgo.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccounts", arg0, arg1)
+	ret0, _ := ret[0].([]zigibankgo.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccounts indicates an expected call of ListAccounts.
+func (mr *MockStoreMockRecorder) ListAccounts(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccounts", reflect.TypeOf((*MockStore)(nil).ListAccounts), arg0, arg1)
+}
+
+// ListAccountsWithUncapitalizedInterest mocks base method.
+func (m *MockStore) ListAccountsWithUncapitalizedInterest(arg0 context.Context, arg1 zigibankgo.ListAccountsWithUncapitalizedInterestParams) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccountsWithUncapitalizedInterest", arg0, arg1)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccountsWithUncapitalizedInterest indicates an expected call of ListAccountsWithUncapitalizedInterest.
+func (mr *MockStoreMockRecorder) ListAccountsWithUncapitalizedInterest(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccountsWithUncapitalizedInterest", reflect.TypeOf((*MockStore)(nil).ListAccountsWithUncapitalizedInterest), arg0, arg1)
+}
+
+// ListActiveTokenRevocations mocks base method.
+func (m *MockStore) ListActiveTokenRevocations(arg0 context.Context) ([]zigibankgo.TokenRevocation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListActiveTokenRevocations", arg0)
+	ret0, _ := ret[0].([]zigibankgo.TokenRevocation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListActiveTokenRevocations indicates an expected call of ListActiveTokenRevocations.
+func (mr *MockStoreMockRecorder) ListActiveTokenRevocations(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListActiveTokenRevocations", reflect.TypeOf((*MockStore)(nil).ListActiveTokenRevocations), arg0)
+}
+
+// ListAuditEvents mocks base method.
+func (m *MockStore) ListAuditEvents(arg0 context.Context, arg1 zigibankgo.ListAuditEventsParams) ([]zigibankgo.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAuditEvents", arg0, arg1)
+	ret0, _ := ret[0].([]zigibankgo.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAuditEvents indicates an expected call of ListAuditEvents.
+func (mr *MockStoreMockRecorder) ListAuditEvents(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAuditEvents", reflect.TypeOf((*MockStore)(nil).ListAuditEvents), arg0, arg1)
+}
+
+// ListBalanceAdjustments mocks base method.
+func (m *MockStore) ListBalanceAdjustments(arg0 context.Context, arg1 int64) ([]zigibankgo.BalanceAdjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBalanceAdjustments", arg0, arg1)
+	ret0, _ := ret[0].([]zigibankgo.BalanceAdjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBalanceAdjustments indicates an expected call of ListBalanceAdjustments.
+func (mr *MockStoreMockRecorder) ListBalanceAdjustments(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBalanceAdjustments", reflect.TypeOf((*MockStore)(nil).ListBalanceAdjustments), arg0, arg1)
+}
+
+// ListChainEntries mocks base method.
+func (m *MockStore) ListChainEntries(arg0 context.Context, arg1 zigibankgo.ListChainEntriesParams) ([]zigibankgo.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListChainEntries", arg0, arg1)
+	ret0, _ := ret[0].([]zigibankgo.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListChainEntries indicates an expected call of ListChainEntries.
+func (mr *MockStoreMockRecorder) ListChainEntries(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListChainEntries", reflect.TypeOf((*MockStore)(nil).ListChainEntries), arg0, arg1)
+}
+
+// ListCurrencyTotals mocks base method.
+func (m *MockStore) ListCurrencyTotals(arg0 context.Context) ([]zigibankgo.ListCurrencyTotalsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCurrencyTotals", arg0)
+	ret0, _ := ret[0].([]zigibankgo.ListCurrencyTotalsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCurrencyTotals indicates an expected call of ListCurrencyTotals.
+func (mr *MockStoreMockRecorder) ListCurrencyTotals(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCurrencyTotals", reflect.TypeOf((*MockStore)(nil).ListCurrencyTotals), arg0)
+}
+
+// ListEntries mocks base method.
+func (m *MockStore) ListEntries(arg0 context.Context) ([]zigibankgo.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListEntries", arg0)
+	ret0, _ := ret[0].([]zigibankgo.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListEntries indicates an expected call of ListEntries.
+func (mr *MockStoreMockRecorder) ListEntries(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEntries", reflect.TypeOf((*MockStore)(nil).ListEntries), arg0)
+}
+
+// ListInterestBearingAccounts mocks base method.
+func (m *MockStore) ListInterestBearingAccounts(arg0 context.Context, arg1 zigibankgo.ListInterestBearingAccountsParams) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListInterestBearingAccounts", arg0, arg1)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListInterestBearingAccounts indicates an expected call of ListInterestBearingAccounts.
+func (mr *MockStoreMockRecorder) ListInterestBearingAccounts(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListInterestBearingAccounts", reflect.TypeOf((*MockStore)(nil).ListInterestBearingAccounts), arg0, arg1)
+}
+
+// ListOpenScheduledTransfersByAccount mocks base method.
+func (m *MockStore) ListOpenScheduledTransfersByAccount(arg0 context.Context, arg1 int64) ([]zigibankgo.ScheduledTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOpenScheduledTransfersByAccount", arg0, arg1)
+	ret0, _ := ret[0].([]zigibankgo.ScheduledTransfer)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// AddAccountBalance indicates an expected call of AddAccountBalance.
-func (mr *MockStoreMockRecorder) AddAccountBalance(arg0, arg1 interface{}) *gomock.Call {
+// ListOpenScheduledTransfersByAccount indicates an expected call of ListOpenScheduledTransfersByAccount.
+func (mr *MockStoreMockRecorder) ListOpenScheduledTransfersByAccount(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAccountBalance", reflect.TypeOf((*MockStore)(nil).AddAccountBalance), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOpenScheduledTransfersByAccount", reflect.TypeOf((*MockStore)(nil).ListOpenScheduledTransfersByAccount), arg0, arg1)
 }
 
-// CreateAccount mocks base method.
-func (m *MockStore) CreateAccount(arg0 context.Context, arg1 zigibankgo.CreateAccountParams) (zigibankgo.Account, error) {
+// ListReconciliationReports mocks base method.
+func (m *MockStore) ListReconciliationReports(arg0 context.Context, arg1 zigibankgo.ListReconciliationReportsParams) ([]zigibankgo.ReconciliationReport, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateAccount", arg0, arg1)
-	ret0, _ := ret[0].(zigibankgo.Account)
+	ret := m.ctrl.Call(m, "ListReconciliationReports", arg0, arg1)
+	ret0, _ := ret[0].([]zigibankgo.ReconciliationReport)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateAccount indicates an expected call of CreateAccount.
-func (mr *MockStoreMockRecorder) CreateAccount(arg0, arg1 interface{}) *gomock.Call {
+// ListReconciliationReports indicates an expected call of ListReconciliationReports.
+func (mr *MockStoreMockRecorder) ListReconciliationReports(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccount", reflect.TypeOf((*MockStore)(nil).CreateAccount), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListReconciliationReports", reflect.TypeOf((*MockStore)(nil).ListReconciliationReports), arg0, arg1)
 }
 
-// CreateEntry mocks base method.
-func (m *MockStore) CreateEntry(arg0 context.Context, arg1 zigibankgo.CreateEntryParams) (zigibankgo.Entry, error) {
+// ListScheduledTransferRuns mocks base method.
+func (m *MockStore) ListScheduledTransferRuns(arg0 context.Context, arg1 zigibankgo.ListScheduledTransferRunsParams) ([]zigibankgo.ScheduledTransferRun, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateEntry", arg0, arg1)
-	ret0, _ := ret[0].(zigibankgo.Entry)
+	ret := m.ctrl.Call(m, "ListScheduledTransferRuns", arg0, arg1)
+	ret0, _ := ret[0].([]zigibankgo.ScheduledTransferRun)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateEntry indicates an expected call of CreateEntry.
-func (mr *MockStoreMockRecorder) CreateEntry(arg0, arg1 interface{}) *gomock.Call {
+// ListScheduledTransferRuns indicates an expected call of ListScheduledTransferRuns.
+func (mr *MockStoreMockRecorder) ListScheduledTransferRuns(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEntry", reflect.TypeOf((*MockStore)(nil).CreateEntry), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListScheduledTransferRuns", reflect.TypeOf((*MockStore)(nil).ListScheduledTransferRuns), arg0, arg1)
 }
 
-// CreateTransfer mocks base method.
-func (m *MockStore) CreateTransfer(arg0 context.Context, arg1 zigibankgo.CreateTransferParams) (zigibankgo.Transfer, error) {
+// ListScheduledTransfers mocks base method.
+func (m *MockStore) ListScheduledTransfers(arg0 context.Context, arg1 zigibankgo.ListScheduledTransfersParams) ([]zigibankgo.ScheduledTransfer, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateTransfer", arg0, arg1)
-	ret0, _ := ret[0].(zigibankgo.Transfer)
+	ret := m.ctrl.Call(m, "ListScheduledTransfers", arg0, arg1)
+	ret0, _ := ret[0].([]zigibankgo.ScheduledTransfer)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateTransfer indicates an expected call of CreateTransfer.
-func (mr *MockStoreMockRecorder) CreateTransfer(arg0, arg1 interface{}) *gomock.Call {
+// ListScheduledTransfers indicates an expected call of ListScheduledTransfers.
+func (mr *MockStoreMockRecorder) ListScheduledTransfers(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransfer", reflect.TypeOf((*MockStore)(nil).CreateTransfer), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListScheduledTransfers", reflect.TypeOf((*MockStore)(nil).ListScheduledTransfers), arg0, arg1)
 }
 
-// CreateUser mocks base method.
-func (m *MockStore) CreateUser(arg0 context.Context, arg1 zigibankgo.CreateUserParams) (zigibankgo.User, error) {
+// ListStatementEntries mocks base method.
+func (m *MockStore) ListStatementEntries(arg0 context.Context, arg1 zigibankgo.ListStatementEntriesParams) ([]zigibankgo.ListStatementEntriesRow, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateUser", arg0, arg1)
-	ret0, _ := ret[0].(zigibankgo.User)
+	ret := m.ctrl.Call(m, "ListStatementEntries", arg0, arg1)
+	ret0, _ := ret[0].([]zigibankgo.ListStatementEntriesRow)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateUser indicates an expected call of CreateUser.
-func (mr *MockStoreMockRecorder) CreateUser(arg0, arg1 interface{}) *gomock.Call {
+// ListStatementEntries indicates an expected call of ListStatementEntries.
+func (mr *MockStoreMockRecorder) ListStatementEntries(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockStore)(nil).CreateUser), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListStatementEntries", reflect.TypeOf((*MockStore)(nil).ListStatementEntries), arg0, arg1)
 }
 
-// DeleteAccount mocks base method.
-func (m *MockStore) DeleteAccount(arg0 context.Context, arg1 int64) error {
+// ListTransferEntryCounts mocks base method.
+func (m *MockStore) ListTransferEntryCounts(arg0 context.Context, arg1 zigibankgo.ListTransferEntryCountsParams) ([]zigibankgo.ListTransferEntryCountsRow, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteAccount", arg0, arg1)
+	ret := m.ctrl.Call(m, "ListTransferEntryCounts", arg0, arg1)
+	ret0, _ := ret[0].([]zigibankgo.ListTransferEntryCountsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTransferEntryCounts indicates an expected call of ListTransferEntryCounts.
+func (mr *MockStoreMockRecorder) ListTransferEntryCounts(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTransferEntryCounts", reflect.TypeOf((*MockStore)(nil).ListTransferEntryCounts), arg0, arg1)
+}
+
+// ListTransfers mocks base method.
+func (m *MockStore) ListTransfers(arg0 context.Context) ([]zigibankgo.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTransfers", arg0)
+	ret0, _ := ret[0].([]zigibankgo.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTransfers indicates an expected call of ListTransfers.
+func (mr *MockStoreMockRecorder) ListTransfers(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTransfers", reflect.TypeOf((*MockStore)(nil).ListTransfers), arg0)
+}
+
+// LockTransferLimit mocks base method.
+func (m *MockStore) LockTransferLimit(arg0 context.Context, arg1 zigibankgo.LockTransferLimitParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockTransferLimit", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// DeleteAccount indicates an expected call of DeleteAccount.
-func (mr *MockStoreMockRecorder) DeleteAccount(arg0, arg1 interface{}) *gomock.Call {
+// LockTransferLimit indicates an expected call of LockTransferLimit.
+func (mr *MockStoreMockRecorder) LockTransferLimit(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccount", reflect.TypeOf((*MockStore)(nil).DeleteAccount), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockTransferLimit", reflect.TypeOf((*MockStore)(nil).LockTransferLimit), arg0, arg1)
 }
 
-// GetAccount mocks base method.
-func (m *MockStore) GetAccount(arg0 context.Context, arg1 int64) (zigibankgo.Account, error) {
+// LogoutTx mocks base method.
+func (m *MockStore) LogoutTx(arg0 context.Context, arg1 zigibankgo.LogoutTxParams) (zigibankgo.LogoutTxResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAccount", arg0, arg1)
-	ret0, _ := ret[0].(zigibankgo.Account)
+	ret := m.ctrl.Call(m, "LogoutTx", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.LogoutTxResult)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetAccount indicates an expected call of GetAccount.
-func (mr *MockStoreMockRecorder) GetAccount(arg0, arg1 interface{}) *gomock.Call {
+// LogoutTx indicates an expected call of LogoutTx.
+func (mr *MockStoreMockRecorder) LogoutTx(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccount", reflect.TypeOf((*MockStore)(nil).GetAccount), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LogoutTx", reflect.TypeOf((*MockStore)(nil).LogoutTx), arg0, arg1)
 }
 
-// GetAccountForUpdate mocks base method.
-func (m *MockStore) GetAccountForUpdate(arg0 context.Context, arg1 int64) (zigibankgo.Account, error) {
+// NextEntryID mocks base method.
+func (m *MockStore) NextEntryID(arg0 context.Context) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAccountForUpdate", arg0, arg1)
+	ret := m.ctrl.Call(m, "NextEntryID", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NextEntryID indicates an expected call of NextEntryID.
+func (mr *MockStoreMockRecorder) NextEntryID(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NextEntryID", reflect.TypeOf((*MockStore)(nil).NextEntryID), arg0)
+}
+
+// RequestTransferReversalTx mocks base method.
+func (m *MockStore) RequestTransferReversalTx(arg0 context.Context, arg1 zigibankgo.TransferReversalTxParams) (zigibankgo.TransferReversal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestTransferReversalTx", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.TransferReversal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequestTransferReversalTx indicates an expected call of RequestTransferReversalTx.
+func (mr *MockStoreMockRecorder) RequestTransferReversalTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestTransferReversalTx", reflect.TypeOf((*MockStore)(nil).RequestTransferReversalTx), arg0, arg1)
+}
+
+// ReverseTransferTx mocks base method.
+func (m *MockStore) ReverseTransferTx(arg0 context.Context, arg1 zigibankgo.TransferReversalTxParams) (zigibankgo.TransferReversalTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReverseTransferTx", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.TransferReversalTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReverseTransferTx indicates an expected call of ReverseTransferTx.
+func (mr *MockStoreMockRecorder) ReverseTransferTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReverseTransferTx", reflect.TypeOf((*MockStore)(nil).ReverseTransferTx), arg0, arg1)
+}
+
+// RevokeUserSessionsTx mocks base method.
+func (m *MockStore) RevokeUserSessionsTx(arg0 context.Context, arg1 zigibankgo.RevokeUserSessionsTxParams) (zigibankgo.RevokeUserSessionsTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeUserSessionsTx", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.RevokeUserSessionsTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeUserSessionsTx indicates an expected call of RevokeUserSessionsTx.
+func (mr *MockStoreMockRecorder) RevokeUserSessionsTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeUserSessionsTx", reflect.TypeOf((*MockStore)(nil).RevokeUserSessionsTx), arg0, arg1)
+}
+
+// RunScheduledTransferTx mocks base method.
+func (m *MockStore) RunScheduledTransferTx(arg0 context.Context, arg1 zigibankgo.RunScheduledTransferTxParams) (zigibankgo.RunScheduledTransferTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RunScheduledTransferTx", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.RunScheduledTransferTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RunScheduledTransferTx indicates an expected call of RunScheduledTransferTx.
+func (mr *MockStoreMockRecorder) RunScheduledTransferTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunScheduledTransferTx", reflect.TypeOf((*MockStore)(nil).RunScheduledTransferTx), arg0, arg1)
+}
+
+// SetAccountStatusTx mocks base method.
+func (m *MockStore) SetAccountStatusTx(arg0 context.Context, arg1 zigibankgo.SetAccountStatusTxParams) (zigibankgo.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetAccountStatusTx", arg0, arg1)
 	ret0, _ := ret[0].(zigibankgo.Account)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetAccountForUpdate indicates an expected call of GetAccountForUpdate.
-func (mr *MockStoreMockRecorder) GetAccountForUpdate(arg0, arg1 interface{}) *gomock.Call {
+// SetAccountStatusTx indicates an expected call of SetAccountStatusTx.
+func (mr *MockStoreMockRecorder) SetAccountStatusTx(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountForUpdate", reflect.TypeOf((*MockStore)(nil).GetAccountForUpdate), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAccountStatusTx", reflect.TypeOf((*MockStore)(nil).SetAccountStatusTx), arg0, arg1)
 }
 
-// GetEntry mocks base method.
-func (m *MockStore) GetEntry(arg0 context.Context, arg1 int64) (zigibankgo.Entry, error) {
+// SetCashMovementEntry mocks base method.
+func (m *MockStore) SetCashMovementEntry(arg0 context.Context, arg1 zigibankgo.SetCashMovementEntryParams) (zigibankgo.CashMovement, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetEntry", arg0, arg1)
-	ret0, _ := ret[0].(zigibankgo.Entry)
+	ret := m.ctrl.Call(m, "SetCashMovementEntry", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.CashMovement)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetEntry indicates an expected call of GetEntry.
-func (mr *MockStoreMockRecorder) GetEntry(arg0, arg1 interface{}) *gomock.Call {
+// SetCashMovementEntry indicates an expected call of SetCashMovementEntry.
+func (mr *MockStoreMockRecorder) SetCashMovementEntry(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEntry", reflect.TypeOf((*MockStore)(nil).GetEntry), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCashMovementEntry", reflect.TypeOf((*MockStore)(nil).SetCashMovementEntry), arg0, arg1)
 }
 
-// GetTransfer mocks base method.
-func (m *MockStore) GetTransfer(arg0 context.Context, arg1 zigibankgo.GetTransferParams) (zigibankgo.Transfer, error) {
+// SetInterestRateTx mocks base method.
+func (m *MockStore) SetInterestRateTx(arg0 context.Context, arg1 zigibankgo.UpsertInterestRateParams) (zigibankgo.InterestRate, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetTransfer", arg0, arg1)
-	ret0, _ := ret[0].(zigibankgo.Transfer)
+	ret := m.ctrl.Call(m, "SetInterestRateTx", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.InterestRate)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetTransfer indicates an expected call of GetTransfer.
-func (mr *MockStoreMockRecorder) GetTransfer(arg0, arg1 interface{}) *gomock.Call {
+// SetInterestRateTx indicates an expected call of SetInterestRateTx.
+func (mr *MockStoreMockRecorder) SetInterestRateTx(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransfer", reflect.TypeOf((*MockStore)(nil).GetTransfer), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetInterestRateTx", reflect.TypeOf((*MockStore)(nil).SetInterestRateTx), arg0, arg1)
 }
 
-// GetTransferFromId mocks base method.
-func (m *MockStore) GetTransferFromId(arg0 context.Context, arg1 int64) (zigibankgo.Transfer, error) {
+// SetTransferLimitTx mocks base method.
+func (m *MockStore) SetTransferLimitTx(arg0 context.Context, arg1 zigibankgo.UpsertTransferLimitParams) (zigibankgo.TransferLimit, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetTransferFromId", arg0, arg1)
-	ret0, _ := ret[0].(zigibankgo.Transfer)
+	ret := m.ctrl.Call(m, "SetTransferLimitTx", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.TransferLimit)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetTransferFromId indicates an expected call of GetTransferFromId.
-func (mr *MockStoreMockRecorder) GetTransferFromId(arg0, arg1 interface{}) *gomock.Call {
+// SetTransferLimitTx indicates an expected call of SetTransferLimitTx.
+func (mr *MockStoreMockRecorder) SetTransferLimitTx(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransferFromId", reflect.TypeOf((*MockStore)(nil).GetTransferFromId), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTransferLimitTx", reflect.TypeOf((*MockStore)(nil).SetTransferLimitTx), arg0, arg1)
 }
 
-// GetUser mocks base method.
-func (m *MockStore) GetUser(arg0 context.Context, arg1 string) (zigibankgo.User, error) {
+// SettleHold mocks base method.
+func (m *MockStore) SettleHold(arg0 context.Context, arg1 zigibankgo.SettleHoldParams) (zigibankgo.Hold, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUser", arg0, arg1)
-	ret0, _ := ret[0].(zigibankgo.User)
+	ret := m.ctrl.Call(m, "SettleHold", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.Hold)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetUser indicates an expected call of GetUser.
-func (mr *MockStoreMockRecorder) GetUser(arg0, arg1 interface{}) *gomock.Call {
+// SettleHold indicates an expected call of SettleHold.
+func (mr *MockStoreMockRecorder) SettleHold(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockStore)(nil).GetUser), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SettleHold", reflect.TypeOf((*MockStore)(nil).SettleHold), arg0, arg1)
 }
 
-// ListAccounts mocks base method.
-func (m *MockStore) ListAccounts(arg0 context.Context, arg1 zigibankgo.ListAccountsParams) ([]zigibankgo.Account, error) {
+// StatementTx mocks base method.
+func (m *MockStore) StatementTx(arg0 context.Context, arg1 zigibankgo.StatementTxParams) (zigibankgo.StatementTxResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListAccounts", arg0, arg1)
-	ret0, _ := ret[0].([]zigibankgo.Account)
+	ret := m.ctrl.Call(m, "StatementTx", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.StatementTxResult)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListAccounts indicates an expected call of ListAccounts.
-func (mr *MockStoreMockRecorder) ListAccounts(arg0, arg1 interface{}) *gomock.Call {
+// StatementTx indicates an expected call of StatementTx.
+func (mr *MockStoreMockRecorder) StatementTx(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccounts", reflect.TypeOf((*MockStore)(nil).ListAccounts), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StatementTx", reflect.TypeOf((*MockStore)(nil).StatementTx), arg0, arg1)
 }
 
-// ListEntries mocks base method.
-func (m *MockStore) ListEntries(arg0 context.Context) ([]zigibankgo.Entry, error) {
+// SumEntriesFromID mocks base method.
+func (m *MockStore) SumEntriesFromID(arg0 context.Context, arg1 zigibankgo.SumEntriesFromIDParams) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListEntries", arg0)
-	ret0, _ := ret[0].([]zigibankgo.Entry)
+	ret := m.ctrl.Call(m, "SumEntriesFromID", arg0, arg1)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListEntries indicates an expected call of ListEntries.
-func (mr *MockStoreMockRecorder) ListEntries(arg0 interface{}) *gomock.Call {
+// SumEntriesFromID indicates an expected call of SumEntriesFromID.
+func (mr *MockStoreMockRecorder) SumEntriesFromID(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEntries", reflect.TypeOf((*MockStore)(nil).ListEntries), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SumEntriesFromID", reflect.TypeOf((*MockStore)(nil).SumEntriesFromID), arg0, arg1)
 }
 
-// ListTransfers mocks base method.
-func (m *MockStore) ListTransfers(arg0 context.Context) ([]zigibankgo.Transfer, error) {
+// SumEntriesSince mocks base method.
+func (m *MockStore) SumEntriesSince(arg0 context.Context, arg1 zigibankgo.SumEntriesSinceParams) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListTransfers", arg0)
-	ret0, _ := ret[0].([]zigibankgo.Transfer)
+	ret := m.ctrl.Call(m, "SumEntriesSince", arg0, arg1)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListTransfers indicates an expected call of ListTransfers.
-func (mr *MockStoreMockRecorder) ListTransfers(arg0 interface{}) *gomock.Call {
+// SumEntriesSince indicates an expected call of SumEntriesSince.
+func (mr *MockStoreMockRecorder) SumEntriesSince(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTransfers", reflect.TypeOf((*MockStore)(nil).ListTransfers), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SumEntriesSince", reflect.TypeOf((*MockStore)(nil).SumEntriesSince), arg0, arg1)
+}
+
+// SumUncapitalizedInterest mocks base method.
+func (m *MockStore) SumUncapitalizedInterest(arg0 context.Context, arg1 zigibankgo.SumUncapitalizedInterestParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SumUncapitalizedInterest", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SumUncapitalizedInterest indicates an expected call of SumUncapitalizedInterest.
+func (mr *MockStoreMockRecorder) SumUncapitalizedInterest(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SumUncapitalizedInterest", reflect.TypeOf((*MockStore)(nil).SumUncapitalizedInterest), arg0, arg1)
 }
 
 // TransferTx mocks base method.
@@ -274,6 +1681,21 @@ func (mr *MockStoreMockRecorder) TransferTx(arg0, arg1 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransferTx", reflect.TypeOf((*MockStore)(nil).TransferTx), arg0, arg1)
 }
 
+// UpdateAccountStatus mocks base method.
+func (m *MockStore) UpdateAccountStatus(arg0 context.Context, arg1 zigibankgo.UpdateAccountStatusParams) (zigibankgo.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAccountStatus", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateAccountStatus indicates an expected call of UpdateAccountStatus.
+func (mr *MockStoreMockRecorder) UpdateAccountStatus(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAccountStatus", reflect.TypeOf((*MockStore)(nil).UpdateAccountStatus), arg0, arg1)
+}
+
 // UpdateBalance mocks base method.
 func (m *MockStore) UpdateBalance(arg0 context.Context, arg1 zigibankgo.UpdateBalanceParams) (int64, error) {
 	m.ctrl.T.Helper()
@@ -288,3 +1710,123 @@ func (mr *MockStoreMockRecorder) UpdateBalance(arg0, arg1 interface{}) *gomock.C
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBalance", reflect.TypeOf((*MockStore)(nil).UpdateBalance), arg0, arg1)
 }
+
+// UpdateOverdraftLimit mocks base method.
+func (m *MockStore) UpdateOverdraftLimit(arg0 context.Context, arg1 zigibankgo.UpdateOverdraftLimitParams) (zigibankgo.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateOverdraftLimit", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateOverdraftLimit indicates an expected call of UpdateOverdraftLimit.
+func (mr *MockStoreMockRecorder) UpdateOverdraftLimit(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOverdraftLimit", reflect.TypeOf((*MockStore)(nil).UpdateOverdraftLimit), arg0, arg1)
+}
+
+// UpdateScheduledTransfer mocks base method.
+func (m *MockStore) UpdateScheduledTransfer(arg0 context.Context, arg1 zigibankgo.UpdateScheduledTransferParams) (zigibankgo.ScheduledTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateScheduledTransfer", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.ScheduledTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateScheduledTransfer indicates an expected call of UpdateScheduledTransfer.
+func (mr *MockStoreMockRecorder) UpdateScheduledTransfer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateScheduledTransfer", reflect.TypeOf((*MockStore)(nil).UpdateScheduledTransfer), arg0, arg1)
+}
+
+// UpdateScheduledTransferTx mocks base method.
+func (m *MockStore) UpdateScheduledTransferTx(arg0 context.Context, arg1 zigibankgo.UpdateScheduledTransferParams) (zigibankgo.ScheduledTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateScheduledTransferTx", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.ScheduledTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateScheduledTransferTx indicates an expected call of UpdateScheduledTransferTx.
+func (mr *MockStoreMockRecorder) UpdateScheduledTransferTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateScheduledTransferTx", reflect.TypeOf((*MockStore)(nil).UpdateScheduledTransferTx), arg0, arg1)
+}
+
+// UpdateUserRole mocks base method.
+func (m *MockStore) UpdateUserRole(arg0 context.Context, arg1 zigibankgo.UpdateUserRoleParams) (zigibankgo.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserRole", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserRole indicates an expected call of UpdateUserRole.
+func (mr *MockStoreMockRecorder) UpdateUserRole(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserRole", reflect.TypeOf((*MockStore)(nil).UpdateUserRole), arg0, arg1)
+}
+
+// UpsertInterestRate mocks base method.
+func (m *MockStore) UpsertInterestRate(arg0 context.Context, arg1 zigibankgo.UpsertInterestRateParams) (zigibankgo.InterestRate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertInterestRate", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.InterestRate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertInterestRate indicates an expected call of UpsertInterestRate.
+func (mr *MockStoreMockRecorder) UpsertInterestRate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertInterestRate", reflect.TypeOf((*MockStore)(nil).UpsertInterestRate), arg0, arg1)
+}
+
+// UpsertTransferLimit mocks base method.
+func (m *MockStore) UpsertTransferLimit(arg0 context.Context, arg1 zigibankgo.UpsertTransferLimitParams) (zigibankgo.TransferLimit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertTransferLimit", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.TransferLimit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertTransferLimit indicates an expected call of UpsertTransferLimit.
+func (mr *MockStoreMockRecorder) UpsertTransferLimit(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertTransferLimit", reflect.TypeOf((*MockStore)(nil).UpsertTransferLimit), arg0, arg1)
+}
+
+// VoidHoldTx mocks base method.
+func (m *MockStore) VoidHoldTx(arg0 context.Context, arg1 int64) (zigibankgo.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VoidHoldTx", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VoidHoldTx indicates an expected call of VoidHoldTx.
+func (mr *MockStoreMockRecorder) VoidHoldTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VoidHoldTx", reflect.TypeOf((*MockStore)(nil).VoidHoldTx), arg0, arg1)
+}
+
+// WithdrawTx mocks base method.
+func (m *MockStore) WithdrawTx(arg0 context.Context, arg1 zigibankgo.CashTxParams) (zigibankgo.CashTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithdrawTx", arg0, arg1)
+	ret0, _ := ret[0].(zigibankgo.CashTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WithdrawTx indicates an expected call of WithdrawTx.
+func (mr *MockStoreMockRecorder) WithdrawTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithdrawTx", reflect.TypeOf((*MockStore)(nil).WithdrawTx), arg0, arg1)
+}
@@ -1,8 +1,8 @@
 -- name: CreateAccount :one
 INSERT INTO account (
-  owner, balance, currency
+  owner, balance, currency, kind
 ) VALUES (
-  $1, $2, $3
+  $1, $2, $3, $4
 )
 RETURNING *;
 
@@ -37,4 +37,27 @@ RETURNING id;
 
 -- name: DeleteAccount :exec
 DELETE FROM account
-WHERE id = $1;
\ No newline at end of file
+WHERE id = $1;
+
+-- name: UpdateOverdraftLimit :one
+UPDATE account
+SET overdraft_limit = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: UpdateAccountStatus :one
+UPDATE account
+SET status = $2, status_reason = $3, status_changed_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: ListAccountIDsByOwner :many
+SELECT id FROM account
+WHERE owner = $1
+ORDER BY id;
+
+-- name: AddAccountHeldAmount :one
+UPDATE account
+SET held_amount = held_amount + sqlc.arg(amount)
+WHERE id = sqlc.arg(id)
+RETURNING *;
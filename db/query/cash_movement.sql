@@ -0,0 +1,19 @@
+-- name: CreateCashMovement :one
+INSERT INTO cash_movements (
+  kind, account_id, amount, external_reference, created_by
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+ON CONFLICT (kind, external_reference) DO NOTHING
+RETURNING *;
+
+-- name: GetCashMovementByReference :one
+SELECT * FROM cash_movements
+WHERE kind = $1 AND external_reference = $2
+LIMIT 1;
+
+-- name: SetCashMovementEntry :one
+UPDATE cash_movements
+SET entry_id = $2
+WHERE id = $1
+RETURNING *;
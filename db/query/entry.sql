@@ -1,15 +1,72 @@
 -- name: CreateEntry :one
 INSERT INTO entries (
-  account_id, amount
+  id, account_id, amount, transfer_id, journal_id, created_at, prev_hash, hash
 ) VALUES (
-  $1, $2
+  $1, $2, $3, $4, $5, $6, $7, $8
 )
 RETURNING *;
 
+-- name: NextEntryID :one
+-- reserves the id of an entry, which is part of its hash
+SELECT nextval(pg_get_serial_sequence('entries', 'id'))::bigint AS id;
+
+-- name: GetLastAccountEntry :one
+-- the entry the next one of the account chains onto
+SELECT * FROM entries
+WHERE account_id = $1
+ORDER BY id DESC
+LIMIT 1;
+
+-- name: ListChainEntries :many
+-- walks the entries account by account, in the order they were chained
+SELECT * FROM entries
+WHERE (account_id, id) > (sqlc.arg(after_account_id)::bigint, sqlc.arg(after_id)::bigint)
+ORDER BY account_id, id
+LIMIT sqlc.arg(limit_count);
+
 -- name: GetEntry :one
 SELECT * FROM entries
 WHERE account_id = $1
 LIMIT 1;
 
 -- name: ListEntries :many
-SELECT * FROM entries;
\ No newline at end of file
+SELECT * FROM entries;
+
+-- name: ListAccountEntries :many
+SELECT * FROM entries
+WHERE account_id = sqlc.arg(account_id)
+  AND created_at >= sqlc.arg(from_time)
+  AND created_at < sqlc.arg(to_time)
+  AND id > sqlc.arg(after_id)
+ORDER BY id
+LIMIT sqlc.arg(limit_count);
+
+-- name: SumEntriesSince :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS total
+FROM entries
+WHERE account_id = sqlc.arg(account_id)
+  AND created_at >= sqlc.arg(since);
+
+-- name: SumEntriesFromID :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS total
+FROM entries
+WHERE account_id = sqlc.arg(account_id)
+  AND id >= sqlc.arg(from_id);
+
+-- name: ListStatementEntries :many
+SELECT e.id, e.account_id, e.amount, e.created_at, e.transfer_id,
+       t.from_account_id, t.to_account_id,
+       ba.reason AS adjustment_reason,
+       cm.kind AS movement_kind, cm.external_reference
+FROM entries e
+LEFT JOIN transfers t ON t.id = e.transfer_id
+LEFT JOIN balance_adjustments ba ON ba.entry_id = e.id
+LEFT JOIN cash_movements cm ON cm.entry_id = e.id
+WHERE e.account_id = sqlc.arg(account_id)
+  AND e.created_at >= sqlc.arg(from_time)
+  AND e.created_at < sqlc.arg(to_time)
+ORDER BY e.id;
+
+-- name: GetEntryFromId :one
+SELECT * FROM entries
+WHERE id = $1;
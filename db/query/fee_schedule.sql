@@ -0,0 +1,14 @@
+-- name: CreateFeeSchedule :one
+INSERT INTO fee_schedules (
+  currency, kind, flat_fee, rate_bps, tiers, min_fee, max_fee, created_by
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8
+)
+RETURNING *;
+
+-- name: GetFeeSchedule :one
+-- the schedule in force for a currency is the last one set
+SELECT * FROM fee_schedules
+WHERE currency = $1
+ORDER BY id DESC
+LIMIT 1;
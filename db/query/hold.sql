@@ -0,0 +1,31 @@
+-- name: CreateHold :one
+INSERT INTO holds (
+  from_account_id, to_account_id, amount, expires_at
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: GetHold :one
+SELECT * FROM holds
+WHERE id = $1
+LIMIT 1;
+
+-- name: GetHoldForUpdate :one
+SELECT * FROM holds
+WHERE id = $1
+LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ClaimExpiredHold :one
+SELECT * FROM holds
+WHERE status = 'pending' AND expires_at <= sqlc.arg(now)
+ORDER BY expires_at
+LIMIT 1
+FOR NO KEY UPDATE SKIP LOCKED;
+
+-- name: SettleHold :one
+UPDATE holds
+SET status = $2, transfer_id = $3, settled_at = now()
+WHERE id = $1
+RETURNING *;
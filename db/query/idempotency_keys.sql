@@ -0,0 +1,13 @@
+-- name: CreateIdempotencyKey :one
+INSERT INTO idempotency_keys (
+  username, key, request_path, request_hash, response_body
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+ON CONFLICT (username, key) DO NOTHING
+RETURNING *;
+
+-- name: GetIdempotencyKey :one
+SELECT * FROM idempotency_keys
+WHERE username = $1 AND key = $2
+LIMIT 1;
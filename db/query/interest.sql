@@ -0,0 +1,61 @@
+-- name: CapitalizeInterestAccruals :execrows
+UPDATE interest_accruals
+SET capitalized_at = now(), entry_id = sqlc.narg(entry_id)
+WHERE account_id = sqlc.arg(account_id)
+  AND accrual_date < sqlc.arg(before)
+  AND capitalized_at IS NULL;
+
+-- name: CreateInterestAccrual :one
+INSERT INTO interest_accruals (
+  account_id, accrual_date, balance, annual_rate, amount
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+RETURNING *;
+
+-- name: GetInterestAccrual :one
+SELECT * FROM interest_accruals
+WHERE account_id = $1 AND accrual_date = $2
+LIMIT 1;
+
+-- name: GetInterestRate :one
+SELECT * FROM interest_rates
+WHERE currency = $1 AND account_kind = $2
+LIMIT 1;
+
+-- name: ListAccountsWithUncapitalizedInterest :many
+SELECT DISTINCT account_id FROM interest_accruals
+WHERE capitalized_at IS NULL
+  AND accrual_date < sqlc.arg(before)
+  AND account_id > sqlc.arg(after_id)
+ORDER BY account_id
+LIMIT sqlc.arg(limit_count);
+
+-- name: ListInterestBearingAccounts :many
+-- accounts open before the end of a day with a rate set for their currency and kind
+SELECT a.id FROM account a
+JOIN interest_rates r ON r.currency = a.currency AND r.account_kind = a.kind
+WHERE a.status <> 'closed'
+  AND a.created_at < sqlc.arg(before)
+  AND a.id > sqlc.arg(after_id)
+ORDER BY a.id
+LIMIT sqlc.arg(limit_count);
+
+-- name: SumUncapitalizedInterest :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS total
+FROM interest_accruals
+WHERE account_id = sqlc.arg(account_id)
+  AND accrual_date < sqlc.arg(before)
+  AND capitalized_at IS NULL;
+
+-- name: UpsertInterestRate :one
+INSERT INTO interest_rates (
+  currency, account_kind, annual_rate, updated_by
+) VALUES (
+  $1, $2, $3, $4
+)
+ON CONFLICT (currency, account_kind) DO UPDATE
+SET annual_rate = EXCLUDED.annual_rate,
+    updated_by = EXCLUDED.updated_by,
+    updated_at = now()
+RETURNING *;
@@ -0,0 +1,35 @@
+-- name: CreateJournal :one
+INSERT INTO journals (
+  kind
+) VALUES (
+  $1
+)
+RETURNING *;
+
+-- name: GetSystemAccount :one
+SELECT a.* FROM account a
+JOIN system_accounts sa ON sa.account_id = a.id
+WHERE sa.kind = $1 AND sa.currency = $2
+LIMIT 1;
+
+-- name: CreateSystemAccount :exec
+-- opens the account of a kind in a currency, owned by the system user of the kind.
+-- A posting racing to open the same account waits for it and leaves it in place
+WITH created AS (
+  INSERT INTO account (
+    owner,
+    balance,
+    currency
+  ) VALUES (
+    sqlc.arg(kind), 0, sqlc.arg(currency)
+  )
+  ON CONFLICT DO NOTHING
+  RETURNING id, owner, currency
+)
+INSERT INTO system_accounts (kind, currency, account_id)
+SELECT owner, currency, id FROM created;
+
+-- name: IsSystemAccount :one
+SELECT EXISTS (
+  SELECT 1 FROM system_accounts WHERE account_id = $1
+) AS is_system;
@@ -0,0 +1,40 @@
+-- name: ListAccountEntryTotals :many
+SELECT a.id, a.balance,
+       COALESCE((SELECT SUM(e.amount) FROM entries e WHERE e.account_id = a.id), 0)::bigint AS entries_total
+FROM account a
+WHERE a.id > sqlc.arg(after_id)
+ORDER BY a.id
+LIMIT sqlc.arg(limit_count);
+
+-- name: ListTransferEntryCounts :many
+SELECT t.id, t.from_account_id, t.to_account_id, t.amount, t.to_amount,
+       COUNT(e.id)::bigint AS entry_count,
+       COUNT(e.id) FILTER (WHERE e.account_id = t.from_account_id AND e.amount = -t.amount)::bigint AS debit_count,
+       COUNT(e.id) FILTER (WHERE e.account_id = t.to_account_id AND e.amount = t.to_amount)::bigint AS credit_count,
+       (u.transfer_id IS NOT NULL)::bool AS unlinked
+FROM transfers t
+LEFT JOIN entries e ON e.transfer_id = t.id
+LEFT JOIN unlinked_transfers u ON u.transfer_id = t.id
+WHERE t.id > sqlc.arg(after_id)
+GROUP BY t.id, u.transfer_id
+ORDER BY t.id
+LIMIT sqlc.arg(limit_count);
+
+-- name: CreateReconciliationReport :one
+INSERT INTO reconciliation_reports (
+  started_at, finished_at, accounts_checked, transfers_checked, transfers_unverifiable, discrepancy_count, discrepancies, run_by
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8
+)
+RETURNING *;
+
+-- name: ListReconciliationReports :many
+SELECT * FROM reconciliation_reports
+ORDER BY id DESC
+LIMIT $1 OFFSET $2;
+
+-- name: ListCurrencyTotals :many
+SELECT currency, SUM(balance)::bigint AS total
+FROM account
+GROUP BY currency
+ORDER BY currency;
@@ -0,0 +1,62 @@
+-- name: CreateScheduledTransfer :one
+INSERT INTO scheduled_transfers (
+  owner, from_account_id, to_account_id, amount, cadence, start_at, next_run_at, end_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $6, $7
+)
+RETURNING *;
+
+-- name: GetScheduledTransfer :one
+SELECT * FROM scheduled_transfers
+WHERE id = $1
+LIMIT 1;
+
+-- name: ListScheduledTransfers :many
+SELECT * FROM scheduled_transfers
+WHERE owner = $1
+ORDER BY id
+LIMIT $2 OFFSET $3;
+
+-- name: ListOpenScheduledTransfersByAccount :many
+-- schedules still to run that pay from or into an account, locked so they can be cancelled
+SELECT * FROM scheduled_transfers
+WHERE (from_account_id = sqlc.arg(account_id) OR to_account_id = sqlc.arg(account_id))
+  AND status IN ('active', 'paused')
+ORDER BY id
+FOR UPDATE;
+
+-- name: UpdateScheduledTransfer :one
+UPDATE scheduled_transfers
+SET
+  amount = COALESCE(sqlc.narg(amount), amount),
+  end_at = COALESCE(sqlc.narg(end_at), end_at),
+  status = COALESCE(sqlc.narg(status), status)
+WHERE id = sqlc.arg(id) AND status IN ('active', 'paused')
+RETURNING *;
+
+-- name: ClaimDueScheduledTransfer :one
+SELECT * FROM scheduled_transfers
+WHERE status = 'active' AND next_run_at <= sqlc.arg(now)
+ORDER BY next_run_at
+LIMIT 1
+FOR UPDATE SKIP LOCKED;
+
+-- name: AdvanceScheduledTransfer :one
+UPDATE scheduled_transfers
+SET next_run_at = $2, status = $3
+WHERE id = $1
+RETURNING *;
+
+-- name: CreateScheduledTransferRun :one
+INSERT INTO scheduled_transfer_runs (
+  scheduled_transfer_id, scheduled_for, transfer_id, error
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: ListScheduledTransferRuns :many
+SELECT * FROM scheduled_transfer_runs
+WHERE scheduled_transfer_id = $1
+ORDER BY id DESC
+LIMIT $2 OFFSET $3;
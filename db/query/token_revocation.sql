@@ -0,0 +1,16 @@
+-- name: CreateTokenRevocation :one
+INSERT INTO token_revocations (
+  username, token_id, expires_at
+) VALUES (
+  $1, $2, $3
+)
+RETURNING *;
+
+-- name: ListActiveTokenRevocations :many
+SELECT * FROM token_revocations
+WHERE expires_at > now()
+ORDER BY id;
+
+-- name: DeleteExpiredTokenRevocations :execrows
+DELETE FROM token_revocations
+WHERE expires_at <= now();
\ No newline at end of file
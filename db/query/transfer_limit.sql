@@ -0,0 +1,40 @@
+-- name: DeleteTransferLimit :exec
+DELETE FROM transfer_limits
+WHERE username = $1 AND currency = $2;
+
+-- name: GetHeldAmountTotal :one
+-- money the pending holds of a user reserve in a currency
+SELECT COALESCE(SUM(held_amount), 0)::bigint AS total
+FROM account
+WHERE owner = sqlc.arg(owner) AND currency = sqlc.arg(currency);
+
+-- name: GetOutboundTransferTotal :one
+SELECT COALESCE(SUM(t.amount), 0)::bigint AS total
+FROM transfers t
+JOIN account a ON a.id = t.from_account_id
+WHERE a.owner = sqlc.arg(owner)
+  AND a.currency = sqlc.arg(currency)
+  AND t.created_at >= sqlc.arg(since)
+  AND t.reversal_of IS NULL;
+
+-- name: GetTransferLimit :one
+SELECT * FROM transfer_limits
+WHERE username = $1 AND currency = $2
+LIMIT 1;
+
+-- name: LockTransferLimit :exec
+-- serializes the outbound transfers of a user in a currency until the transaction ends
+SELECT pg_advisory_xact_lock(hashtext(sqlc.arg(owner)::text || '/' || sqlc.arg(currency)::text));
+
+-- name: UpsertTransferLimit :one
+INSERT INTO transfer_limits (
+  username, currency, per_transfer_limit, daily_limit, updated_by
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+ON CONFLICT (username, currency) DO UPDATE
+SET per_transfer_limit = EXCLUDED.per_transfer_limit,
+    daily_limit = EXCLUDED.daily_limit,
+    updated_by = EXCLUDED.updated_by,
+    updated_at = now()
+RETURNING *;
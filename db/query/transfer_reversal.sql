@@ -0,0 +1,19 @@
+-- name: CreateTransferReversal :one
+INSERT INTO transfer_reversals (
+  transfer_id, amount, reason, requested_by, status, reversal_transfer_id, completed_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+)
+RETURNING *;
+
+-- name: GetPendingTransferReversalForUpdate :one
+SELECT * FROM transfer_reversals
+WHERE transfer_id = $1 AND status = 'pending'
+LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: CompleteTransferReversal :one
+UPDATE transfer_reversals
+SET status = 'completed', reversal_transfer_id = $2, completed_at = now()
+WHERE id = $1
+RETURNING *;
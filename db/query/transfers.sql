@@ -1,8 +1,8 @@
 -- name: CreateTransfer :one
 INSERT INTO transfers (
-  from_account_id, to_account_id, amount
+  from_account_id, to_account_id, amount, to_amount, exchange_rate, rate_quoted_at, reversal_of, fee
 ) VALUES (
-  $1, $2, $3
+  $1, $2, $3, $4, $5, $6, $7, $8
 )
 RETURNING *;
 
@@ -15,5 +15,17 @@ LIMIT 1;
 SELECT * FROM transfers
 WHERE id = $1;
 
+-- name: GetTransferForUpdate :one
+SELECT * FROM transfers
+WHERE id = $1
+LIMIT 1
+FOR NO KEY UPDATE;
+
 -- name: ListTransfers :many
-SELECT * FROM transfers;
\ No newline at end of file
+SELECT * FROM transfers;
+
+-- name: AddTransferReversedAmount :one
+UPDATE transfers
+SET reversed_amount = reversed_amount + sqlc.arg(amount)
+WHERE id = sqlc.arg(id)
+RETURNING *;
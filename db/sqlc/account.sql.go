@@ -7,6 +7,7 @@ package zigibankgo
 
 import (
 	"context"
+	"database/sql"
 )
 
 const addAccountBalance = `-- name: AddAccountBalance :one
@@ -28,23 +29,60 @@ func (q *Queries) AddAccountBalance(ctx context.Context, arg AddAccountBalancePa
 	return id, err
 }
 
+const addAccountHeldAmount = `-- name: AddAccountHeldAmount :one
+UPDATE account
+SET held_amount = held_amount + $1
+WHERE id = $2
+RETURNING id, owner, balance, currency, created_at, overdraft_limit, held_amount, status, status_reason, status_changed_at, kind
+`
+
+type AddAccountHeldAmountParams struct {
+	Amount int64
+	ID     int64
+}
+
+func (q *Queries) AddAccountHeldAmount(ctx context.Context, arg AddAccountHeldAmountParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, addAccountHeldAmount, arg.Amount, arg.ID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.HeldAmount,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
+		&i.Kind,
+	)
+	return i, err
+}
+
 const createAccount = `-- name: CreateAccount :one
 INSERT INTO account (
-  owner, balance, currency
+  owner, balance, currency, kind
 ) VALUES (
-  $1, $2, $3
+  $1, $2, $3, $4
 )
-RETURNING id, owner, balance, currency, created_at
+RETURNING id, owner, balance, currency, created_at, overdraft_limit, held_amount, status, status_reason, status_changed_at, kind
 `
 
 type CreateAccountParams struct {
 	Owner    string
 	Balance  int64
 	Currency string
+	Kind     string
 }
 
 func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error) {
-	row := q.db.QueryRowContext(ctx, createAccount, arg.Owner, arg.Balance, arg.Currency)
+	row := q.db.QueryRowContext(ctx, createAccount,
+		arg.Owner,
+		arg.Balance,
+		arg.Currency,
+		arg.Kind,
+	)
 	var i Account
 	err := row.Scan(
 		&i.ID,
@@ -52,6 +90,12 @@ func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.HeldAmount,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
+		&i.Kind,
 	)
 	return i, err
 }
@@ -67,7 +111,7 @@ func (q *Queries) DeleteAccount(ctx context.Context, id int64) error {
 }
 
 const getAccount = `-- name: GetAccount :one
-SELECT id, owner, balance, currency, created_at FROM account
+SELECT id, owner, balance, currency, created_at, overdraft_limit, held_amount, status, status_reason, status_changed_at, kind FROM account
 WHERE id = $1
 LIMIT 1
 `
@@ -81,12 +125,18 @@ func (q *Queries) GetAccount(ctx context.Context, id int64) (Account, error) {
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.HeldAmount,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
+		&i.Kind,
 	)
 	return i, err
 }
 
 const getAccountForUpdate = `-- name: GetAccountForUpdate :one
-SELECT id, owner, balance, currency, created_at FROM account
+SELECT id, owner, balance, currency, created_at, overdraft_limit, held_amount, status, status_reason, status_changed_at, kind FROM account
 WHERE id = $1
 LIMIT 1
 FOR NO KEY UPDATE
@@ -101,12 +151,47 @@ func (q *Queries) GetAccountForUpdate(ctx context.Context, id int64) (Account, e
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.HeldAmount,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
+		&i.Kind,
 	)
 	return i, err
 }
 
+const listAccountIDsByOwner = `-- name: ListAccountIDsByOwner :many
+SELECT id FROM account
+WHERE owner = $1
+ORDER BY id
+`
+
+func (q *Queries) ListAccountIDsByOwner(ctx context.Context, owner string) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountIDsByOwner, owner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listAccounts = `-- name: ListAccounts :many
-SELECT id, owner, balance, currency, created_at FROM account
+SELECT id, owner, balance, currency, created_at, overdraft_limit, held_amount, status, status_reason, status_changed_at, kind FROM account
 WHERE owner = $1
 ORDER BY id
 LIMIT $2 OFFSET $3
@@ -133,6 +218,12 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 			&i.Balance,
 			&i.Currency,
 			&i.CreatedAt,
+			&i.OverdraftLimit,
+			&i.HeldAmount,
+			&i.Status,
+			&i.StatusReason,
+			&i.StatusChangedAt,
+			&i.Kind,
 		); err != nil {
 			return nil, err
 		}
@@ -147,6 +238,38 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 	return items, nil
 }
 
+const updateAccountStatus = `-- name: UpdateAccountStatus :one
+UPDATE account
+SET status = $2, status_reason = $3, status_changed_at = now()
+WHERE id = $1
+RETURNING id, owner, balance, currency, created_at, overdraft_limit, held_amount, status, status_reason, status_changed_at, kind
+`
+
+type UpdateAccountStatusParams struct {
+	ID           int64
+	Status       string
+	StatusReason sql.NullString
+}
+
+func (q *Queries) UpdateAccountStatus(ctx context.Context, arg UpdateAccountStatusParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, updateAccountStatus, arg.ID, arg.Status, arg.StatusReason)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.HeldAmount,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
+		&i.Kind,
+	)
+	return i, err
+}
+
 const updateBalance = `-- name: UpdateBalance :one
 UPDATE account
 SET balance = $2
@@ -165,3 +288,34 @@ func (q *Queries) UpdateBalance(ctx context.Context, arg UpdateBalanceParams) (i
 	err := row.Scan(&id)
 	return id, err
 }
+
+const updateOverdraftLimit = `-- name: UpdateOverdraftLimit :one
+UPDATE account
+SET overdraft_limit = $2
+WHERE id = $1
+RETURNING id, owner, balance, currency, created_at, overdraft_limit, held_amount, status, status_reason, status_changed_at, kind
+`
+
+type UpdateOverdraftLimitParams struct {
+	ID             int64
+	OverdraftLimit int64
+}
+
+func (q *Queries) UpdateOverdraftLimit(ctx context.Context, arg UpdateOverdraftLimitParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, updateOverdraftLimit, arg.ID, arg.OverdraftLimit)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.HeldAmount,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
+		&i.Kind,
+	)
+	return i, err
+}
@@ -0,0 +1,198 @@
+package zigibankgo
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// Contains input parameters of the account entries transaction
+type ListAccountEntriesTxParams struct {
+	AccountID int64
+	// entries created in [From, To) are listed
+	From time.Time
+	To   time.Time
+	// only entries with a greater id are listed, 0 starts from the first one
+	AfterID int64
+	Limit   int64
+}
+
+// An account entry with the balance it left the account at
+type EntryLine struct {
+	Entry
+	// account balance right after the entry
+	Balance int64 `json:"balance"`
+}
+
+// Contains result of the account entries transaction
+type ListAccountEntriesTxResult struct {
+	Account Account `json:"account"`
+	// balance at From
+	OpeningBalance int64 `json:"opening_balance"`
+	// balance at To
+	ClosingBalance int64       `json:"closing_balance"`
+	Entries        []EntryLine `json:"entries"`
+}
+
+// Lists a page of an account's entries ordered by id, with the running balance after each one.
+// All reads happen in one repeatable read snapshot so the balances add up even while money moves
+func (store *SQLStore) ListAccountEntriesTx(ctx context.Context, arg ListAccountEntriesTxParams) (ListAccountEntriesTxResult, error) {
+	var result ListAccountEntriesTxResult
+
+	opts := &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}
+	err := store.execTxOptions(ctx, opts, func(q *Queries) error {
+		var err error
+		result.Account, err = q.GetAccount(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		// balances in the past are the current one minus everything booked since
+		sinceFrom, err := q.SumEntriesSince(ctx, SumEntriesSinceParams{
+			AccountID: arg.AccountID,
+			Since:     arg.From,
+		})
+		if err != nil {
+			return err
+		}
+		result.OpeningBalance = result.Account.Balance - sinceFrom
+
+		sinceTo, err := q.SumEntriesSince(ctx, SumEntriesSinceParams{
+			AccountID: arg.AccountID,
+			Since:     arg.To,
+		})
+		if err != nil {
+			return err
+		}
+		result.ClosingBalance = result.Account.Balance - sinceTo
+
+		entries, err := q.ListAccountEntries(ctx, ListAccountEntriesParams{
+			AccountID:  arg.AccountID,
+			FromTime:   arg.From,
+			ToTime:     arg.To,
+			AfterID:    arg.AfterID,
+			LimitCount: arg.Limit,
+		})
+		if err != nil || len(entries) == 0 {
+			result.Entries = []EntryLine{}
+			return err
+		}
+
+		fromFirst, err := q.SumEntriesFromID(ctx, SumEntriesFromIDParams{
+			AccountID: arg.AccountID,
+			FromID:    entries[0].ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		balance := result.Account.Balance - fromFirst
+		result.Entries = make([]EntryLine, len(entries))
+		for i, entry := range entries {
+			balance += entry.Amount
+			result.Entries[i] = EntryLine{Entry: entry, Balance: balance}
+		}
+		return nil
+	})
+
+	return result, err
+}
+
+// Contains input parameters of the statement transaction
+type StatementTxParams struct {
+	AccountID int64
+	// entries created in [From, To) are on the statement
+	From time.Time
+	To   time.Time
+}
+
+// A statement line, with the other account of the transfer that booked it if any
+type StatementLine struct {
+	EntryID   int64     `json:"entry_id"`
+	CreatedAt time.Time `json:"created_at"`
+	Amount    int64     `json:"amount"`
+	// account balance right after the entry
+	Balance               int64 `json:"balance"`
+	TransferID            int64 `json:"transfer_id,omitempty"`
+	CounterpartyAccountID int64 `json:"counterparty_account_id,omitempty"`
+	// set for entries booked by a balance adjustment
+	AdjustmentReason string `json:"adjustment_reason,omitempty"`
+	// deposit or withdrawal, with the reference of the payment processor
+	MovementKind      string `json:"movement_kind,omitempty"`
+	ExternalReference string `json:"external_reference,omitempty"`
+}
+
+// Contains result of the statement transaction
+type StatementTxResult struct {
+	Account        Account         `json:"account"`
+	OwnerFullName  string          `json:"owner_full_name"`
+	From           time.Time       `json:"from"`
+	To             time.Time       `json:"to"`
+	OpeningBalance int64           `json:"opening_balance"`
+	ClosingBalance int64           `json:"closing_balance"`
+	Lines          []StatementLine `json:"lines"`
+}
+
+// Gathers everything on an account statement for a period from one repeatable read snapshot
+func (store *SQLStore) StatementTx(ctx context.Context, arg StatementTxParams) (StatementTxResult, error) {
+	result := StatementTxResult{From: arg.From, To: arg.To}
+
+	opts := &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}
+	err := store.execTxOptions(ctx, opts, func(q *Queries) error {
+		var err error
+		result.Account, err = q.GetAccount(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		owner, err := q.GetUser(ctx, result.Account.Owner)
+		if err != nil {
+			return err
+		}
+		result.OwnerFullName = owner.FullName
+
+		sinceFrom, err := q.SumEntriesSince(ctx, SumEntriesSinceParams{
+			AccountID: arg.AccountID,
+			Since:     arg.From,
+		})
+		if err != nil {
+			return err
+		}
+		result.OpeningBalance = result.Account.Balance - sinceFrom
+
+		rows, err := q.ListStatementEntries(ctx, ListStatementEntriesParams{
+			AccountID: arg.AccountID,
+			FromTime:  arg.From,
+			ToTime:    arg.To,
+		})
+		if err != nil {
+			return err
+		}
+
+		balance := result.OpeningBalance
+		result.Lines = make([]StatementLine, len(rows))
+		for i, row := range rows {
+			balance += row.Amount
+			result.Lines[i] = StatementLine{
+				EntryID:           row.ID,
+				CreatedAt:         row.CreatedAt,
+				Amount:            row.Amount,
+				Balance:           balance,
+				TransferID:        row.TransferID.Int64,
+				AdjustmentReason:  row.AdjustmentReason.String,
+				MovementKind:      row.MovementKind.String,
+				ExternalReference: row.ExternalReference.String,
+			}
+			// the counterparty is whichever side of the transfer is not this account
+			if row.FromAccountID.Int64 == arg.AccountID {
+				result.Lines[i].CounterpartyAccountID = row.ToAccountID.Int64
+			} else {
+				result.Lines[i].CounterpartyAccountID = row.FromAccountID.Int64
+			}
+		}
+		result.ClosingBalance = balance
+		return nil
+	})
+
+	return result, err
+}
@@ -0,0 +1,101 @@
+package zigibankgo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestListAccountEntriesTx(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+	admin := createRandomUser(t)
+
+	from := time.Now().Add(-time.Minute)
+	amounts := []int64{10, -4, 7}
+	for _, amount := range amounts {
+		_, err := store.AdjustBalanceTx(context.Background(), AdjustBalanceTxParams{
+			AccountID:  account.ID,
+			Amount:     amount,
+			Reason:     "statement test",
+			AdjustedBy: admin.Username,
+		})
+		require.NoError(t, err)
+	}
+	to := time.Now().Add(time.Minute)
+
+	arg := ListAccountEntriesTxParams{
+		AccountID: account.ID,
+		From:      from,
+		To:        to,
+		Limit:     2,
+	}
+
+	page1, err := store.ListAccountEntriesTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, account.Balance, page1.OpeningBalance)
+	require.Equal(t, account.Balance+13, page1.ClosingBalance)
+	require.Len(t, page1.Entries, 2)
+	require.Equal(t, account.Balance+10, page1.Entries[0].Balance)
+	require.Equal(t, account.Balance+6, page1.Entries[1].Balance)
+
+	arg.AfterID = page1.Entries[1].ID
+	page2, err := store.ListAccountEntriesTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, page2.Entries, 1)
+	require.Equal(t, page1.ClosingBalance, page2.Entries[0].Balance)
+
+	// nothing was booked before the range
+	arg.To = from
+	arg.From = from.Add(-time.Hour)
+	arg.AfterID = 0
+	before, err := store.ListAccountEntriesTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Empty(t, before.Entries)
+	require.Equal(t, account.Balance, before.ClosingBalance)
+}
+
+func TestStatementTx(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	from := time.Now().Add(-time.Minute)
+	amount := int64(1)
+
+	// the random balance may be zero
+	_, err := store.UpdateOverdraftLimit(context.Background(), UpdateOverdraftLimitParams{
+		ID:             account1.ID,
+		OverdraftLimit: amount,
+	})
+	require.NoError(t, err)
+
+	transfer, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        amount,
+	})
+	require.NoError(t, err)
+	require.Equal(t, transfer.Transfer.ID, transfer.FromEntry.TransferID.Int64)
+
+	result, err := store.StatementTx(context.Background(), StatementTxParams{
+		AccountID: account1.ID,
+		From:      from,
+		To:        time.Now().Add(time.Minute),
+	})
+	require.NoError(t, err)
+
+	owner, err := store.GetUser(context.Background(), account1.Owner)
+	require.NoError(t, err)
+	require.Equal(t, owner.FullName, result.OwnerFullName)
+	require.Equal(t, account1.Balance, result.OpeningBalance)
+	require.Equal(t, account1.Balance-amount, result.ClosingBalance)
+
+	require.Len(t, result.Lines, 1)
+	require.Equal(t, transfer.FromEntry.ID, result.Lines[0].EntryID)
+	require.Equal(t, transfer.Transfer.ID, result.Lines[0].TransferID)
+	require.Equal(t, account2.ID, result.Lines[0].CounterpartyAccountID)
+	require.Equal(t, result.ClosingBalance, result.Lines[0].Balance)
+}
@@ -17,6 +17,7 @@ func createRandomAccount(t *testing.T) Account {
 		Owner:    user.Username,
 		Balance:  util.RandomMoney(),
 		Currency: util.RandomCurrency(),
+		Kind:     util.CheckingAccount,
 	}
 
 	account, err := testQueries.CreateAccount(context.Background(), arg)
@@ -26,6 +27,7 @@ func createRandomAccount(t *testing.T) Account {
 	require.Equal(t, arg.Owner, account.Owner)
 	require.Equal(t, arg.Balance, account.Balance)
 	require.Equal(t, arg.Currency, account.Currency)
+	require.Equal(t, arg.Kind, account.Kind)
 
 	require.NotZero(t, account.ID)
 	require.NotZero(t, account.CreatedAt)
@@ -0,0 +1,176 @@
+package zigibankgo
+
+import (
+	"BankAppGo/db/util"
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+)
+
+// Contains input parameters of the balance adjustment transaction
+type AdjustBalanceTxParams struct {
+	AccountID int64 `json:"account_id"`
+	// positive credits the account, negative debits it
+	Amount int64  `json:"amount"`
+	Reason string `json:"reason"`
+	// admin who made the adjustment
+	AdjustedBy string `json:"adjusted_by"`
+}
+
+// Contains result of the balance adjustment transaction
+type AdjustBalanceTxResult struct {
+	Adjustment BalanceAdjustment `json:"adjustment"`
+	Entry      Entry             `json:"entry"`
+	Account    Account           `json:"account"`
+}
+
+// Corrects an account balance by posting a compensating entry against cash-in clearing,
+// recording who made the adjustment and why within a single database transaction
+func (store *SQLStore) AdjustBalanceTx(ctx context.Context, arg AdjustBalanceTxParams) (AdjustBalanceTxResult, error) {
+	var result AdjustBalanceTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		account, err := q.GetAccountForUpdate(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		_, result.Entry, err = postCash(ctx, q, util.AdjustmentJournal, account, arg.Amount)
+		if err != nil {
+			return err
+		}
+
+		result.Adjustment, err = q.CreateBalanceAdjustment(ctx, CreateBalanceAdjustmentParams{
+			AccountID:  arg.AccountID,
+			EntryID:    result.Entry.ID,
+			Amount:     arg.Amount,
+			Reason:     arg.Reason,
+			AdjustedBy: arg.AdjustedBy,
+		})
+		if err != nil {
+			return err
+		}
+		recordChange(ctx, AuditTargetAdjustment, strconv.FormatInt(result.Adjustment.ID, 10), nil, result.Adjustment)
+
+		result.Account, err = q.GetAccount(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		recordChange(ctx, AuditTargetAccount, strconv.FormatInt(account.ID, 10), account, result.Account)
+		return nil
+	})
+
+	return result, err
+}
+
+// Closes an active account with a zero balance and no pending holds. Its entries and transfers are kept,
+// but it no longer accepts debits or credits and its scheduled transfers are cancelled
+func (store *SQLStore) CloseAccountTx(ctx context.Context, accountID int64) (Account, error) {
+	var account Account
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		account, err = q.GetAccountForUpdate(ctx, accountID)
+		if err != nil {
+			return err
+		}
+
+		err = checkActive(account)
+		if err != nil {
+			return err
+		}
+
+		return closeAccount(ctx, q, &account, "closed by its owner")
+	})
+
+	return account, err
+}
+
+// Contains input parameters of the set account status transaction
+type SetAccountStatusTxParams struct {
+	AccountID int64  `json:"account_id"`
+	Status    string `json:"status"`
+	Reason    string `json:"reason"`
+}
+
+// Freezes, unfreezes or closes an account. Frozen accounts can be closed,
+// closed accounts never change status again
+func (store *SQLStore) SetAccountStatusTx(ctx context.Context, arg SetAccountStatusTxParams) (Account, error) {
+	var account Account
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		account, err = q.GetAccountForUpdate(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		if account.Status == util.AccountClosed {
+			return fmt.Errorf("%w: account %d", ErrAccountClosed, account.ID)
+		}
+		if account.Status == arg.Status {
+			return fmt.Errorf("%w: account %d is already %s", ErrInvalidStatusTransition, account.ID, arg.Status)
+		}
+
+		if arg.Status == util.AccountClosed {
+			return closeAccount(ctx, q, &account, arg.Reason)
+		}
+
+		before := account
+		account, err = q.UpdateAccountStatus(ctx, UpdateAccountStatusParams{
+			ID:           account.ID,
+			Status:       arg.Status,
+			StatusReason: sql.NullString{String: arg.Reason, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+
+		recordChange(ctx, AuditTargetAccount, strconv.FormatInt(account.ID, 10), before, account)
+		return nil
+	})
+
+	return account, err
+}
+
+// closes a locked account once nothing is left on it, updating account in place and
+// cancelling the schedules that pay from or into it
+func closeAccount(ctx context.Context, q *Queries, account *Account, reason string) error {
+	if account.Balance != 0 {
+		return fmt.Errorf("%w: account %d has balance %d", ErrAccountNotEmpty, account.ID, account.Balance)
+	}
+	if account.HeldAmount != 0 {
+		return fmt.Errorf("%w: account %d has %d held", ErrAccountNotEmpty, account.ID, account.HeldAmount)
+	}
+
+	closed, err := q.UpdateAccountStatus(ctx, UpdateAccountStatusParams{
+		ID:           account.ID,
+		Status:       util.AccountClosed,
+		StatusReason: sql.NullString{String: reason, Valid: true},
+	})
+	if err != nil {
+		return err
+	}
+
+	recordChange(ctx, AuditTargetAccount, strconv.FormatInt(account.ID, 10), *account, closed)
+	*account = closed
+
+	// a schedule paying from or into a closed account could never run again
+	schedules, err := q.ListOpenScheduledTransfersByAccount(ctx, account.ID)
+	if err != nil {
+		return err
+	}
+	for _, schedule := range schedules {
+		cancelled, err := q.UpdateScheduledTransfer(ctx, UpdateScheduledTransferParams{
+			ID:     schedule.ID,
+			Status: sql.NullString{String: util.ScheduleCancelled, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+		recordChange(ctx, AuditTargetScheduledTransfer, strconv.FormatInt(schedule.ID, 10), schedule, cancelled)
+	}
+	return nil
+}
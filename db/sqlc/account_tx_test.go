@@ -0,0 +1,161 @@
+package zigibankgo
+
+import (
+	"BankAppGo/db/util"
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdjustBalanceTx(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+	admin := createRandomUser(t)
+
+	arg := AdjustBalanceTxParams{
+		AccountID:  account.ID,
+		Amount:     10,
+		Reason:     "goodwill credit",
+		AdjustedBy: admin.Username,
+	}
+
+	result, err := store.AdjustBalanceTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	require.Equal(t, account.Balance+arg.Amount, result.Account.Balance)
+	require.Equal(t, account.ID, result.Entry.AccountID)
+	require.Equal(t, arg.Amount, result.Entry.Amount)
+	require.Equal(t, result.Entry.ID, result.Adjustment.EntryID)
+	require.Equal(t, arg.Reason, result.Adjustment.Reason)
+	require.Equal(t, admin.Username, result.Adjustment.AdjustedBy)
+
+	adjustments, err := store.ListBalanceAdjustments(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Len(t, adjustments, 1)
+	require.Equal(t, result.Adjustment.ID, adjustments[0].ID)
+
+	// debits may not go past the overdraft limit
+	arg.Amount = -(result.Account.Balance + 1)
+	_, err = store.AdjustBalanceTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+}
+
+func TestCloseAccountTx(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+	other := createRandomAccount(t)
+	admin := createRandomUser(t)
+
+	// empty the account first if it holds money
+	if account.Balance != 0 {
+		_, err := store.CloseAccountTx(context.Background(), account.ID)
+		require.ErrorIs(t, err, ErrAccountNotEmpty)
+
+		_, err = store.AdjustBalanceTx(context.Background(), AdjustBalanceTxParams{
+			AccountID:  account.ID,
+			Amount:     -account.Balance,
+			Reason:     "emptied before closing",
+			AdjustedBy: admin.Username,
+		})
+		require.NoError(t, err)
+	}
+
+	outgoing := createRandomScheduledTransfer(t, account, other, 10, util.DailyCadence, time.Now().Add(time.Hour), sql.NullTime{})
+	incoming := createRandomScheduledTransfer(t, other, account, 10, util.WeeklyCadence, time.Now().Add(time.Hour), sql.NullTime{})
+
+	closed, err := store.CloseAccountTx(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, util.AccountClosed, closed.Status)
+	require.True(t, closed.StatusChangedAt.Valid)
+	require.Zero(t, closed.Balance)
+
+	_, err = store.CloseAccountTx(context.Background(), account.ID)
+	require.ErrorIs(t, err, ErrAccountClosed)
+
+	// schedules paying from or into the closed account are cancelled with it
+	for _, schedule := range []ScheduledTransfer{outgoing, incoming} {
+		after, err := store.GetScheduledTransfer(context.Background(), schedule.ID)
+		require.NoError(t, err)
+		require.Equal(t, util.ScheduleCancelled, after.Status)
+	}
+
+	// the closed account keeps its history but no longer receives money
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: other.ID,
+		ToAccountID:   account.ID,
+		Amount:        1,
+	})
+	require.ErrorIs(t, err, ErrAccountClosed)
+
+	_, err = store.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+}
+
+func TestSetAccountStatusTx(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+	other := createRandomAccount(t)
+
+	frozen, err := store.SetAccountStatusTx(context.Background(), SetAccountStatusTxParams{
+		AccountID: account.ID,
+		Status:    util.AccountFrozen,
+		Reason:    "suspected fraud",
+	})
+	require.NoError(t, err)
+	require.Equal(t, util.AccountFrozen, frozen.Status)
+	require.Equal(t, "suspected fraud", frozen.StatusReason.String)
+	require.True(t, frozen.StatusChangedAt.Valid)
+
+	_, err = store.SetAccountStatusTx(context.Background(), SetAccountStatusTxParams{
+		AccountID: account.ID,
+		Status:    util.AccountFrozen,
+		Reason:    "again",
+	})
+	require.ErrorIs(t, err, ErrInvalidStatusTransition)
+
+	// a frozen account is neither debited nor credited
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account.ID,
+		ToAccountID:   other.ID,
+		Amount:        1,
+	})
+	require.ErrorIs(t, err, ErrAccountFrozen)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: other.ID,
+		ToAccountID:   account.ID,
+		Amount:        1,
+	})
+	require.ErrorIs(t, err, ErrAccountFrozen)
+
+	// nor closed by its owner
+	_, err = store.CloseAccountTx(context.Background(), account.ID)
+	require.ErrorIs(t, err, ErrAccountFrozen)
+
+	active, err := store.SetAccountStatusTx(context.Background(), SetAccountStatusTxParams{
+		AccountID: account.ID,
+		Status:    util.AccountActive,
+		Reason:    "cleared",
+	})
+	require.NoError(t, err)
+	require.Equal(t, util.AccountActive, active.Status)
+	require.Equal(t, account.Balance, active.Balance)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: other.ID,
+		ToAccountID:   account.ID,
+		Amount:        1,
+	})
+	require.NoError(t, err)
+
+	// the account still holds money
+	_, err = store.SetAccountStatusTx(context.Background(), SetAccountStatusTxParams{
+		AccountID: account.ID,
+		Status:    util.AccountClosed,
+		Reason:    "closed by the bank",
+	})
+	require.ErrorIs(t, err, ErrAccountNotEmpty)
+}
@@ -0,0 +1,167 @@
+package zigibankgo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Kinds of record named by audit events
+const (
+	AuditTargetAccount           = "account"
+	AuditTargetTransfer          = "transfer"
+	AuditTargetCashMovement      = "cash_movement"
+	AuditTargetUser              = "user"
+	AuditTargetSession           = "session"
+	AuditTargetRevocation        = "token_revocation"
+	AuditTargetAdjustment        = "balance_adjustment"
+	AuditTargetInterestRate      = "interest_rate"
+	AuditTargetTransferLimit     = "transfer_limit"
+	AuditTargetHold              = "hold"
+	AuditTargetReversal          = "transfer_reversal"
+	AuditTargetFeeSchedule       = "fee_schedule"
+	AuditTargetScheduledTransfer = "scheduled_transfer"
+)
+
+// Who makes a request and what it does. Attached to the context of a state-changing request,
+// every transaction run with that context writes an audit event before it commits
+type Audit struct {
+	// empty when nobody is authenticated
+	Actor     string
+	Action    string
+	ClientIP  string
+	RequestID string
+
+	// changes recorded by the running transaction
+	changes []auditChange
+}
+
+// one record changed by a transaction
+type auditChange struct {
+	targetType string
+	targetID   string
+	before     any
+	after      any
+}
+
+type auditKey struct{}
+
+// Returns a copy of ctx whose transactions are audited
+func WithAudit(ctx context.Context, audit *Audit) context.Context {
+	return context.WithValue(ctx, auditKey{}, audit)
+}
+
+// Returns the audit attached to ctx, nil when the request is not audited
+func AuditFromContext(ctx context.Context) *Audit {
+	audit, _ := ctx.Value(auditKey{}).(*Audit)
+	return audit
+}
+
+// describes a record changed by the transaction running with ctx, before is nil when the record was created
+func recordChange(ctx context.Context, targetType string, targetID string, before any, after any) {
+	audit := AuditFromContext(ctx)
+	if audit == nil {
+		return
+	}
+	audit.changes = append(audit.changes, auditChange{
+		targetType: targetType,
+		targetID:   targetID,
+		before:     before,
+		after:      after,
+	})
+}
+
+// writes an event per change recorded by the transaction, or a single event naming no record when the
+// transaction described none. Called by execTx before it commits so the events share its fate
+func writeAudit(ctx context.Context, q *Queries) error {
+	audit := AuditFromContext(ctx)
+	if audit == nil {
+		return nil
+	}
+	changes := audit.changes
+	audit.changes = nil
+
+	if len(changes) == 0 {
+		changes = []auditChange{{}}
+	}
+
+	for _, change := range changes {
+		before, err := json.Marshal(change.before)
+		if err != nil {
+			return err
+		}
+		after, err := json.Marshal(change.after)
+		if err != nil {
+			return err
+		}
+
+		_, err = q.CreateAuditEvent(ctx, CreateAuditEventParams{
+			Actor:      sql.NullString{String: audit.Actor, Valid: audit.Actor != ""},
+			Action:     audit.Action,
+			TargetType: sql.NullString{String: change.targetType, Valid: change.targetType != ""},
+			TargetID:   sql.NullString{String: change.targetID, Valid: change.targetID != ""},
+			Before:     before,
+			After:      after,
+			ClientIp:   audit.ClientIP,
+			RequestID:  audit.RequestID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// forgets the changes of a transaction that rolled back
+func discardAudit(ctx context.Context) {
+	if audit := AuditFromContext(ctx); audit != nil {
+		audit.changes = nil
+	}
+}
+
+// what audit events record of a user, the password hash is left out
+type auditedUser struct {
+	Username          string
+	FullName          string
+	Email             string
+	PasswordChangedAt time.Time
+	CreatedAt         time.Time
+	Role              string
+}
+
+func newAuditedUser(user User) auditedUser {
+	return auditedUser{
+		Username:          user.Username,
+		FullName:          user.FullName,
+		Email:             user.Email,
+		PasswordChangedAt: user.PasswordChangedAt,
+		CreatedAt:         user.CreatedAt,
+		Role:              user.Role,
+	}
+}
+
+// what audit events record of a session, the refresh token is left out
+type auditedSession struct {
+	ID        uuid.UUID
+	Username  string
+	UserAgent string
+	ClientIp  string
+	IsBlocked bool
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
+
+func newAuditedSession(session Session) auditedSession {
+	return auditedSession{
+		ID:        session.ID,
+		Username:  session.Username,
+		UserAgent: session.UserAgent,
+		ClientIp:  session.ClientIp,
+		IsBlocked: session.IsBlocked,
+		ExpiresAt: session.ExpiresAt,
+		CreatedAt: session.CreatedAt,
+	}
+}
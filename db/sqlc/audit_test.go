@@ -0,0 +1,155 @@
+package zigibankgo
+
+import (
+	"BankAppGo/db/util"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAudit(actor string) *Audit {
+	return &Audit{
+		Actor:     actor,
+		Action:    "POST /transfers",
+		ClientIP:  "127.0.0.1",
+		RequestID: uuid.NewString(),
+	}
+}
+
+// lists the events written for a request, newest first
+func listRequestAuditEvents(t *testing.T, audit *Audit) []AuditEvent {
+	events, err := testQueries.ListAuditEvents(context.Background(), ListAuditEventsParams{
+		Actor:      sql.NullString{String: audit.Actor, Valid: audit.Actor != ""},
+		LimitCount: 100,
+	})
+	require.NoError(t, err)
+
+	var matching []AuditEvent
+	for _, event := range events {
+		if event.RequestID == audit.RequestID {
+			matching = append(matching, event)
+		}
+	}
+	return matching
+}
+
+func TestAuditedTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createFundedAccount(t, util.USD)
+	account2 := createFundedAccount(t, util.USD)
+
+	audit := newTestAudit(account1.Owner)
+	ctx := WithAudit(context.Background(), audit)
+
+	result, err := store.TransferTx(ctx, TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	events := listRequestAuditEvents(t, audit)
+	require.Len(t, events, 1)
+
+	event := events[0]
+	require.Equal(t, audit.Actor, event.Actor.String)
+	require.Equal(t, audit.Action, event.Action)
+	require.Equal(t, audit.ClientIP, event.ClientIp)
+	require.Equal(t, AuditTargetTransfer, event.TargetType.String)
+	require.Equal(t, strconv.FormatInt(result.Transfer.ID, 10), event.TargetID.String)
+	require.JSONEq(t, "null", string(event.Before))
+
+	var after Transfer
+	require.NoError(t, json.Unmarshal(event.After, &after))
+	require.Equal(t, result.Transfer.ID, after.ID)
+	require.Equal(t, int64(10), after.Amount)
+
+	// a transfer that rolls back leaves no event behind
+	_, err = store.TransferTx(ctx, TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        1_000_000,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+	require.Len(t, listRequestAuditEvents(t, audit), 1)
+}
+
+func TestAuditedSetAccountStatusTx(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+	admin := createRandomUser(t)
+
+	audit := newTestAudit(admin.Username)
+	audit.Action = "POST /accounts/:id/status"
+
+	_, err := store.SetAccountStatusTx(WithAudit(context.Background(), audit), SetAccountStatusTxParams{
+		AccountID: account.ID,
+		Status:    util.AccountFrozen,
+		Reason:    "compliance review",
+	})
+	require.NoError(t, err)
+
+	events := listRequestAuditEvents(t, audit)
+	require.Len(t, events, 1)
+
+	var before, after Account
+	require.NoError(t, json.Unmarshal(events[0].Before, &before))
+	require.NoError(t, json.Unmarshal(events[0].After, &after))
+	require.Equal(t, util.AccountActive, before.Status)
+	require.Equal(t, util.AccountFrozen, after.Status)
+}
+
+func TestAuditedCreateUserTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	// nobody is authenticated when signing up
+	audit := newTestAudit("")
+	audit.Action = "POST /users"
+
+	user, err := store.CreateUserTx(WithAudit(context.Background(), audit), CreateUserParams{
+		Username:       util.RandomOwner(),
+		HashedPassword: "secret-hash",
+		FullName:       util.RandomOwner(),
+		Email:          util.RandomEmail(),
+	})
+	require.NoError(t, err)
+
+	events, err := testQueries.ListAuditEvents(context.Background(), ListAuditEventsParams{
+		TargetType: sql.NullString{String: AuditTargetUser, Valid: true},
+		TargetID:   sql.NullString{String: user.Username, Valid: true},
+		LimitCount: 10,
+	})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.False(t, events[0].Actor.Valid)
+	require.NotContains(t, string(events[0].After), "secret-hash")
+}
+
+func TestAuditEventsAppendOnly(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+
+	audit := newTestAudit(account.Owner)
+	audit.Action = "POST /users/logout"
+
+	_, err := store.LogoutTx(WithAudit(context.Background(), audit), LogoutTxParams{
+		Username:             account.Owner,
+		AccessTokenID:        uuid.New(),
+		AccessTokenExpiresAt: account.CreatedAt.AddDate(1, 0, 0),
+	})
+	require.NoError(t, err)
+
+	events := listRequestAuditEvents(t, audit)
+	require.Len(t, events, 1)
+
+	_, err = testDB.Exec("UPDATE audit_events SET actor = 'mallory' WHERE id = $1", events[0].ID)
+	require.Error(t, err)
+
+	_, err = testDB.Exec("DELETE FROM audit_events WHERE id = $1", events[0].ID)
+	require.Error(t, err)
+}
@@ -0,0 +1,113 @@
+package zigibankgo
+
+import (
+	"BankAppGo/db/util"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// a reference can only be retried for the same account and amount
+var ErrExternalReferenceReused = errors.New("external reference was used for a different movement")
+
+// Contains input parameters of the deposit and withdrawal transactions
+type CashTxParams struct {
+	AccountID int64 `json:"account_id"`
+	Amount    int64 `json:"amount"`
+	// id given by the payment processor, retries with the same reference return the first movement
+	ExternalReference string `json:"external_reference"`
+	// user who requested the movement
+	CreatedBy string `json:"created_by"`
+}
+
+// Contains result of the deposit and withdrawal transactions
+type CashTxResult struct {
+	Movement CashMovement `json:"movement"`
+	Entry    Entry        `json:"entry"`
+	Account  Account      `json:"account"`
+}
+
+// Pays money into an account from outside the bank, against the cash-in clearing account
+func (store *SQLStore) DepositTx(ctx context.Context, arg CashTxParams) (CashTxResult, error) {
+	return store.cashTx(ctx, util.DepositJournal, arg.Amount, arg)
+}
+
+// Pays money out of an account to outside the bank, against the cash-in clearing account
+func (store *SQLStore) WithdrawTx(ctx context.Context, arg CashTxParams) (CashTxResult, error) {
+	return store.cashTx(ctx, util.WithdrawalJournal, -arg.Amount, arg)
+}
+
+// books a movement once per external reference, crediting amount to the account or debiting it when negative
+func (store *SQLStore) cashTx(ctx context.Context, kind string, amount int64, arg CashTxParams) (CashTxResult, error) {
+	var result CashTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		// a concurrent movement with the same reference makes the insert wait until it commits
+		movement, err := q.CreateCashMovement(ctx, CreateCashMovementParams{
+			Kind:              kind,
+			AccountID:         arg.AccountID,
+			Amount:            arg.Amount,
+			ExternalReference: arg.ExternalReference,
+			CreatedBy:         arg.CreatedBy,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			result, err = replayCashMovement(ctx, q, kind, arg)
+			return err
+		}
+		if err != nil {
+			return err
+		}
+
+		account, err := q.GetAccountForUpdate(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		_, result.Entry, err = postCash(ctx, q, kind, account, amount)
+		if err != nil {
+			return err
+		}
+
+		result.Movement, err = q.SetCashMovementEntry(ctx, SetCashMovementEntryParams{
+			ID:      movement.ID,
+			EntryID: sql.NullInt64{Int64: result.Entry.ID, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+		recordChange(ctx, AuditTargetCashMovement, strconv.FormatInt(movement.ID, 10), nil, result.Movement)
+
+		result.Account, err = q.GetAccount(ctx, arg.AccountID)
+		return err
+	})
+
+	return result, err
+}
+
+// loads the movement already booked for the reference of arg
+func replayCashMovement(ctx context.Context, q *Queries, kind string, arg CashTxParams) (CashTxResult, error) {
+	var result CashTxResult
+
+	movement, err := q.GetCashMovementByReference(ctx, GetCashMovementByReferenceParams{
+		Kind:              kind,
+		ExternalReference: arg.ExternalReference,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	if movement.AccountID != arg.AccountID || movement.Amount != arg.Amount {
+		return result, fmt.Errorf("%w: %s %q booked %d on account %d", ErrExternalReferenceReused, kind, arg.ExternalReference, movement.Amount, movement.AccountID)
+	}
+	result.Movement = movement
+
+	result.Entry, err = q.GetEntryFromId(ctx, movement.EntryID.Int64)
+	if err != nil {
+		return result, err
+	}
+
+	result.Account, err = q.GetAccount(ctx, movement.AccountID)
+	return result, err
+}
@@ -0,0 +1,71 @@
+package zigibankgo
+
+import (
+	"BankAppGo/db/util"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDepositTx(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+
+	clearing, err := systemAccount(context.Background(), testQueries, util.CashClearingAccount, account.Currency)
+	require.NoError(t, err)
+
+	arg := CashTxParams{
+		AccountID:         account.ID,
+		Amount:            40,
+		ExternalReference: util.RandomString(12),
+		CreatedBy:         account.Owner,
+	}
+
+	result, err := store.DepositTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, util.DepositJournal, result.Movement.Kind)
+	require.Equal(t, result.Entry.ID, result.Movement.EntryID.Int64)
+	require.Equal(t, int64(40), result.Entry.Amount)
+	require.True(t, result.Entry.JournalID.Valid)
+	require.Equal(t, account.Balance+40, result.Account.Balance)
+
+	// a retry with the same reference books nothing more
+	replayed, err := store.DepositTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, result.Movement.ID, replayed.Movement.ID)
+	require.Equal(t, result.Entry.ID, replayed.Entry.ID)
+	require.Equal(t, account.Balance+40, replayed.Account.Balance)
+
+	arg.Amount = 50
+	_, err = store.DepositTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrExternalReferenceReused)
+
+	// the money came from the clearing account of the same currency
+	after, err := store.GetAccount(context.Background(), clearing.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(-40), after.Balance-clearing.Balance)
+}
+
+func TestWithdrawTx(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+
+	arg := CashTxParams{
+		AccountID:         account.ID,
+		Amount:            account.Balance + 1,
+		ExternalReference: util.RandomString(12),
+		CreatedBy:         account.Owner,
+	}
+
+	_, err := store.WithdrawTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	// the failed withdrawal left its reference free
+	arg.Amount = account.Balance
+	result, err := store.WithdrawTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, util.WithdrawalJournal, result.Movement.Kind)
+	require.Equal(t, -account.Balance, result.Entry.Amount)
+	require.Zero(t, result.Account.Balance)
+}
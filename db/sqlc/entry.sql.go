@@ -7,36 +7,57 @@ package zigibankgo
 
 import (
 	"context"
+	"database/sql"
+	"time"
 )
 
 const createEntry = `-- name: CreateEntry :one
 INSERT INTO entries (
-  account_id, amount
+  id, account_id, amount, transfer_id, journal_id, created_at, prev_hash, hash
 ) VALUES (
-  $1, $2
+  $1, $2, $3, $4, $5, $6, $7, $8
 )
-RETURNING id, account_id, amount, created_at
+RETURNING id, account_id, amount, created_at, transfer_id, journal_id, prev_hash, hash
 `
 
 type CreateEntryParams struct {
-	AccountID int64
-	Amount    int64
+	ID         int64
+	AccountID  int64
+	Amount     int64
+	TransferID sql.NullInt64
+	JournalID  sql.NullInt64
+	CreatedAt  time.Time
+	PrevHash   []byte
+	Hash       []byte
 }
 
 func (q *Queries) CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error) {
-	row := q.db.QueryRowContext(ctx, createEntry, arg.AccountID, arg.Amount)
+	row := q.db.QueryRowContext(ctx, createEntry,
+		arg.ID,
+		arg.AccountID,
+		arg.Amount,
+		arg.TransferID,
+		arg.JournalID,
+		arg.CreatedAt,
+		arg.PrevHash,
+		arg.Hash,
+	)
 	var i Entry
 	err := row.Scan(
 		&i.ID,
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.TransferID,
+		&i.JournalID,
+		&i.PrevHash,
+		&i.Hash,
 	)
 	return i, err
 }
 
 const getEntry = `-- name: GetEntry :one
-SELECT id, account_id, amount, created_at FROM entries
+SELECT id, account_id, amount, created_at, transfer_id, journal_id, prev_hash, hash FROM entries
 WHERE account_id = $1
 LIMIT 1
 `
@@ -49,12 +70,163 @@ func (q *Queries) GetEntry(ctx context.Context, accountID int64) (Entry, error)
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.TransferID,
+		&i.JournalID,
+		&i.PrevHash,
+		&i.Hash,
+	)
+	return i, err
+}
+
+const getEntryFromId = `-- name: GetEntryFromId :one
+SELECT id, account_id, amount, created_at, transfer_id, journal_id, prev_hash, hash FROM entries
+WHERE id = $1
+`
+
+func (q *Queries) GetEntryFromId(ctx context.Context, id int64) (Entry, error) {
+	row := q.db.QueryRowContext(ctx, getEntryFromId, id)
+	var i Entry
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.TransferID,
+		&i.JournalID,
+		&i.PrevHash,
+		&i.Hash,
+	)
+	return i, err
+}
+
+const getLastAccountEntry = `-- name: GetLastAccountEntry :one
+SELECT id, account_id, amount, created_at, transfer_id, journal_id, prev_hash, hash FROM entries
+WHERE account_id = $1
+ORDER BY id DESC
+LIMIT 1
+`
+
+// the entry the next one of the account chains onto
+func (q *Queries) GetLastAccountEntry(ctx context.Context, accountID int64) (Entry, error) {
+	row := q.db.QueryRowContext(ctx, getLastAccountEntry, accountID)
+	var i Entry
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.TransferID,
+		&i.JournalID,
+		&i.PrevHash,
+		&i.Hash,
 	)
 	return i, err
 }
 
+const listAccountEntries = `-- name: ListAccountEntries :many
+SELECT id, account_id, amount, created_at, transfer_id, journal_id, prev_hash, hash FROM entries
+WHERE account_id = $1
+  AND created_at >= $2
+  AND created_at < $3
+  AND id > $4
+ORDER BY id
+LIMIT $5
+`
+
+type ListAccountEntriesParams struct {
+	AccountID  int64
+	FromTime   time.Time
+	ToTime     time.Time
+	AfterID    int64
+	LimitCount int64
+}
+
+func (q *Queries) ListAccountEntries(ctx context.Context, arg ListAccountEntriesParams) ([]Entry, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountEntries,
+		arg.AccountID,
+		arg.FromTime,
+		arg.ToTime,
+		arg.AfterID,
+		arg.LimitCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Entry{}
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+			&i.JournalID,
+			&i.PrevHash,
+			&i.Hash,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listChainEntries = `-- name: ListChainEntries :many
+SELECT id, account_id, amount, created_at, transfer_id, journal_id, prev_hash, hash FROM entries
+WHERE (account_id, id) > ($1::bigint, $2::bigint)
+ORDER BY account_id, id
+LIMIT $3
+`
+
+type ListChainEntriesParams struct {
+	AfterAccountID int64
+	AfterID        int64
+	LimitCount     int64
+}
+
+// walks the entries account by account, in the order they were chained
+func (q *Queries) ListChainEntries(ctx context.Context, arg ListChainEntriesParams) ([]Entry, error) {
+	rows, err := q.db.QueryContext(ctx, listChainEntries, arg.AfterAccountID, arg.AfterID, arg.LimitCount)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Entry{}
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+			&i.JournalID,
+			&i.PrevHash,
+			&i.Hash,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listEntries = `-- name: ListEntries :many
-SELECT id, account_id, amount, created_at FROM entries
+SELECT id, account_id, amount, created_at, transfer_id, journal_id, prev_hash, hash FROM entries
 `
 
 func (q *Queries) ListEntries(ctx context.Context) ([]Entry, error) {
@@ -71,6 +243,78 @@ func (q *Queries) ListEntries(ctx context.Context) ([]Entry, error) {
 			&i.AccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.TransferID,
+			&i.JournalID,
+			&i.PrevHash,
+			&i.Hash,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listStatementEntries = `-- name: ListStatementEntries :many
+SELECT e.id, e.account_id, e.amount, e.created_at, e.transfer_id,
+       t.from_account_id, t.to_account_id,
+       ba.reason AS adjustment_reason,
+       cm.kind AS movement_kind, cm.external_reference
+FROM entries e
+LEFT JOIN transfers t ON t.id = e.transfer_id
+LEFT JOIN balance_adjustments ba ON ba.entry_id = e.id
+LEFT JOIN cash_movements cm ON cm.entry_id = e.id
+WHERE e.account_id = $1
+  AND e.created_at >= $2
+  AND e.created_at < $3
+ORDER BY e.id
+`
+
+type ListStatementEntriesParams struct {
+	AccountID int64
+	FromTime  time.Time
+	ToTime    time.Time
+}
+
+type ListStatementEntriesRow struct {
+	ID                int64
+	AccountID         int64
+	Amount            int64
+	CreatedAt         time.Time
+	TransferID        sql.NullInt64
+	FromAccountID     sql.NullInt64
+	ToAccountID       sql.NullInt64
+	AdjustmentReason  sql.NullString
+	MovementKind      sql.NullString
+	ExternalReference sql.NullString
+}
+
+func (q *Queries) ListStatementEntries(ctx context.Context, arg ListStatementEntriesParams) ([]ListStatementEntriesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listStatementEntries, arg.AccountID, arg.FromTime, arg.ToTime)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListStatementEntriesRow{}
+	for rows.Next() {
+		var i ListStatementEntriesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.AdjustmentReason,
+			&i.MovementKind,
+			&i.ExternalReference,
 		); err != nil {
 			return nil, err
 		}
@@ -84,3 +328,53 @@ func (q *Queries) ListEntries(ctx context.Context) ([]Entry, error) {
 	}
 	return items, nil
 }
+
+const nextEntryID = `-- name: NextEntryID :one
+SELECT nextval(pg_get_serial_sequence('entries', 'id'))::bigint AS id
+`
+
+// reserves the id of an entry, which is part of its hash
+func (q *Queries) NextEntryID(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, nextEntryID)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
+const sumEntriesFromID = `-- name: SumEntriesFromID :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS total
+FROM entries
+WHERE account_id = $1
+  AND id >= $2
+`
+
+type SumEntriesFromIDParams struct {
+	AccountID int64
+	FromID    int64
+}
+
+func (q *Queries) SumEntriesFromID(ctx context.Context, arg SumEntriesFromIDParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, sumEntriesFromID, arg.AccountID, arg.FromID)
+	var total int64
+	err := row.Scan(&total)
+	return total, err
+}
+
+const sumEntriesSince = `-- name: SumEntriesSince :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS total
+FROM entries
+WHERE account_id = $1
+  AND created_at >= $2
+`
+
+type SumEntriesSinceParams struct {
+	AccountID int64
+	Since     time.Time
+}
+
+func (q *Queries) SumEntriesSince(ctx context.Context, arg SumEntriesSinceParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, sumEntriesSince, arg.AccountID, arg.Since)
+	var total int64
+	err := row.Scan(&total)
+	return total, err
+}
@@ -0,0 +1,76 @@
+package zigibankgo
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/binary"
+	"errors"
+	"time"
+)
+
+// Hashes the contents of an entry together with the hash of the previous entry of its account.
+// Must stay in step with the backfill of migration 000022
+func EntryHash(entry Entry) []byte {
+	var buf bytes.Buffer
+	writeInt64(&buf, entry.ID)
+	writeInt64(&buf, entry.AccountID)
+	writeInt64(&buf, entry.Amount)
+	writeInt64(&buf, entry.CreatedAt.UnixMicro())
+	writeNullInt64(&buf, entry.TransferID)
+	writeNullInt64(&buf, entry.JournalID)
+	buf.Write(entry.PrevHash)
+
+	sum := sha256.Sum256(buf.Bytes())
+	return sum[:]
+}
+
+func writeInt64(buf *bytes.Buffer, v int64) {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], uint64(v))
+	buf.Write(b[:])
+}
+
+func writeNullInt64(buf *bytes.Buffer, v sql.NullInt64) {
+	if !v.Valid {
+		buf.WriteByte(0)
+		return
+	}
+	buf.WriteByte(1)
+	writeInt64(buf, v.Int64)
+}
+
+// Books an entry chained onto the last one of its account.
+// The account must be locked so no other entry can chain onto the same one
+func createChainedEntry(ctx context.Context, q *Queries, entry Entry) (Entry, error) {
+	last, err := q.GetLastAccountEntry(ctx, entry.AccountID)
+	switch {
+	case err == nil:
+		entry.PrevHash = last.Hash
+	case errors.Is(err, sql.ErrNoRows):
+		entry.PrevHash = nil
+	default:
+		return Entry{}, err
+	}
+
+	entry.ID, err = q.NextEntryID(ctx)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	// postgres keeps microseconds, the hash must see the time as it is stored
+	entry.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+	entry.Hash = EntryHash(entry)
+
+	return q.CreateEntry(ctx, CreateEntryParams{
+		ID:         entry.ID,
+		AccountID:  entry.AccountID,
+		Amount:     entry.Amount,
+		TransferID: entry.TransferID,
+		JournalID:  entry.JournalID,
+		CreatedAt:  entry.CreatedAt,
+		PrevHash:   entry.PrevHash,
+		Hash:       entry.Hash,
+	})
+}
@@ -0,0 +1,52 @@
+package zigibankgo
+
+import (
+	"BankAppGo/db/util"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransferTxChainsEntries(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createFundedAccount(t, util.USD)
+	account2 := createFundedAccount(t, util.USD)
+
+	arg := TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	}
+
+	first, err := store.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	// the first entry of an account starts its chain
+	require.Nil(t, first.FromEntry.PrevHash)
+	require.Nil(t, first.ToEntry.PrevHash)
+	require.Equal(t, EntryHash(first.FromEntry), first.FromEntry.Hash)
+
+	second, err := store.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, first.FromEntry.Hash, second.FromEntry.PrevHash)
+	require.Equal(t, first.ToEntry.Hash, second.ToEntry.PrevHash)
+
+	last, err := store.GetLastAccountEntry(context.Background(), account2.ID)
+	require.NoError(t, err)
+	require.Equal(t, second.ToEntry.ID, last.ID)
+
+	// the stored row hashes the same as the one returned
+	require.Equal(t, second.ToEntry.Hash, last.Hash)
+	require.Equal(t, EntryHash(last), last.Hash)
+
+	entries, err := store.ListChainEntries(context.Background(), ListChainEntriesParams{
+		AfterAccountID: account1.ID,
+		AfterID:        0,
+		LimitCount:     2,
+	})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, first.FromEntry.ID, entries[0].ID)
+	require.Equal(t, second.FromEntry.ID, entries[1].ID)
+}
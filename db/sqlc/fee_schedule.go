@@ -0,0 +1,85 @@
+package zigibankgo
+
+import (
+	"BankAppGo/fees"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// the fee of a transfer must leave something to send
+var ErrAmountBelowFee = errors.New("amount does not cover the transfer fee")
+
+// Schedule returns the fee schedule stored in the row
+func (schedule FeeSchedule) Schedule() (fees.Schedule, error) {
+	result := fees.Schedule{
+		Kind:    schedule.Kind,
+		Flat:    schedule.FlatFee,
+		RateBps: schedule.RateBps,
+		Min:     schedule.MinFee,
+		Max:     schedule.MaxFee,
+	}
+	if len(schedule.Tiers) != 0 {
+		err := json.Unmarshal(schedule.Tiers, &result.Tiers)
+		if err != nil {
+			return result, fmt.Errorf("cannot read tiers of fee schedule %d: %w", schedule.ID, err)
+		}
+	}
+	return result, nil
+}
+
+// TransferFee returns the fee charged on a transfer of amount under schedule,
+// which must be smaller than the amount
+func TransferFee(schedule FeeSchedule, amount int64) (int64, error) {
+	s, err := schedule.Schedule()
+	if err != nil {
+		return 0, err
+	}
+
+	fee, err := s.Fee(amount)
+	if err != nil {
+		return 0, err
+	}
+	if fee >= amount {
+		return 0, fmt.Errorf("%w: fee is %d %s, amount is %d", ErrAmountBelowFee, fee, schedule.Currency, amount)
+	}
+	return fee, nil
+}
+
+// returns the fee charged on a transfer of amount from an account, zero when its currency has no schedule
+func transferFee(ctx context.Context, q *Queries, fromAccountID int64, amount int64) (int64, error) {
+	account, err := q.GetAccount(ctx, fromAccountID)
+	if err != nil {
+		return 0, err
+	}
+
+	schedule, err := q.GetFeeSchedule(ctx, account.Currency)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return TransferFee(schedule, amount)
+}
+
+// Creates a fee schedule within a database transaction, it replaces the one in force for its currency
+func (store *SQLStore) CreateFeeScheduleTx(ctx context.Context, arg CreateFeeScheduleParams) (FeeSchedule, error) {
+	var schedule FeeSchedule
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		schedule, err = q.CreateFeeSchedule(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		recordChange(ctx, AuditTargetFeeSchedule, strconv.FormatInt(schedule.ID, 10), nil, schedule)
+		return nil
+	})
+
+	return schedule, err
+}
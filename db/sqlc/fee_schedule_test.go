@@ -0,0 +1,159 @@
+package zigibankgo
+
+import (
+	"BankAppGo/db/util"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// sets a fee schedule for currency until the test ends
+func setFeeSchedule(t *testing.T, arg CreateFeeScheduleParams) FeeSchedule {
+	schedule, err := testQueries.CreateFeeSchedule(context.Background(), arg)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_, err := testDB.Exec("DELETE FROM fee_schedules WHERE id = $1", schedule.ID)
+		require.NoError(t, err)
+	})
+	return schedule
+}
+
+func createFundedAccount(t *testing.T, currency string) Account {
+	owner := createRandomUser(t)
+	account, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    owner.Username,
+		Balance:  1000,
+		Currency: currency,
+		Kind:     util.CheckingAccount,
+	})
+	require.NoError(t, err)
+	return account
+}
+
+func TestTransferTxFee(t *testing.T) {
+	store := NewStore(testDB)
+	admin := createRandomUser(t)
+	account1 := createFundedAccount(t, util.USD)
+	account2 := createFundedAccount(t, util.USD)
+
+	setFeeSchedule(t, CreateFeeScheduleParams{
+		Currency:  util.USD,
+		Kind:      "percentage",
+		RateBps:   1000,
+		Tiers:     json.RawMessage("[]"),
+		MinFee:    5,
+		CreatedBy: admin.Username,
+	})
+
+	feesIncome, err := systemAccount(context.Background(), testQueries, util.FeesIncomeAccount, util.USD)
+	require.NoError(t, err)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        100,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(10), result.Fee)
+	require.Equal(t, int64(10), result.Transfer.Fee)
+	require.Equal(t, int64(90), result.Transfer.ToAmount)
+	require.Equal(t, int64(-100), result.FromEntry.Amount)
+	require.Equal(t, int64(90), result.ToEntry.Amount)
+	require.Equal(t, account1.Balance-100, result.FromAccount.Balance)
+	require.Equal(t, account2.Balance+90, result.ToAccount.Balance)
+
+	after, err := store.GetAccount(context.Background(), feesIncome.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(10), after.Balance-feesIncome.Balance)
+
+	// the minimum fee would take everything
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        5,
+	})
+	require.ErrorIs(t, err, ErrAmountBelowFee)
+
+	// a reversal sends back what was credited, the fee is kept
+	reversal, err := store.ReverseTransferTx(context.Background(), TransferReversalTxParams{
+		TransferID:  result.Transfer.ID,
+		Reason:      "sent by mistake",
+		RequestedBy: admin.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(90), reversal.Transfer.Amount)
+	require.Equal(t, int64(90), reversal.Transfer.ToAmount)
+	require.Zero(t, reversal.Fee)
+	require.Equal(t, account1.Balance-10, reversal.ToAccount.Balance)
+	require.Equal(t, account2.Balance, reversal.FromAccount.Balance)
+}
+
+// a captured hold pays the fee as a transfer of the captured amount would
+func TestCaptureHoldTxFee(t *testing.T) {
+	store := NewStore(testDB)
+	admin := createRandomUser(t)
+	account1 := createFundedAccount(t, util.USD)
+	account2 := createFundedAccount(t, util.USD)
+
+	setFeeSchedule(t, CreateFeeScheduleParams{
+		Currency:  util.USD,
+		Kind:      "flat",
+		FlatFee:   15,
+		Tiers:     json.RawMessage("[]"),
+		CreatedBy: admin.Username,
+	})
+
+	hold, err := store.AuthorizeTransferTx(context.Background(), AuthorizeTransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        100,
+		ExpiresAt:     time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	// the fee would take everything
+	_, err = store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{HoldID: hold.ID, Amount: 15})
+	require.ErrorIs(t, err, ErrAmountBelowFee)
+
+	result, err := store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{HoldID: hold.ID, Amount: 60})
+	require.NoError(t, err)
+	require.Equal(t, int64(15), result.Fee)
+	require.Equal(t, int64(60), result.Transfer.Amount)
+	require.Equal(t, int64(45), result.Transfer.ToAmount)
+	require.Equal(t, account1.Balance-60, result.FromAccount.Balance)
+	require.Zero(t, result.FromAccount.HeldAmount)
+	require.Equal(t, account2.Balance+45, result.ToAccount.Balance)
+}
+
+func TestExchangeTransferTxFee(t *testing.T) {
+	store := NewStore(testDB)
+	admin := createRandomUser(t)
+	account1 := createFundedAccount(t, util.USD)
+	account2 := createFundedAccount(t, util.EUR)
+
+	setFeeSchedule(t, CreateFeeScheduleParams{
+		Currency:  util.USD,
+		Kind:      "flat",
+		FlatFee:   20,
+		Tiers:     json.RawMessage("[]"),
+		CreatedBy: admin.Username,
+	})
+
+	// the fee is taken before the rest is converted
+	result, err := store.ExchangeTransferTx(context.Background(), ExchangeTransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        100,
+		ToAmount:      50,
+		ExchangeRate:  "0.5",
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(20), result.Fee)
+	require.Equal(t, int64(40), result.Transfer.ToAmount)
+	require.Equal(t, account1.Balance-100, result.FromAccount.Balance)
+	require.Equal(t, account2.Balance+40, result.ToAccount.Balance)
+}
@@ -0,0 +1,250 @@
+package zigibankgo
+
+import (
+	"BankAppGo/db/util"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Different types of error returned when a hold cannot be settled
+var (
+	// the hold was already captured, voided or expired
+	ErrHoldNotPending = errors.New("hold is not pending")
+	// the hold outlived its expiry and can only be released
+	ErrHoldExpired = errors.New("hold has expired")
+	// a capture cannot move more than the hold reserved
+	ErrCaptureExceedsHold = errors.New("capture exceeds the held amount")
+)
+
+// Contains input parameters of the authorize transfer transaction
+type AuthorizeTransferTxParams struct {
+	FromAccountID int64     `json:"from_account_id"`
+	ToAccountID   int64     `json:"to_account_id"`
+	Amount        int64     `json:"amount"`
+	ExpiresAt     time.Time `json:"expires_at"`
+	// optional, the limits of the sender when no override is set, checked again on capture
+	Limits *TransferLimits `json:"-"`
+	// optional, makes retries of the same request return the first result
+	Idempotency *Idempotency `json:"-"`
+}
+
+// Reserves money on the source account for a later capture. The hold lowers the
+// available balance of the account but writes no entry, so its ledger balance is unchanged
+func (store *SQLStore) AuthorizeTransferTx(ctx context.Context, arg AuthorizeTransferTxParams) (Hold, error) {
+	var hold Hold
+
+	err := store.execIdempotentTx(ctx, arg.Idempotency, &hold, func(q *Queries) error {
+		if arg.Limits != nil {
+			err := checkTransferLimits(ctx, q, arg.FromAccountID, arg.Amount, 0, *arg.Limits)
+			if err != nil {
+				return err
+			}
+		}
+
+		fromAccount, toAccount, err := lockAccounts(ctx, q, arg.FromAccountID, arg.ToAccountID)
+		if err != nil {
+			return err
+		}
+
+		err = checkActive(fromAccount)
+		if err != nil {
+			return err
+		}
+
+		err = checkActive(toAccount)
+		if err != nil {
+			return err
+		}
+
+		err = checkNotSystemAccount(ctx, q, toAccount.ID)
+		if err != nil {
+			return err
+		}
+
+		err = checkFunds(fromAccount, arg.Amount)
+		if err != nil {
+			return err
+		}
+
+		hold, err = q.CreateHold(ctx, CreateHoldParams{
+			FromAccountID: arg.FromAccountID,
+			ToAccountID:   arg.ToAccountID,
+			Amount:        arg.Amount,
+			ExpiresAt:     arg.ExpiresAt,
+		})
+		if err != nil {
+			return err
+		}
+		recordChange(ctx, AuditTargetHold, strconv.FormatInt(hold.ID, 10), nil, hold)
+
+		_, err = q.AddAccountHeldAmount(ctx, AddAccountHeldAmountParams{
+			ID:     arg.FromAccountID,
+			Amount: arg.Amount,
+		})
+		return err
+	})
+
+	return hold, err
+}
+
+// Contains input parameters of the capture hold transaction
+type CaptureHoldTxParams struct {
+	HoldID int64 `json:"hold_id"`
+	// taken from the source account, the whole hold when zero. The fee of the source
+	// currency is taken from it and the destination credited the rest.
+	// Whatever is left of the hold is released
+	Amount int64 `json:"amount"`
+	// optional, the limits of the sender when no override is set, checked as for any other transfer
+	Limits *TransferLimits `json:"-"`
+}
+
+// Contains result of the capture hold transaction
+type CaptureHoldTxResult struct {
+	Hold Hold `json:"hold"`
+	TransferTxResult
+}
+
+// Settles a pending hold with a transfer from the source to the destination account
+// and releases the reserved money, within a single database transaction.
+// The limits of the sender count the capture on the day it is made, not the day it was authorized
+func (store *SQLStore) CaptureHoldTx(ctx context.Context, arg CaptureHoldTxParams) (CaptureHoldTxResult, error) {
+	var result CaptureHoldTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		hold, err := lockPendingHold(ctx, q, arg.HoldID)
+		if err != nil {
+			return err
+		}
+
+		amount := arg.Amount
+		if amount == 0 {
+			amount = hold.Amount
+		}
+		if amount > hold.Amount {
+			return fmt.Errorf("%w: hold %d has %d, capture needs %d", ErrCaptureExceedsHold, hold.ID, hold.Amount, amount)
+		}
+
+		// the limits are locked before the accounts, as TransferTx locks them.
+		// The whole hold is released by the capture, so none of it counts as still reserved
+		if arg.Limits != nil {
+			err = checkTransferLimits(ctx, q, hold.FromAccountID, amount, hold.Amount, *arg.Limits)
+			if err != nil {
+				return err
+			}
+		}
+
+		// both accounts are locked before the source is touched, in the order transfer locks them
+		_, _, err = lockAccounts(ctx, q, hold.FromAccountID, hold.ToAccountID)
+		if err != nil {
+			return err
+		}
+
+		_, err = q.AddAccountHeldAmount(ctx, AddAccountHeldAmountParams{
+			ID:     hold.FromAccountID,
+			Amount: -hold.Amount,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.TransferTxResult, err = feeTransfer(ctx, q, hold.FromAccountID, hold.ToAccountID, amount, nil)
+		if err != nil {
+			return err
+		}
+
+		result.Hold, err = q.SettleHold(ctx, SettleHoldParams{
+			ID:         hold.ID,
+			Status:     util.HoldCaptured,
+			TransferID: sql.NullInt64{Int64: result.Transfer.ID, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+
+		recordChange(ctx, AuditTargetHold, strconv.FormatInt(hold.ID, 10), hold, result.Hold)
+		return nil
+	})
+
+	return result, err
+}
+
+// Releases a pending hold without moving any money
+func (store *SQLStore) VoidHoldTx(ctx context.Context, holdID int64) (Hold, error) {
+	var hold Hold
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		pending, err := lockPendingHold(ctx, q, holdID)
+		if err != nil {
+			return err
+		}
+
+		hold, err = releaseHold(ctx, q, pending, util.HoldVoided)
+		if err != nil {
+			return err
+		}
+
+		recordChange(ctx, AuditTargetHold, strconv.FormatInt(hold.ID, 10), pending, hold)
+		return nil
+	})
+
+	return hold, err
+}
+
+// Releases one pending hold that expired at now. Holds being settled elsewhere are
+// skipped, so several servers can expire holds at once. Returns sql.ErrNoRows when none is left
+func (store *SQLStore) ExpireHoldTx(ctx context.Context, now time.Time) (Hold, error) {
+	var hold Hold
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		pending, err := q.ClaimExpiredHold(ctx, now)
+		if err != nil {
+			return err
+		}
+
+		hold, err = releaseHold(ctx, q, pending, util.HoldExpired)
+		if err != nil {
+			return err
+		}
+
+		recordChange(ctx, AuditTargetHold, strconv.FormatInt(hold.ID, 10), pending, hold)
+		return nil
+	})
+
+	return hold, err
+}
+
+// locks a hold that can still be captured or voided
+func lockPendingHold(ctx context.Context, q *Queries, holdID int64) (Hold, error) {
+	hold, err := q.GetHoldForUpdate(ctx, holdID)
+	if err != nil {
+		return hold, err
+	}
+
+	if hold.Status != util.HoldPending {
+		return hold, fmt.Errorf("%w: hold %d is %s", ErrHoldNotPending, hold.ID, hold.Status)
+	}
+	if !hold.ExpiresAt.After(time.Now()) {
+		return hold, fmt.Errorf("%w: hold %d expired at %s", ErrHoldExpired, hold.ID, hold.ExpiresAt.Format(time.RFC3339))
+	}
+	return hold, nil
+}
+
+// gives the money reserved by a locked hold back to its source account
+func releaseHold(ctx context.Context, q *Queries, hold Hold, status string) (Hold, error) {
+	_, err := q.AddAccountHeldAmount(ctx, AddAccountHeldAmountParams{
+		ID:     hold.FromAccountID,
+		Amount: -hold.Amount,
+	})
+	if err != nil {
+		return hold, err
+	}
+
+	return q.SettleHold(ctx, SettleHoldParams{
+		ID:     hold.ID,
+		Status: status,
+	})
+}
@@ -0,0 +1,238 @@
+package zigibankgo
+
+import (
+	"BankAppGo/db/util"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCaptureHoldTx(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	_, err := store.UpdateOverdraftLimit(context.Background(), UpdateOverdraftLimitParams{
+		ID:             account1.ID,
+		OverdraftLimit: 100,
+	})
+	require.NoError(t, err)
+
+	hold, err := store.AuthorizeTransferTx(context.Background(), AuthorizeTransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        60,
+		ExpiresAt:     time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	require.Equal(t, util.HoldPending, hold.Status)
+
+	// the hold lowers the available balance but not the ledger balance
+	account, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, account.Balance)
+	require.Equal(t, int64(60), account.HeldAmount)
+
+	_, err = store.AuthorizeTransferTx(context.Background(), AuthorizeTransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        account1.Balance + 100 - 60 + 1,
+		ExpiresAt:     time.Now().Add(time.Hour),
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	_, err = store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{HoldID: hold.ID, Amount: 61})
+	require.ErrorIs(t, err, ErrCaptureExceedsHold)
+
+	// a partial capture releases the rest of the hold
+	result, err := store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{HoldID: hold.ID, Amount: 40})
+	require.NoError(t, err)
+	require.Equal(t, util.HoldCaptured, result.Hold.Status)
+	require.True(t, result.Hold.SettledAt.Valid)
+	require.Equal(t, result.Transfer.ID, result.Hold.TransferID.Int64)
+	require.Equal(t, int64(40), result.Transfer.Amount)
+	require.Equal(t, account1.Balance-40, result.FromAccount.Balance)
+	require.Zero(t, result.FromAccount.HeldAmount)
+	require.Equal(t, account2.Balance+40, result.ToAccount.Balance)
+
+	_, err = store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{HoldID: hold.ID})
+	require.ErrorIs(t, err, ErrHoldNotPending)
+
+	_, err = store.VoidHoldTx(context.Background(), hold.ID)
+	require.ErrorIs(t, err, ErrHoldNotPending)
+}
+
+// holds count against the limits of the sender when authorized and again when captured
+func TestHoldTransferLimits(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createFundedAccount(t, util.USD)
+	account2 := createFundedAccount(t, util.USD)
+	limits := &TransferLimits{PerTransfer: 50}
+
+	_, err := store.AuthorizeTransferTx(context.Background(), AuthorizeTransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        51,
+		ExpiresAt:     time.Now().Add(time.Hour),
+		Limits:        limits,
+	})
+	require.ErrorIs(t, err, ErrTransferLimitExceeded)
+
+	hold, err := store.AuthorizeTransferTx(context.Background(), AuthorizeTransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        50,
+		ExpiresAt:     time.Now().Add(time.Hour),
+		Limits:        limits,
+	})
+	require.NoError(t, err)
+
+	// the limit was lowered since the hold was authorized
+	_, err = store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{
+		HoldID: hold.ID,
+		Limits: &TransferLimits{PerTransfer: 40},
+	})
+	require.ErrorIs(t, err, ErrTransferLimitExceeded)
+
+	result, err := store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{
+		HoldID: hold.ID,
+		Amount: 40,
+		Limits: &TransferLimits{PerTransfer: 40},
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(40), result.Transfer.Amount)
+}
+
+// pending holds take their part of the daily allowance, so going over it is refused when authorizing
+func TestHoldDailyLimit(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createFundedAccount(t, util.USD)
+	account2 := createFundedAccount(t, util.USD)
+	limits := &TransferLimits{Daily: 50}
+
+	authorize := func(amount int64) (Hold, error) {
+		return store.AuthorizeTransferTx(context.Background(), AuthorizeTransferTxParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        amount,
+			ExpiresAt:     time.Now().Add(time.Hour),
+			Limits:        limits,
+		})
+	}
+
+	hold, err := authorize(30)
+	require.NoError(t, err)
+
+	_, err = authorize(30)
+	require.ErrorIs(t, err, ErrTransferLimitExceeded)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        30,
+		Limits:        limits,
+	})
+	require.ErrorIs(t, err, ErrTransferLimitExceeded)
+
+	// the capture settles the allowance its hold reserved
+	_, err = store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{
+		HoldID: hold.ID,
+		Limits: limits,
+	})
+	require.NoError(t, err)
+
+	_, err = authorize(21)
+	require.ErrorIs(t, err, ErrTransferLimitExceeded)
+
+	_, err = authorize(20)
+	require.NoError(t, err)
+}
+
+func TestVoidHoldTx(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	_, err := store.AuthorizeTransferTx(context.Background(), AuthorizeTransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        account1.Balance + 1,
+		ExpiresAt:     time.Now().Add(time.Hour),
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	hold, err := store.AuthorizeTransferTx(context.Background(), AuthorizeTransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        account1.Balance,
+		ExpiresAt:     time.Now().Add(time.Hour),
+	})
+	if account1.Balance == 0 {
+		// holds must reserve something
+		require.Error(t, err)
+		return
+	}
+	require.NoError(t, err)
+
+	voided, err := store.VoidHoldTx(context.Background(), hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, util.HoldVoided, voided.Status)
+	require.False(t, voided.TransferID.Valid)
+
+	account, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, account.Balance)
+	require.Zero(t, account.HeldAmount)
+}
+
+func TestExpireHoldTx(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	_, err := store.UpdateOverdraftLimit(context.Background(), UpdateOverdraftLimitParams{
+		ID:             account1.ID,
+		OverdraftLimit: 10,
+	})
+	require.NoError(t, err)
+
+	now := time.Now()
+	hold, err := store.AuthorizeTransferTx(context.Background(), AuthorizeTransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+		ExpiresAt:     now.Add(-time.Second),
+	})
+	require.NoError(t, err)
+
+	// an expired hold can no longer be captured
+	_, err = store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{HoldID: hold.ID})
+	require.ErrorIs(t, err, ErrHoldExpired)
+
+	audit := newTestAudit("")
+	expired, err := store.ExpireHoldTx(WithAudit(context.Background(), audit), now)
+	require.NoError(t, err)
+	require.Equal(t, hold.ID, expired.ID)
+	require.Equal(t, util.HoldExpired, expired.Status)
+
+	events := listRequestAuditEvents(t, audit)
+	require.Len(t, events, 1)
+	require.Equal(t, AuditTargetHold, events[0].TargetType.String)
+	require.Equal(t, strconv.FormatInt(hold.ID, 10), events[0].TargetID.String)
+
+	var after Hold
+	require.NoError(t, json.Unmarshal(events[0].After, &after))
+	require.Equal(t, util.HoldExpired, after.Status)
+
+	account, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Zero(t, account.HeldAmount)
+
+	_, err = store.ExpireHoldTx(context.Background(), now)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
@@ -0,0 +1,86 @@
+package zigibankgo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+)
+
+// Different types of error returned by idempotent transactions
+var (
+	ErrIdempotencyKeyReused = errors.New("idempotency key was already used for a different request")
+	errIdempotencyKeyTaken  = errors.New("idempotency key was claimed by a concurrent request")
+)
+
+// Identifies a client request that must be executed at most once
+type Idempotency struct {
+	Key         string
+	Username    string
+	RequestPath string
+	RequestHash string
+}
+
+// executes fn within a database transaction at most once per idempotency key.
+// A replayed request gets the response stored by the first execution copied into result
+func (store *SQLStore) execIdempotentTx(ctx context.Context, idem *Idempotency, result any, fn func(*Queries) error) error {
+	if idem == nil {
+		return store.execTx(ctx, fn)
+	}
+
+	replayed, err := replayIdempotent(ctx, store.Queries, idem, result)
+	if err != nil || replayed {
+		return err
+	}
+
+	err = store.execTx(ctx, func(q *Queries) error {
+		err := fn(q)
+		if err != nil {
+			return err
+		}
+
+		body, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+
+		// the key is recorded in the same transaction as the change, so a
+		// concurrent request with the same key blocks here until we commit
+		_, err = q.CreateIdempotencyKey(ctx, CreateIdempotencyKeyParams{
+			Username:     idem.Username,
+			Key:          idem.Key,
+			RequestPath:  idem.RequestPath,
+			RequestHash:  idem.RequestHash,
+			ResponseBody: body,
+		})
+		if err == sql.ErrNoRows {
+			return errIdempotencyKeyTaken
+		}
+		return err
+	})
+
+	if errors.Is(err, errIdempotencyKeyTaken) {
+		_, err = replayIdempotent(ctx, store.Queries, idem, result)
+	}
+	return err
+}
+
+// copies the stored response of an already executed request into result
+func replayIdempotent(ctx context.Context, q *Queries, idem *Idempotency, result any) (bool, error) {
+	stored, err := q.GetIdempotencyKey(ctx, GetIdempotencyKeyParams{
+		Username: idem.Username,
+		Key:      idem.Key,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if stored.RequestPath != idem.RequestPath || stored.RequestHash != idem.RequestHash {
+		return false, ErrIdempotencyKeyReused
+	}
+
+	return true, json.Unmarshal(stored.ResponseBody, result)
+}
@@ -0,0 +1,201 @@
+package zigibankgo
+
+import (
+	"BankAppGo/db/util"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// no interest rate is set for the currency and kind of the account
+	ErrNotInterestBearing = errors.New("account does not earn interest")
+	// interest is accrued on the end-of-day balance, so only days that are over can be accrued
+	ErrAccrualDayNotOver = errors.New("accrual day is not over")
+)
+
+// Contains input parameters of the interest accrual transaction
+type AccrueInterestTxParams struct {
+	AccountID int64 `json:"account_id"`
+	// the UTC day to accrue, the time of day is ignored
+	Date time.Time `json:"date"`
+}
+
+// Accrues a day of interest on the end-of-day balance of an account at the rate set for its currency and kind.
+// A day is accrued once per account, accruing it again returns the first accrual
+func (store *SQLStore) AccrueInterestTx(ctx context.Context, arg AccrueInterestTxParams) (InterestAccrual, error) {
+	var accrual InterestAccrual
+
+	day := arg.Date.UTC().Truncate(24 * time.Hour)
+	dayEnd := day.Add(24 * time.Hour)
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		// concurrent accruals of the account wait here, so the day cannot be accrued twice
+		account, err := q.GetAccountForUpdate(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		accrual, err = q.GetInterestAccrual(ctx, GetInterestAccrualParams{
+			AccountID:   account.ID,
+			AccrualDate: day,
+		})
+		switch {
+		case err == nil:
+			return nil
+		case !errors.Is(err, sql.ErrNoRows):
+			return err
+		}
+
+		if account.Status == util.AccountClosed {
+			return fmt.Errorf("%w: account %d", ErrAccountClosed, account.ID)
+		}
+		if dayEnd.After(time.Now()) {
+			return fmt.Errorf("%w: %s", ErrAccrualDayNotOver, day.Format(time.DateOnly))
+		}
+
+		rate, err := q.GetInterestRate(ctx, GetInterestRateParams{
+			Currency:    account.Currency,
+			AccountKind: account.Kind,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: no rate for %s %s accounts", ErrNotInterestBearing, account.Currency, account.Kind)
+		}
+		if err != nil {
+			return err
+		}
+
+		// entries booked after the day ended are taken back off the current balance
+		since, err := q.SumEntriesSince(ctx, SumEntriesSinceParams{
+			AccountID: account.ID,
+			Since:     dayEnd,
+		})
+		if err != nil {
+			return err
+		}
+		balance := account.Balance - since
+
+		amount, err := util.DailyInterest(balance, rate.AnnualRate, day)
+		if err != nil {
+			return err
+		}
+
+		accrual, err = q.CreateInterestAccrual(ctx, CreateInterestAccrualParams{
+			AccountID:   account.ID,
+			AccrualDate: day,
+			Balance:     balance,
+			AnnualRate:  rate.AnnualRate,
+			Amount:      amount,
+		})
+		return err
+	})
+
+	return accrual, err
+}
+
+// Contains input parameters of the interest capitalisation transaction
+type CapitalizeInterestTxParams struct {
+	AccountID int64 `json:"account_id"`
+	// accruals of days before this one are paid
+	Before time.Time `json:"before"`
+}
+
+// Contains result of the interest capitalisation transaction
+type CapitalizeInterestTxResult struct {
+	// interest paid, zero when the accruals earned nothing
+	Amount int64 `json:"amount"`
+	// the credit of the account, empty when nothing was paid
+	Entry   Entry   `json:"entry"`
+	Account Account `json:"account"`
+}
+
+// Pays the interest accrued by an account before a day from the interest expense account and marks the
+// accruals capitalised, so running it again pays nothing more
+func (store *SQLStore) CapitalizeInterestTx(ctx context.Context, arg CapitalizeInterestTxParams) (CapitalizeInterestTxResult, error) {
+	var result CapitalizeInterestTxResult
+
+	before := arg.Before.UTC().Truncate(24 * time.Hour)
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		account, err := q.GetAccountForUpdate(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		result.Amount, err = q.SumUncapitalizedInterest(ctx, SumUncapitalizedInterestParams{
+			AccountID: account.ID,
+			Before:    before,
+		})
+		if err != nil {
+			return err
+		}
+
+		var entryID sql.NullInt64
+		if result.Amount > 0 {
+			err = checkActive(account)
+			if err != nil {
+				return err
+			}
+
+			expense, err := systemAccount(ctx, q, util.InterestExpenseAccount, account.Currency)
+			if err != nil {
+				return err
+			}
+
+			_, entries, err := postJournal(ctx, q, util.InterestJournal, []JournalLeg{
+				{Account: account, Amount: result.Amount},
+				{Account: expense, Amount: -result.Amount},
+			})
+			if err != nil {
+				return err
+			}
+			result.Entry = entries[0]
+			entryID = sql.NullInt64{Int64: result.Entry.ID, Valid: true}
+		}
+
+		_, err = q.CapitalizeInterestAccruals(ctx, CapitalizeInterestAccrualsParams{
+			EntryID:   entryID,
+			AccountID: account.ID,
+			Before:    before,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Account, err = q.GetAccount(ctx, account.ID)
+		return err
+	})
+
+	return result, err
+}
+
+// Sets the annual rate of a currency and account kind within a database transaction
+func (store *SQLStore) SetInterestRateTx(ctx context.Context, arg UpsertInterestRateParams) (InterestRate, error) {
+	var interestRate InterestRate
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var before any
+		previous, err := q.GetInterestRate(ctx, GetInterestRateParams{
+			Currency:    arg.Currency,
+			AccountKind: arg.AccountKind,
+		})
+		switch {
+		case err == nil:
+			before = previous
+		case !errors.Is(err, sql.ErrNoRows):
+			return err
+		}
+
+		interestRate, err = q.UpsertInterestRate(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		recordChange(ctx, AuditTargetInterestRate, arg.Currency+"/"+arg.AccountKind, before, interestRate)
+		return nil
+	})
+
+	return interestRate, err
+}
@@ -0,0 +1,97 @@
+package zigibankgo
+
+import (
+	"BankAppGo/db/util"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// sets the annual rate of savings accounts in currency until the test ends
+func setInterestRate(t *testing.T, currency string, annualRate string) {
+	admin := createRandomUser(t)
+	_, err := testQueries.UpsertInterestRate(context.Background(), UpsertInterestRateParams{
+		Currency:    currency,
+		AccountKind: util.SavingsAccount,
+		AnnualRate:  annualRate,
+		UpdatedBy:   admin.Username,
+	})
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_, err := testDB.Exec("DELETE FROM interest_rates WHERE currency = $1 AND account_kind = $2", currency, util.SavingsAccount)
+		require.NoError(t, err)
+	})
+}
+
+func TestInterestTx(t *testing.T) {
+	store := NewStore(testDB)
+	owner := createRandomUser(t)
+
+	account, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    owner.Username,
+		Balance:  0,
+		Currency: util.GBP,
+		Kind:     util.SavingsAccount,
+	})
+	require.NoError(t, err)
+
+	_, err = store.DepositTx(context.Background(), CashTxParams{
+		AccountID:         account.ID,
+		Amount:            3650000,
+		ExternalReference: util.RandomString(12),
+		CreatedBy:         owner.Username,
+	})
+	require.NoError(t, err)
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	// the deposit was made today, so yesterday ended with nothing in the account
+	_, err = store.AccrueInterestTx(context.Background(), AccrueInterestTxParams{AccountID: account.ID, Date: today})
+	require.ErrorIs(t, err, ErrAccrualDayNotOver)
+	_, err = store.AccrueInterestTx(context.Background(), AccrueInterestTxParams{AccountID: account.ID, Date: today.AddDate(0, 0, -1)})
+	require.ErrorIs(t, err, ErrNotInterestBearing)
+
+	setInterestRate(t, util.GBP, "0.1")
+
+	accrual, err := store.AccrueInterestTx(context.Background(), AccrueInterestTxParams{AccountID: account.ID, Date: today.AddDate(0, 0, -1)})
+	require.NoError(t, err)
+	require.Zero(t, accrual.Balance)
+	require.Zero(t, accrual.Amount)
+
+	// accruing the same day again returns the first accrual
+	again, err := store.AccrueInterestTx(context.Background(), AccrueInterestTxParams{AccountID: account.ID, Date: today.AddDate(0, 0, -1)})
+	require.NoError(t, err)
+	require.Equal(t, accrual.ID, again.ID)
+
+	// the account held nothing on earlier days, so an accrual is written directly to have something to pay
+	_, err = testQueries.CreateInterestAccrual(context.Background(), CreateInterestAccrualParams{
+		AccountID:   account.ID,
+		AccrualDate: today.AddDate(0, 0, -2),
+		Balance:     3650000,
+		AnnualRate:  "0.1",
+		Amount:      1000,
+	})
+	require.NoError(t, err)
+
+	expense, err := systemAccount(context.Background(), testQueries, util.InterestExpenseAccount, util.GBP)
+	require.NoError(t, err)
+
+	result, err := store.CapitalizeInterestTx(context.Background(), CapitalizeInterestTxParams{AccountID: account.ID, Before: today})
+	require.NoError(t, err)
+	require.Equal(t, int64(1000), result.Amount)
+	require.Equal(t, int64(1000), result.Entry.Amount)
+	require.Equal(t, int64(3651000), result.Account.Balance)
+
+	after, err := store.GetAccount(context.Background(), expense.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(-1000), after.Balance-expense.Balance)
+
+	// capitalising again pays nothing more
+	result, err = store.CapitalizeInterestTx(context.Background(), CapitalizeInterestTxParams{AccountID: account.ID, Before: today})
+	require.NoError(t, err)
+	require.Zero(t, result.Amount)
+	require.Equal(t, int64(3651000), result.Account.Balance)
+}
@@ -0,0 +1,135 @@
+package zigibankgo
+
+import (
+	"BankAppGo/db/util"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// a posting whose entries do not sum to zero in every currency would create or destroy money
+var ErrUnbalancedJournal = errors.New("journal does not balance")
+
+// One side of a journal posting
+type JournalLeg struct {
+	// the account as loaded by the caller, for its currency
+	Account Account
+	// positive credits the account, negative debits it
+	Amount     int64
+	TransferID sql.NullInt64
+}
+
+// Applies the legs of a posting to the balances of their accounts and books them as chained entries.
+// Customer accounts must already be locked, system accounts are locked by their balance update
+func postJournal(ctx context.Context, q *Queries, kind string, legs []JournalLeg) (Journal, []Entry, error) {
+	totals := make(map[string]int64)
+	for _, leg := range legs {
+		totals[leg.Account.Currency] += leg.Amount
+	}
+	for currency, total := range totals {
+		if total != 0 {
+			return Journal{}, nil, fmt.Errorf("%w: %s legs sum to %d", ErrUnbalancedJournal, currency, total)
+		}
+	}
+
+	journal, err := q.CreateJournal(ctx, kind)
+	if err != nil {
+		return journal, nil, err
+	}
+
+	// balances are updated in account order so concurrent postings cannot deadlock.
+	// This locks every account of the posting before its entries are chained
+	order := make([]JournalLeg, len(legs))
+	copy(order, legs)
+	sort.SliceStable(order, func(i, j int) bool {
+		return order[i].Account.ID < order[j].Account.ID
+	})
+	for _, leg := range order {
+		_, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
+			ID:     leg.Account.ID,
+			Amount: leg.Amount,
+		})
+		if err != nil {
+			return journal, nil, err
+		}
+	}
+
+	journalID := sql.NullInt64{Int64: journal.ID, Valid: true}
+	entries := make([]Entry, len(legs))
+	for i, leg := range legs {
+		entries[i], err = createChainedEntry(ctx, q, Entry{
+			AccountID:  leg.Account.ID,
+			Amount:     leg.Amount,
+			TransferID: leg.TransferID,
+			JournalID:  journalID,
+		})
+		if err != nil {
+			return journal, entries, err
+		}
+	}
+
+	return journal, entries, nil
+}
+
+// loads the account the bank holds of a kind in a currency, opening it the first time
+// the bank posts in that currency
+func systemAccount(ctx context.Context, q *Queries, kind string, currency string) (Account, error) {
+	arg := GetSystemAccountParams{
+		Kind:     kind,
+		Currency: currency,
+	}
+	account, err := q.GetSystemAccount(ctx, arg)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = q.CreateSystemAccount(ctx, CreateSystemAccountParams(arg))
+		if err == nil {
+			account, err = q.GetSystemAccount(ctx, arg)
+		}
+	}
+	if err != nil {
+		return account, fmt.Errorf("cannot load %s account in %s: %w", kind, currency, err)
+	}
+	return account, nil
+}
+
+// refuses to pay into an account the bank keeps for its own postings
+func checkNotSystemAccount(ctx context.Context, q *Queries, accountID int64) error {
+	system, err := q.IsSystemAccount(ctx, accountID)
+	if err != nil {
+		return err
+	}
+	if system {
+		return fmt.Errorf("%w: account %d only takes postings of the bank", ErrSystemAccount, accountID)
+	}
+	return nil
+}
+
+// posts amount to a locked account against cash-in clearing, returning the entry of the account
+func postCash(ctx context.Context, q *Queries, kind string, account Account, amount int64) (Journal, Entry, error) {
+	err := checkActive(account)
+	if err != nil {
+		return Journal{}, Entry{}, err
+	}
+
+	if amount < 0 {
+		err = checkFunds(account, -amount)
+		if err != nil {
+			return Journal{}, Entry{}, err
+		}
+	}
+
+	clearing, err := systemAccount(ctx, q, util.CashClearingAccount, account.Currency)
+	if err != nil {
+		return Journal{}, Entry{}, err
+	}
+
+	journal, entries, err := postJournal(ctx, q, kind, []JournalLeg{
+		{Account: account, Amount: amount},
+		{Account: clearing, Amount: -amount},
+	})
+	if err != nil {
+		return journal, Entry{}, err
+	}
+	return journal, entries[0], nil
+}
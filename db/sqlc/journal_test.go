@@ -0,0 +1,99 @@
+package zigibankgo
+
+import (
+	"BankAppGo/db/util"
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExchangeTransferTxSuspense(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	suspense1, err := systemAccount(context.Background(), testQueries, util.FXSuspenseAccount, account1.Currency)
+	require.NoError(t, err)
+
+	result, err := store.ExchangeTransferTx(context.Background(), ExchangeTransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        100,
+		ToAmount:      92,
+		ExchangeRate:  "0.92",
+	})
+	require.NoError(t, err)
+	require.Equal(t, result.FromEntry.JournalID, result.ToEntry.JournalID)
+
+	// the suspense account of the source currency took the debited amount
+	after, err := store.GetAccount(context.Background(), suspense1.ID)
+	require.NoError(t, err)
+	if account1.Currency == account2.Currency {
+		require.Equal(t, int64(8), after.Balance-suspense1.Balance)
+	} else {
+		require.Equal(t, int64(100), after.Balance-suspense1.Balance)
+	}
+}
+
+// customers cannot pay into the accounts the bank keeps for its own postings
+func TestTransferToSystemAccount(t *testing.T) {
+	store := NewStore(testDB)
+	account := createFundedAccount(t, util.USD)
+
+	feesIncome, err := systemAccount(context.Background(), testQueries, util.FeesIncomeAccount, util.USD)
+	require.NoError(t, err)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account.ID,
+		ToAccountID:   feesIncome.ID,
+		Amount:        10,
+	})
+	require.ErrorIs(t, err, ErrSystemAccount)
+
+	_, err = store.AuthorizeTransferTx(context.Background(), AuthorizeTransferTxParams{
+		FromAccountID: account.ID,
+		ToAccountID:   feesIncome.ID,
+		Amount:        10,
+		ExpiresAt:     time.Now().Add(time.Hour),
+	})
+	require.ErrorIs(t, err, ErrSystemAccount)
+
+	_, err = store.CreateScheduledTransferTx(context.Background(), CreateScheduledTransferParams{
+		Owner:         account.Owner,
+		FromAccountID: account.ID,
+		ToAccountID:   feesIncome.ID,
+		Amount:        10,
+		Cadence:       util.DailyCadence,
+		StartAt:       time.Now().Add(time.Hour),
+		EndAt:         sql.NullTime{},
+	})
+	require.ErrorIs(t, err, ErrSystemAccount)
+
+	after, err := store.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, account.Balance, after.Balance)
+	require.Zero(t, after.HeldAmount)
+}
+
+// a currency nobody held when the migrations ran gets its system accounts on first use
+func TestSystemAccountOpenedOnFirstUse(t *testing.T) {
+	currency := strings.ToUpper(util.RandomString(6))
+
+	opened, err := systemAccount(context.Background(), testQueries, util.CashClearingAccount, currency)
+	require.NoError(t, err)
+	require.Equal(t, util.CashClearingAccount, opened.Owner)
+	require.Equal(t, currency, opened.Currency)
+	require.Zero(t, opened.Balance)
+
+	system, err := testQueries.IsSystemAccount(context.Background(), opened.ID)
+	require.NoError(t, err)
+	require.True(t, system)
+
+	again, err := systemAccount(context.Background(), testQueries, util.CashClearingAccount, currency)
+	require.NoError(t, err)
+	require.Equal(t, opened.ID, again.ID)
+}
@@ -5,7 +5,11 @@
 package zigibankgo
 
 import (
+	"database/sql"
+	"encoding/json"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 type Account struct {
@@ -14,6 +18,64 @@ type Account struct {
 	Balance   int64
 	Currency  string
 	CreatedAt time.Time
+	// how far below zero the balance may go
+	OverdraftLimit int64
+	// reserved by pending holds, taken off the available but not the ledger balance
+	HeldAmount int64
+	// active, frozen or closed. Only active accounts are debited or credited, entries and transfers are kept
+	Status string
+	// why the account was frozen, unfrozen or closed
+	StatusReason sql.NullString
+	// when the status last changed
+	StatusChangedAt sql.NullTime
+	// checking or savings, an account earns interest when a rate is set for its kind and currency
+	Kind string
+}
+
+type AuditEvent struct {
+	ID int64
+	// user who made the request, null when nobody was authenticated
+	Actor sql.NullString
+	// method and route of the request
+	Action string
+	// kind of record changed, null when the change was not described
+	TargetType sql.NullString
+	TargetID   sql.NullString
+	// the record before the change, null when it was created
+	Before json.RawMessage
+	// the record after the change
+	After    json.RawMessage
+	ClientIp string
+	// X-Request-ID of the request, generated when the client sent none
+	RequestID string
+	CreatedAt time.Time
+}
+
+type BalanceAdjustment struct {
+	ID        int64
+	AccountID int64
+	EntryID   int64
+	// positive credits the account, negative debits it
+	Amount int64
+	Reason string
+	// admin who made the adjustment
+	AdjustedBy string
+	CreatedAt  time.Time
+}
+
+type CashMovement struct {
+	ID int64
+	// deposit or withdrawal
+	Kind      string
+	AccountID int64
+	Amount    int64
+	// id given by the payment processor, a movement is booked at most once per reference
+	ExternalReference string
+	// entry booked on the account, set once the movement is posted
+	EntryID sql.NullInt64
+	// user who requested the movement
+	CreatedBy string
+	CreatedAt time.Time
 }
 
 type Entry struct {
@@ -22,6 +84,166 @@ type Entry struct {
 	// can be negative (debit) or positive (credit)
 	Amount    int64
 	CreatedAt time.Time
+	// set for entries booked by a transfer
+	TransferID sql.NullInt64
+	// posting the entry belongs to, the entries of a posting sum to zero per currency
+	JournalID sql.NullInt64
+	// hash of the previous entry of the account, null on its first entry
+	PrevHash []byte
+	// sha256 of the entry contents and prev_hash
+	Hash []byte
+}
+
+type Hold struct {
+	ID            int64
+	FromAccountID int64
+	ToAccountID   int64
+	// reserved on the source account, the most that can be captured
+	Amount int64
+	// pending, captured, voided or expired
+	Status    string
+	ExpiresAt time.Time
+	// set when the hold is captured
+	TransferID sql.NullInt64
+	// when the hold stopped being pending
+	SettledAt sql.NullTime
+	CreatedAt time.Time
+}
+
+type FeeSchedule struct {
+	ID       int64
+	Currency string
+	// flat, percentage or tiered
+	Kind    string
+	FlatFee int64
+	// basis points of the amount
+	RateBps int64
+	// used by tiered schedules, the first tier whose up_to covers the amount applies
+	Tiers  json.RawMessage
+	MinFee int64
+	// no cap when zero
+	MaxFee int64
+	// admin who set the schedule
+	CreatedBy string
+	CreatedAt time.Time
+}
+
+type IdempotencyKey struct {
+	Username    string
+	Key         string
+	RequestPath string
+	// sha256 fingerprint of the request body
+	RequestHash  string
+	ResponseBody json.RawMessage
+	CreatedAt    time.Time
+}
+
+type InterestAccrual struct {
+	ID          int64
+	AccountID   int64
+	AccrualDate time.Time
+	// balance at the end of the day
+	Balance int64
+	// rate in force on the day
+	AnnualRate string
+	// interest earned on the day, rounded to the minor unit with ties to even
+	Amount int64
+	// entry that paid the interest, null when nothing was paid
+	EntryID sql.NullInt64
+	// when the interest was added to the balance, null until then
+	CapitalizedAt sql.NullTime
+	CreatedAt     time.Time
+}
+
+type InterestRate struct {
+	Currency    string
+	AccountKind string
+	// decimal fraction of the balance paid over a year, 0.035 is 3.5%
+	AnnualRate string
+	// admin who set the rate
+	UpdatedBy string
+	UpdatedAt time.Time
+}
+
+type Journal struct {
+	ID int64
+	// opening, transfer, deposit, withdrawal, adjustment or interest
+	Kind      string
+	CreatedAt time.Time
+}
+
+type ReconciliationReport struct {
+	ID               int64
+	StartedAt        time.Time
+	FinishedAt       time.Time
+	AccountsChecked  int64
+	TransfersChecked int64
+	// checked transfers listed in unlinked_transfers, not counted as discrepancies
+	TransfersUnverifiable int64
+	DiscrepancyCount      int64
+	// every discrepancy found by the run, as reported
+	Discrepancies json.RawMessage
+	// admin who ran the check, empty when run from the command line
+	RunBy     sql.NullString
+	CreatedAt time.Time
+}
+
+type ScheduledTransfer struct {
+	ID            int64
+	Owner         string
+	FromAccountID int64
+	ToAccountID   int64
+	Amount        int64
+	// once, daily, weekly or monthly
+	Cadence string
+	// first run, monthly runs keep its day of the month
+	StartAt   time.Time
+	NextRunAt time.Time
+	// no run is made after it, runs forever when null
+	EndAt sql.NullTime
+	// active, paused, completed or cancelled
+	Status    string
+	CreatedAt time.Time
+}
+
+type ScheduledTransferRun struct {
+	ID                  int64
+	ScheduledTransferID int64
+	// the next_run_at the run was made for
+	ScheduledFor time.Time
+	// set when the transfer was made
+	TransferID sql.NullInt64
+	// set when the transfer failed
+	Error     sql.NullString
+	CreatedAt time.Time
+}
+
+type Session struct {
+	ID           uuid.UUID
+	Username     string
+	RefreshToken string
+	UserAgent    string
+	ClientIp     string
+	IsBlocked    bool
+	ExpiresAt    time.Time
+	CreatedAt    time.Time
+}
+
+type TokenRevocation struct {
+	ID       int64
+	Username string
+	// null revokes every token of the user issued at or before revoked_at
+	TokenID uuid.NullUUID
+	// when the revoked tokens expire on their own and the row can be deleted
+	ExpiresAt time.Time
+	RevokedAt time.Time
+}
+
+type SystemAccount struct {
+	// cash_clearing, fees_income, fx_suspense or interest_expense
+	Kind      string
+	Currency  string
+	AccountID int64
 }
 
 type Transfer struct {
@@ -31,6 +253,48 @@ type Transfer struct {
 	// must be positive
 	Amount    int64
 	CreatedAt time.Time
+	// credited to the destination account, in its currency
+	ToAmount int64
+	// destination minor units per source minor unit
+	ExchangeRate string
+	RateQuotedAt sql.NullTime
+	// set on a reversal, the transfer it sends money back for
+	ReversalOf sql.NullInt64
+	// how much of the amount was sent back by reversals
+	ReversedAmount int64
+	// taken from the amount for the bank, in the source currency
+	Fee int64
+}
+
+type TransferLimit struct {
+	Username string
+	Currency string
+	// most a single transfer may move, unlimited when zero
+	PerTransferLimit int64
+	// most the user may send in the currency per UTC day, unlimited when zero
+	DailyLimit int64
+	// admin who set the limits
+	UpdatedBy string
+	UpdatedAt time.Time
+}
+
+type TransferReversal struct {
+	ID         int64
+	TransferID int64
+	// sent back to the source account, in its currency
+	Amount      int64
+	Reason      string
+	RequestedBy string
+	// pending until the recipient consents, then completed
+	Status string
+	// set once the money was sent back
+	ReversalTransferID sql.NullInt64
+	CreatedAt          time.Time
+	CompletedAt        sql.NullTime
+}
+
+type UnlinkedTransfer struct {
+	TransferID int64
 }
 
 type User struct {
@@ -40,4 +304,6 @@ type User struct {
 	Email             string
 	PasswordChangedAt time.Time
 	CreatedAt         time.Time
+	// depositor, banker or admin
+	Role string
 }
@@ -6,25 +6,112 @@ package zigibankgo
 
 import (
 	"context"
+	"time"
+
+	"github.com/google/uuid"
 )
 
 type Querier interface {
 	AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (int64, error)
+	AddAccountHeldAmount(ctx context.Context, arg AddAccountHeldAmountParams) (Account, error)
+	AddTransferReversedAmount(ctx context.Context, arg AddTransferReversedAmountParams) (Transfer, error)
+	AdvanceScheduledTransfer(ctx context.Context, arg AdvanceScheduledTransferParams) (ScheduledTransfer, error)
+	BlockSession(ctx context.Context, arg BlockSessionParams) error
+	BlockUserSessions(ctx context.Context, username string) (int64, error)
+	CapitalizeInterestAccruals(ctx context.Context, arg CapitalizeInterestAccrualsParams) (int64, error)
+	ClaimDueScheduledTransfer(ctx context.Context, now time.Time) (ScheduledTransfer, error)
+	ClaimExpiredHold(ctx context.Context, now time.Time) (Hold, error)
+	CompleteTransferReversal(ctx context.Context, arg CompleteTransferReversalParams) (TransferReversal, error)
 	CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error)
+	CreateAuditEvent(ctx context.Context, arg CreateAuditEventParams) (AuditEvent, error)
+	CreateBalanceAdjustment(ctx context.Context, arg CreateBalanceAdjustmentParams) (BalanceAdjustment, error)
+	CreateCashMovement(ctx context.Context, arg CreateCashMovementParams) (CashMovement, error)
 	CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error)
+	CreateFeeSchedule(ctx context.Context, arg CreateFeeScheduleParams) (FeeSchedule, error)
+	CreateHold(ctx context.Context, arg CreateHoldParams) (Hold, error)
+	CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (IdempotencyKey, error)
+	CreateInterestAccrual(ctx context.Context, arg CreateInterestAccrualParams) (InterestAccrual, error)
+	CreateJournal(ctx context.Context, kind string) (Journal, error)
+	CreateReconciliationReport(ctx context.Context, arg CreateReconciliationReportParams) (ReconciliationReport, error)
+	CreateScheduledTransfer(ctx context.Context, arg CreateScheduledTransferParams) (ScheduledTransfer, error)
+	CreateScheduledTransferRun(ctx context.Context, arg CreateScheduledTransferRunParams) (ScheduledTransferRun, error)
+	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
+	// opens the account of a kind in a currency, owned by the system user of the kind.
+	// A posting racing to open the same account waits for it and leaves it in place
+	CreateSystemAccount(ctx context.Context, arg CreateSystemAccountParams) error
+	CreateTokenRevocation(ctx context.Context, arg CreateTokenRevocationParams) (TokenRevocation, error)
 	CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error)
+	CreateTransferReversal(ctx context.Context, arg CreateTransferReversalParams) (TransferReversal, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
 	DeleteAccount(ctx context.Context, id int64) error
+	DeleteExpiredTokenRevocations(ctx context.Context) (int64, error)
+	DeleteTransferLimit(ctx context.Context, arg DeleteTransferLimitParams) error
 	GetAccount(ctx context.Context, id int64) (Account, error)
 	GetAccountForUpdate(ctx context.Context, id int64) (Account, error)
+	GetCashMovementByReference(ctx context.Context, arg GetCashMovementByReferenceParams) (CashMovement, error)
 	GetEntry(ctx context.Context, accountID int64) (Entry, error)
+	GetEntryFromId(ctx context.Context, id int64) (Entry, error)
+	// the entry the next one of the account chains onto
+	GetLastAccountEntry(ctx context.Context, accountID int64) (Entry, error)
+	// the schedule in force for a currency is the last one set
+	GetFeeSchedule(ctx context.Context, currency string) (FeeSchedule, error)
+	// money the pending holds of a user reserve in a currency
+	GetHeldAmountTotal(ctx context.Context, arg GetHeldAmountTotalParams) (int64, error)
+	GetHold(ctx context.Context, id int64) (Hold, error)
+	GetHoldForUpdate(ctx context.Context, id int64) (Hold, error)
+	GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error)
+	GetInterestAccrual(ctx context.Context, arg GetInterestAccrualParams) (InterestAccrual, error)
+	GetInterestRate(ctx context.Context, arg GetInterestRateParams) (InterestRate, error)
+	GetOutboundTransferTotal(ctx context.Context, arg GetOutboundTransferTotalParams) (int64, error)
+	GetPendingTransferReversalForUpdate(ctx context.Context, transferID int64) (TransferReversal, error)
+	GetScheduledTransfer(ctx context.Context, id int64) (ScheduledTransfer, error)
+	GetSession(ctx context.Context, id uuid.UUID) (Session, error)
+	GetSystemAccount(ctx context.Context, arg GetSystemAccountParams) (Account, error)
 	GetTransfer(ctx context.Context, arg GetTransferParams) (Transfer, error)
+	GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error)
 	GetTransferFromId(ctx context.Context, id int64) (Transfer, error)
+	GetTransferLimit(ctx context.Context, arg GetTransferLimitParams) (TransferLimit, error)
 	GetUser(ctx context.Context, username string) (User, error)
+	IsSystemAccount(ctx context.Context, accountID int64) (bool, error)
+	ListAccountEntries(ctx context.Context, arg ListAccountEntriesParams) ([]Entry, error)
+	ListAccountEntryTotals(ctx context.Context, arg ListAccountEntryTotalsParams) ([]ListAccountEntryTotalsRow, error)
+	ListAccountIDsByOwner(ctx context.Context, owner string) ([]int64, error)
 	ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error)
+	ListAccountsWithUncapitalizedInterest(ctx context.Context, arg ListAccountsWithUncapitalizedInterestParams) ([]int64, error)
+	ListActiveTokenRevocations(ctx context.Context) ([]TokenRevocation, error)
+	// newest first, filters left null match every event
+	ListAuditEvents(ctx context.Context, arg ListAuditEventsParams) ([]AuditEvent, error)
+	ListBalanceAdjustments(ctx context.Context, accountID int64) ([]BalanceAdjustment, error)
+	// walks the entries account by account, in the order they were chained
+	ListChainEntries(ctx context.Context, arg ListChainEntriesParams) ([]Entry, error)
+	ListCurrencyTotals(ctx context.Context) ([]ListCurrencyTotalsRow, error)
 	ListEntries(ctx context.Context) ([]Entry, error)
+	// accounts open before the end of a day with a rate set for their currency and kind
+	ListInterestBearingAccounts(ctx context.Context, arg ListInterestBearingAccountsParams) ([]int64, error)
+	// schedules still to run that pay from or into an account, locked so they can be cancelled
+	ListOpenScheduledTransfersByAccount(ctx context.Context, accountID int64) ([]ScheduledTransfer, error)
+	ListReconciliationReports(ctx context.Context, arg ListReconciliationReportsParams) ([]ReconciliationReport, error)
+	ListScheduledTransferRuns(ctx context.Context, arg ListScheduledTransferRunsParams) ([]ScheduledTransferRun, error)
+	ListScheduledTransfers(ctx context.Context, arg ListScheduledTransfersParams) ([]ScheduledTransfer, error)
+	ListStatementEntries(ctx context.Context, arg ListStatementEntriesParams) ([]ListStatementEntriesRow, error)
+	ListTransferEntryCounts(ctx context.Context, arg ListTransferEntryCountsParams) ([]ListTransferEntryCountsRow, error)
 	ListTransfers(ctx context.Context) ([]Transfer, error)
+	// serializes the outbound transfers of a user in a currency until the transaction ends
+	LockTransferLimit(ctx context.Context, arg LockTransferLimitParams) error
+	// reserves the id of an entry, which is part of its hash
+	NextEntryID(ctx context.Context) (int64, error)
+	SetCashMovementEntry(ctx context.Context, arg SetCashMovementEntryParams) (CashMovement, error)
+	SettleHold(ctx context.Context, arg SettleHoldParams) (Hold, error)
+	SumEntriesFromID(ctx context.Context, arg SumEntriesFromIDParams) (int64, error)
+	SumEntriesSince(ctx context.Context, arg SumEntriesSinceParams) (int64, error)
+	SumUncapitalizedInterest(ctx context.Context, arg SumUncapitalizedInterestParams) (int64, error)
+	UpdateAccountStatus(ctx context.Context, arg UpdateAccountStatusParams) (Account, error)
 	UpdateBalance(ctx context.Context, arg UpdateBalanceParams) (int64, error)
+	UpdateOverdraftLimit(ctx context.Context, arg UpdateOverdraftLimitParams) (Account, error)
+	UpdateScheduledTransfer(ctx context.Context, arg UpdateScheduledTransferParams) (ScheduledTransfer, error)
+	UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (User, error)
+	UpsertInterestRate(ctx context.Context, arg UpsertInterestRateParams) (InterestRate, error)
+	UpsertTransferLimit(ctx context.Context, arg UpsertTransferLimitParams) (TransferLimit, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -0,0 +1,113 @@
+package zigibankgo
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Contains input parameters of the logout transaction
+type LogoutTxParams struct {
+	Username string
+	// access token to revoke until it expires
+	AccessTokenID        uuid.UUID
+	AccessTokenExpiresAt time.Time
+	// optional, session of the refresh token to block, the refresh token is revoked along with it
+	SessionID        uuid.NullUUID
+	SessionExpiresAt time.Time
+}
+
+// Contains result of the logout transaction
+type LogoutTxResult struct {
+	AccessTokenRevocation TokenRevocation `json:"access_token_revocation"`
+	// nil when no session was given
+	RefreshTokenRevocation *TokenRevocation `json:"refresh_token_revocation"`
+}
+
+// Revokes an access token, and the refresh token of its user's session while blocking the session,
+// within a single database transaction
+func (store *SQLStore) LogoutTx(ctx context.Context, arg LogoutTxParams) (LogoutTxResult, error) {
+	var result LogoutTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		result.AccessTokenRevocation, err = revokeToken(ctx, q, arg.Username, arg.AccessTokenID, arg.AccessTokenExpiresAt)
+		if err != nil {
+			return err
+		}
+
+		if !arg.SessionID.Valid {
+			return nil
+		}
+
+		refreshTokenRevocation, err := revokeToken(ctx, q, arg.Username, arg.SessionID.UUID, arg.SessionExpiresAt)
+		if err != nil {
+			return err
+		}
+		result.RefreshTokenRevocation = &refreshTokenRevocation
+
+		return q.BlockSession(ctx, BlockSessionParams{
+			ID:       arg.SessionID.UUID,
+			Username: arg.Username,
+		})
+	})
+
+	return result, err
+}
+
+// revokes a single token until it expires
+func revokeToken(ctx context.Context, q *Queries, username string, tokenID uuid.UUID, expiresAt time.Time) (TokenRevocation, error) {
+	revocation, err := q.CreateTokenRevocation(ctx, CreateTokenRevocationParams{
+		Username:  username,
+		TokenID:   uuid.NullUUID{UUID: tokenID, Valid: true},
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return revocation, err
+	}
+
+	recordChange(ctx, AuditTargetRevocation, strconv.FormatInt(revocation.ID, 10), nil, revocation)
+	return revocation, nil
+}
+
+// Contains input parameters of the revoke user sessions transaction
+type RevokeUserSessionsTxParams struct {
+	Username string
+	// must be at least as late as the expiry of any token issued so far
+	ExpiresAt time.Time
+}
+
+// Contains result of the revoke user sessions transaction
+type RevokeUserSessionsTxResult struct {
+	Revocation      TokenRevocation `json:"revocation"`
+	BlockedSessions int64           `json:"blocked_sessions"`
+}
+
+// Blocks every session of a user and revokes all tokens issued to them so far
+// within a single database transaction
+func (store *SQLStore) RevokeUserSessionsTx(ctx context.Context, arg RevokeUserSessionsTxParams) (RevokeUserSessionsTxResult, error) {
+	var result RevokeUserSessionsTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		result.BlockedSessions, err = q.BlockUserSessions(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+
+		result.Revocation, err = q.CreateTokenRevocation(ctx, CreateTokenRevocationParams{
+			Username:  arg.Username,
+			ExpiresAt: arg.ExpiresAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		recordChange(ctx, AuditTargetRevocation, strconv.FormatInt(result.Revocation.ID, 10), nil, result.Revocation)
+		return nil
+	})
+
+	return result, err
+}
@@ -0,0 +1,66 @@
+package zigibankgo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogoutTx(t *testing.T) {
+	store := NewStore(testDB)
+	session := createRandomSession(t)
+
+	arg := LogoutTxParams{
+		Username:             session.Username,
+		AccessTokenID:        uuid.New(),
+		AccessTokenExpiresAt: time.Now().Add(time.Minute),
+		SessionID:            uuid.NullUUID{UUID: session.ID, Valid: true},
+		SessionExpiresAt:     session.ExpiresAt,
+	}
+
+	result, err := store.LogoutTx(context.Background(), arg)
+	require.NoError(t, err)
+	revocation := result.AccessTokenRevocation
+	require.Equal(t, arg.Username, revocation.Username)
+	require.Equal(t, arg.AccessTokenID, revocation.TokenID.UUID)
+	require.WithinDuration(t, arg.AccessTokenExpiresAt, revocation.ExpiresAt, time.Second)
+
+	// the refresh token of the session is revoked as well
+	require.NotNil(t, result.RefreshTokenRevocation)
+	require.Equal(t, session.ID, result.RefreshTokenRevocation.TokenID.UUID)
+	require.WithinDuration(t, session.ExpiresAt, result.RefreshTokenRevocation.ExpiresAt, time.Second)
+
+	blocked, err := store.GetSession(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.True(t, blocked.IsBlocked)
+
+	revocations, err := store.ListActiveTokenRevocations(context.Background())
+	require.NoError(t, err)
+	require.Contains(t, revocations, revocation)
+	require.Contains(t, revocations, *result.RefreshTokenRevocation)
+
+	// the same token cannot be revoked twice
+	_, err = store.LogoutTx(context.Background(), arg)
+	require.Error(t, err)
+}
+
+func TestRevokeUserSessionsTx(t *testing.T) {
+	store := NewStore(testDB)
+	session := createRandomSession(t)
+
+	result, err := store.RevokeUserSessionsTx(context.Background(), RevokeUserSessionsTxParams{
+		Username:  session.Username,
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), result.BlockedSessions)
+	require.Equal(t, session.Username, result.Revocation.Username)
+	require.False(t, result.Revocation.TokenID.Valid)
+
+	blocked, err := store.GetSession(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.True(t, blocked.IsBlocked)
+}
@@ -0,0 +1,154 @@
+package zigibankgo
+
+import (
+	"BankAppGo/db/util"
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// Contains input parameters of the run scheduled transfer transaction
+type RunScheduledTransferTxParams struct {
+	// schedules due at or before it are claimed
+	Now time.Time
+	// optional, the limits of the owner when no override is set, checked as for any other transfer
+	Limits *TransferLimits
+}
+
+// Contains result of the run scheduled transfer transaction
+type RunScheduledTransferTxResult struct {
+	// the scheduled transfer after it was advanced
+	ScheduledTransfer ScheduledTransfer    `json:"scheduled_transfer"`
+	Run               ScheduledTransferRun `json:"run"`
+}
+
+// Claims one scheduled transfer due at now, makes its transfer with the limits and fee of
+// any other transfer, records the run and moves next_run_at to the following occurrence.
+//
+// The schedule stays locked with FOR UPDATE SKIP LOCKED until the run is recorded, so
+// executors on other replicas skip it instead of waiting. The claim, the transfer and the
+// run commit together, so an occurrence can neither move the money twice nor be lost.
+// Returns sql.ErrNoRows when nothing is due
+func (store *SQLStore) RunScheduledTransferTx(ctx context.Context, arg RunScheduledTransferTxParams) (RunScheduledTransferTxResult, error) {
+	var result RunScheduledTransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		scheduled, err := q.ClaimDueScheduledTransfer(ctx, arg.Now)
+		if err != nil {
+			return err
+		}
+
+		run := CreateScheduledTransferRunParams{
+			ScheduledTransferID: scheduled.ID,
+			ScheduledFor:        scheduled.NextRunAt,
+		}
+
+		transfer, err := feeTransfer(ctx, q, scheduled.FromAccountID, scheduled.ToAccountID, scheduled.Amount, arg.Limits)
+		switch {
+		case err == nil:
+			run.TransferID = sql.NullInt64{Int64: transfer.Transfer.ID, Valid: true}
+		case isTransferRefused(err):
+			// the occurrence is skipped, the owner sees why in the run history
+			run.Error = sql.NullString{String: err.Error(), Valid: true}
+		default:
+			// anything else is retried on the next claim
+			return err
+		}
+
+		result.Run, err = q.CreateScheduledTransferRun(ctx, run)
+		if err != nil {
+			return err
+		}
+
+		next, status := nextScheduledRun(scheduled, arg.Now)
+		result.ScheduledTransfer, err = q.AdvanceScheduledTransfer(ctx, AdvanceScheduledTransferParams{
+			ID:        scheduled.ID,
+			NextRunAt: next,
+			Status:    status,
+		})
+		return err
+	})
+
+	return result, err
+}
+
+// reports whether a transfer was refused by the rules of the bank rather than failed on the database.
+// Transfers are refused before anything is written, so the transaction can go on
+func isTransferRefused(err error) bool {
+	for _, target := range []error{
+		ErrInsufficientFunds,
+		ErrAccountClosed,
+		ErrAccountFrozen,
+		ErrSystemAccount,
+		ErrTransferLimitExceeded,
+		ErrAmountBelowFee,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// returns the first occurrence after now and the status the schedule is left in.
+// Occurrences missed while no executor was running are skipped rather than run in a burst
+func nextScheduledRun(scheduled ScheduledTransfer, now time.Time) (time.Time, string) {
+	next := scheduled.NextRunAt
+	for {
+		var ok bool
+		next, ok = util.NextRun(scheduled.Cadence, scheduled.StartAt, next)
+		if !ok || (scheduled.EndAt.Valid && next.After(scheduled.EndAt.Time)) {
+			return scheduled.NextRunAt, util.ScheduleCompleted
+		}
+		if next.After(now) {
+			return next, util.ScheduleActive
+		}
+	}
+}
+
+// Creates a scheduled transfer within a database transaction
+func (store *SQLStore) CreateScheduledTransferTx(ctx context.Context, arg CreateScheduledTransferParams) (ScheduledTransfer, error) {
+	var scheduled ScheduledTransfer
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		err := checkNotSystemAccount(ctx, q, arg.ToAccountID)
+		if err != nil {
+			return err
+		}
+
+		scheduled, err = q.CreateScheduledTransfer(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		recordChange(ctx, AuditTargetScheduledTransfer, strconv.FormatInt(scheduled.ID, 10), nil, scheduled)
+		return nil
+	})
+
+	return scheduled, err
+}
+
+// Updates a scheduled transfer that has not finished within a database transaction.
+// Returns sql.ErrNoRows when it was completed or cancelled
+func (store *SQLStore) UpdateScheduledTransferTx(ctx context.Context, arg UpdateScheduledTransferParams) (ScheduledTransfer, error) {
+	var scheduled ScheduledTransfer
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		before, err := q.GetScheduledTransfer(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+
+		scheduled, err = q.UpdateScheduledTransfer(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		recordChange(ctx, AuditTargetScheduledTransfer, strconv.FormatInt(scheduled.ID, 10), before, scheduled)
+		return nil
+	})
+
+	return scheduled, err
+}
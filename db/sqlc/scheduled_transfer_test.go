@@ -0,0 +1,218 @@
+package zigibankgo
+
+import (
+	"BankAppGo/db/util"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomScheduledTransfer(t *testing.T, from, to Account, amount int64, cadence string, startAt time.Time, endAt sql.NullTime) ScheduledTransfer {
+	arg := CreateScheduledTransferParams{
+		Owner:         from.Owner,
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        amount,
+		Cadence:       cadence,
+		StartAt:       startAt,
+		EndAt:         endAt,
+	}
+
+	scheduled, err := testQueries.CreateScheduledTransfer(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Owner, scheduled.Owner)
+	require.Equal(t, arg.Amount, scheduled.Amount)
+	require.Equal(t, arg.Cadence, scheduled.Cadence)
+	require.WithinDuration(t, startAt, scheduled.NextRunAt, time.Second)
+	require.Equal(t, util.ScheduleActive, scheduled.Status)
+
+	return scheduled
+}
+
+func TestRunScheduledTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	_, err := store.UpdateOverdraftLimit(context.Background(), UpdateOverdraftLimitParams{
+		ID:             account1.ID,
+		OverdraftLimit: 100,
+	})
+	require.NoError(t, err)
+
+	now := time.Now()
+	startAt := now.Add(-time.Minute)
+
+	// a one-off transfer runs once and completes
+	once := createRandomScheduledTransfer(t, account1, account2, 10, util.OnceCadence, startAt, sql.NullTime{})
+
+	result, err := store.RunScheduledTransferTx(context.Background(), RunScheduledTransferTxParams{Now: now})
+	require.NoError(t, err)
+	require.Equal(t, once.ID, result.Run.ScheduledTransferID)
+	require.WithinDuration(t, startAt, result.Run.ScheduledFor, time.Second)
+	require.True(t, result.Run.TransferID.Valid)
+	require.False(t, result.Run.Error.Valid)
+	require.Equal(t, util.ScheduleCompleted, result.ScheduledTransfer.Status)
+
+	transfer, err := store.GetTransferFromId(context.Background(), result.Run.TransferID.Int64)
+	require.NoError(t, err)
+	require.Equal(t, account1.ID, transfer.FromAccountID)
+	require.Equal(t, account2.ID, transfer.ToAccountID)
+	require.Equal(t, int64(10), transfer.Amount)
+
+	_, err = store.RunScheduledTransferTx(context.Background(), RunScheduledTransferTxParams{Now: now})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	// a recurring transfer moves on to its next occurrence
+	daily := createRandomScheduledTransfer(t, account1, account2, 5, util.DailyCadence, startAt, sql.NullTime{Time: now.Add(72 * time.Hour), Valid: true})
+
+	result, err = store.RunScheduledTransferTx(context.Background(), RunScheduledTransferTxParams{Now: now})
+	require.NoError(t, err)
+	require.Equal(t, daily.ID, result.Run.ScheduledTransferID)
+	require.True(t, result.Run.TransferID.Valid)
+	require.Equal(t, util.ScheduleActive, result.ScheduledTransfer.Status)
+	require.WithinDuration(t, startAt.AddDate(0, 0, 1), result.ScheduledTransfer.NextRunAt, time.Second)
+
+	_, err = store.RunScheduledTransferTx(context.Background(), RunScheduledTransferTxParams{Now: now})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	// a paused transfer is not claimed even once due
+	_, err = store.UpdateScheduledTransfer(context.Background(), UpdateScheduledTransferParams{
+		ID:     daily.ID,
+		Status: sql.NullString{String: util.SchedulePaused, Valid: true},
+	})
+	require.NoError(t, err)
+
+	_, err = store.RunScheduledTransferTx(context.Background(), RunScheduledTransferTxParams{Now: now.Add(48 * time.Hour)})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	cancelled, err := store.UpdateScheduledTransfer(context.Background(), UpdateScheduledTransferParams{
+		ID:     daily.ID,
+		Status: sql.NullString{String: util.ScheduleCancelled, Valid: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, util.ScheduleCancelled, cancelled.Status)
+
+	// a cancelled transfer cannot be resumed
+	_, err = store.UpdateScheduledTransfer(context.Background(), UpdateScheduledTransferParams{
+		ID:     daily.ID,
+		Status: sql.NullString{String: util.ScheduleActive, Valid: true},
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	runs, err := store.ListScheduledTransferRuns(context.Background(), ListScheduledTransferRunsParams{
+		ScheduledTransferID: daily.ID,
+		Limit:               10,
+	})
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+}
+
+func TestRunScheduledTransferTxInsufficientFunds(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	now := time.Now()
+	scheduled := createRandomScheduledTransfer(t, account1, account2, account1.Balance+1, util.OnceCadence, now.Add(-time.Minute), sql.NullTime{})
+
+	// the failure is recorded instead of returned
+	result, err := store.RunScheduledTransferTx(context.Background(), RunScheduledTransferTxParams{Now: now})
+	require.NoError(t, err)
+	require.Equal(t, scheduled.ID, result.Run.ScheduledTransferID)
+	require.False(t, result.Run.TransferID.Valid)
+	require.True(t, result.Run.Error.Valid)
+	require.Contains(t, result.Run.Error.String, ErrInsufficientFunds.Error())
+	require.Equal(t, util.ScheduleCompleted, result.ScheduledTransfer.Status)
+
+	account, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, account.Balance)
+}
+
+// a refused transfer of any kind is recorded as a failed run, so the schedule is not retried forever
+func TestRunScheduledTransferTxOverLimit(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	now := time.Now()
+	scheduled := createRandomScheduledTransfer(t, account1, account2, 10, util.DailyCadence, now.Add(-time.Minute), sql.NullTime{})
+
+	result, err := store.RunScheduledTransferTx(context.Background(), RunScheduledTransferTxParams{
+		Now:    now,
+		Limits: &TransferLimits{PerTransfer: 9},
+	})
+	require.NoError(t, err)
+	require.Equal(t, scheduled.ID, result.Run.ScheduledTransferID)
+	require.False(t, result.Run.TransferID.Valid)
+	require.Contains(t, result.Run.Error.String, ErrTransferLimitExceeded.Error())
+	require.Equal(t, util.ScheduleActive, result.ScheduledTransfer.Status)
+	require.True(t, result.ScheduledTransfer.NextRunAt.After(now))
+
+	_, err = store.RunScheduledTransferTx(context.Background(), RunScheduledTransferTxParams{Now: now})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	account, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, account.Balance)
+}
+
+// a run whose amount doesn't cover the fee is refused like any other transfer
+func TestRunScheduledTransferTxBelowFee(t *testing.T) {
+	store := NewStore(testDB)
+	admin := createRandomUser(t)
+	account1 := createFundedAccount(t, util.USD)
+	account2 := createFundedAccount(t, util.USD)
+
+	setFeeSchedule(t, CreateFeeScheduleParams{
+		Currency:  util.USD,
+		Kind:      "flat",
+		FlatFee:   15,
+		Tiers:     json.RawMessage("[]"),
+		CreatedBy: admin.Username,
+	})
+
+	now := time.Now()
+	scheduled := createRandomScheduledTransfer(t, account1, account2, 15, util.OnceCadence, now.Add(-time.Minute), sql.NullTime{})
+
+	result, err := store.RunScheduledTransferTx(context.Background(), RunScheduledTransferTxParams{Now: now})
+	require.NoError(t, err)
+	require.Equal(t, scheduled.ID, result.Run.ScheduledTransferID)
+	require.False(t, result.Run.TransferID.Valid)
+	require.Contains(t, result.Run.Error.String, ErrAmountBelowFee.Error())
+	require.Equal(t, util.ScheduleCompleted, result.ScheduledTransfer.Status)
+
+	account, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, account.Balance)
+
+	account, err = store.GetAccount(context.Background(), account2.ID)
+	require.NoError(t, err)
+	require.Equal(t, account2.Balance, account.Balance)
+}
+
+func TestNextScheduledRun(t *testing.T) {
+	start := time.Date(2024, time.January, 10, 8, 0, 0, 0, time.UTC)
+	scheduled := ScheduledTransfer{
+		Cadence:   util.WeeklyCadence,
+		StartAt:   start,
+		NextRunAt: start,
+	}
+
+	// missed occurrences are skipped
+	next, status := nextScheduledRun(scheduled, start.AddDate(0, 0, 20))
+	require.Equal(t, start.AddDate(0, 0, 21), next)
+	require.Equal(t, util.ScheduleActive, status)
+
+	scheduled.EndAt = sql.NullTime{Time: start.AddDate(0, 0, 10), Valid: true}
+	_, status = nextScheduledRun(scheduled, start)
+	require.Equal(t, util.ScheduleActive, status)
+
+	_, status = nextScheduledRun(scheduled, start.AddDate(0, 0, 7))
+	require.Equal(t, util.ScheduleCompleted, status)
+}
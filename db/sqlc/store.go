@@ -1,15 +1,66 @@
 package zigibankgo
 
 import (
+	"BankAppGo/db/util"
+	"BankAppGo/fx"
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"strconv"
+	"time"
+)
+
+// Different types of error returned when money cannot be moved
+var (
+	// a debit would take an account below its overdraft limit
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	// the account was closed and no longer accepts debits or credits
+	ErrAccountClosed = errors.New("account is closed")
+	// the account was frozen by compliance and accepts no debits or credits until unfrozen
+	ErrAccountFrozen = errors.New("account is frozen")
+	// only accounts with a zero balance and no pending holds can be closed
+	ErrAccountNotEmpty = errors.New("account balance is not zero")
+	// the account already has the requested status
+	ErrInvalidStatusTransition = errors.New("invalid account status transition")
+	// the account is kept by the bank for its own postings and cannot be paid into
+	ErrSystemAccount = errors.New("account is a system account")
 )
 
 // Provides all functions to execute db queries and transactions
 type Store interface {
 	Querier
 	TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error)
+	ExchangeTransferTx(ctx context.Context, arg ExchangeTransferTxParams) (TransferTxResult, error)
+	CreateAccountTx(ctx context.Context, arg CreateAccountTxParams) (Account, error)
+	AdjustBalanceTx(ctx context.Context, arg AdjustBalanceTxParams) (AdjustBalanceTxResult, error)
+	DepositTx(ctx context.Context, arg CashTxParams) (CashTxResult, error)
+	WithdrawTx(ctx context.Context, arg CashTxParams) (CashTxResult, error)
+	CloseAccountTx(ctx context.Context, accountID int64) (Account, error)
+	SetAccountStatusTx(ctx context.Context, arg SetAccountStatusTxParams) (Account, error)
+	ListAccountEntriesTx(ctx context.Context, arg ListAccountEntriesTxParams) (ListAccountEntriesTxResult, error)
+	StatementTx(ctx context.Context, arg StatementTxParams) (StatementTxResult, error)
+	FilterTransfers(ctx context.Context, arg FilterTransfersParams) ([]Transfer, error)
+	RunScheduledTransferTx(ctx context.Context, arg RunScheduledTransferTxParams) (RunScheduledTransferTxResult, error)
+	AuthorizeTransferTx(ctx context.Context, arg AuthorizeTransferTxParams) (Hold, error)
+	CaptureHoldTx(ctx context.Context, arg CaptureHoldTxParams) (CaptureHoldTxResult, error)
+	VoidHoldTx(ctx context.Context, holdID int64) (Hold, error)
+	ExpireHoldTx(ctx context.Context, now time.Time) (Hold, error)
+	ReverseTransferTx(ctx context.Context, arg TransferReversalTxParams) (TransferReversalTxResult, error)
+	RequestTransferReversalTx(ctx context.Context, arg TransferReversalTxParams) (TransferReversal, error)
+	ApproveTransferReversalTx(ctx context.Context, transferID int64) (TransferReversalTxResult, error)
+	LogoutTx(ctx context.Context, arg LogoutTxParams) (LogoutTxResult, error)
+	RevokeUserSessionsTx(ctx context.Context, arg RevokeUserSessionsTxParams) (RevokeUserSessionsTxResult, error)
+	AccrueInterestTx(ctx context.Context, arg AccrueInterestTxParams) (InterestAccrual, error)
+	CapitalizeInterestTx(ctx context.Context, arg CapitalizeInterestTxParams) (CapitalizeInterestTxResult, error)
+	CreateUserTx(ctx context.Context, arg CreateUserParams) (User, error)
+	CreateSessionTx(ctx context.Context, arg CreateSessionParams) (Session, error)
+	CreateFeeScheduleTx(ctx context.Context, arg CreateFeeScheduleParams) (FeeSchedule, error)
+	SetInterestRateTx(ctx context.Context, arg UpsertInterestRateParams) (InterestRate, error)
+	SetTransferLimitTx(ctx context.Context, arg UpsertTransferLimitParams) (TransferLimit, error)
+	DeleteTransferLimitTx(ctx context.Context, arg DeleteTransferLimitParams) error
+	CreateScheduledTransferTx(ctx context.Context, arg CreateScheduledTransferParams) (ScheduledTransfer, error)
+	UpdateScheduledTransferTx(ctx context.Context, arg UpdateScheduledTransferParams) (ScheduledTransfer, error)
 }
 
 // Provides all functions to execute SQL queries and transactions
@@ -28,14 +79,24 @@ func NewStore(db *sql.DB) Store {
 
 // executes a function within a database transaction
 func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) error {
-	tx, err := store.db.BeginTx(ctx, nil)
+	return store.execTxOptions(ctx, nil, fn)
+}
+
+// executes a function within a database transaction started with the given options
+func (store *SQLStore) execTxOptions(ctx context.Context, opts *sql.TxOptions, fn func(*Queries) error) error {
+	tx, err := store.db.BeginTx(ctx, opts)
 	if err != nil {
 		return err
 	}
 
 	q := New(tx)
 	err = fn(q)
+	if err == nil {
+		// audited requests record what they changed in the same transaction
+		err = writeAudit(ctx, q)
+	}
 	if err != nil {
+		discardAudit(ctx)
 		if rbErr := tx.Rollback(); rbErr != nil {
 			return fmt.Errorf("tx err: %v, rb err: %v", err, rbErr)
 		}
@@ -50,6 +111,10 @@ type TransferTxParams struct {
 	FromAccountID int64 `json:"from_account_id"`
 	ToAccountID   int64 `json:"to_account_id"`
 	Amount        int64 `json:"amount"`
+	// optional, the limits of the sender when no override is set, checked within the transfer transaction
+	Limits *TransferLimits `json:"-"`
+	// optional, makes retries of the same request return the first result
+	Idempotency *Idempotency `json:"-"`
 }
 
 // Contains result of the transfer transaction
@@ -59,111 +124,259 @@ type TransferTxResult struct {
 	ToAccount   Account  `json:"to_account"`
 	FromEntry   Entry    `json:"from_entry"`
 	ToEntry     Entry    `json:"to_entry"`
+	// taken from the amount for the bank, in the source currency
+	Fee int64 `json:"fee"`
 }
 
 // var txKey = struct{}{}
 
 // Performs money transfer from on account to another
-// It creates a transfer record, add ccount entries, and update accounts' balance within a single database transaction
+// It creates a transfer record, add ccount entries, and update accounts' balance within a single database transaction.
+// The fee of the source currency's schedule is taken from the amount, the destination is credited the rest
 func (store *SQLStore) TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error) {
 	var result TransferTxResult
 
-	err := store.execTx(ctx, func(q *Queries) error {
+	err := store.execIdempotentTx(ctx, arg.Idempotency, &result, func(q *Queries) error {
 		var err error
-		result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{FromAccountID: arg.FromAccountID, ToAccountID: arg.ToAccountID, Amount: arg.Amount})
-		if err != nil {
-			return err
-		}
+		result, err = feeTransfer(ctx, q, arg.FromAccountID, arg.ToAccountID, arg.Amount, arg.Limits)
+		return err
+	})
 
-		result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.FromAccountID,
-			Amount:    -arg.Amount,
-		})
-		if err != nil {
-			return err
-		}
+	return result, err
+}
 
-		result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.ToAccountID,
-			Amount:    arg.Amount,
-		})
+// checks the limits of the sender when given, then transfers amount within one currency,
+// taking the fee of its schedule from it and crediting the destination the rest
+func feeTransfer(ctx context.Context, q *Queries, fromAccountID int64, toAccountID int64, amount int64, limits *TransferLimits) (TransferTxResult, error) {
+	if limits != nil {
+		err := checkTransferLimits(ctx, q, fromAccountID, amount, 0, *limits)
 		if err != nil {
-			return err
+			return TransferTxResult{}, err
 		}
+	}
 
-		if arg.FromAccountID <= arg.ToAccountID {
-			id1, id2, err := transferMoney(
-				ctx,
-				q,
-				arg.FromAccountID,
-				-arg.Amount,
-				arg.ToAccountID,
-				arg.Amount,
-			)
-			if err != nil {
-				return err
-			}
+	fee, err := transferFee(ctx, q, fromAccountID, amount)
+	if err != nil {
+		return TransferTxResult{}, err
+	}
 
-			result.FromAccount, err = q.GetAccount(ctx, id1)
-			if err != nil {
-				return err
-			}
-			result.ToAccount, err = q.GetAccount(ctx, id2)
-			if err != nil {
-				return err
-			}
-		} else {
-			id2, id1, err := transferMoney(
-				ctx,
-				q,
-				arg.ToAccountID,
-				arg.Amount,
-				arg.FromAccountID,
-				-arg.Amount,
-			)
+	return transfer(ctx, q, CreateTransferParams{
+		FromAccountID: fromAccountID,
+		ToAccountID:   toAccountID,
+		Amount:        amount,
+		ToAmount:      amount - fee,
+		ExchangeRate:  "1",
+		Fee:           fee,
+	})
+}
+
+// Contains input parameters of the cross-currency transfer transaction
+type ExchangeTransferTxParams struct {
+	FromAccountID int64 `json:"from_account_id"`
+	ToAccountID   int64 `json:"to_account_id"`
+	// debited from the source account, in its currency
+	Amount int64 `json:"amount"`
+	// credited to the destination account, in its currency.
+	// Converted again from what is left of Amount when a fee is charged
+	ToAmount     int64     `json:"to_amount"`
+	ExchangeRate string    `json:"exchange_rate"`
+	QuotedAt     time.Time `json:"quoted_at"`
+	// optional, the limits of the sender when no override is set, checked within the transfer transaction
+	Limits *TransferLimits `json:"-"`
+	// optional, makes retries of the same request return the first result
+	Idempotency *Idempotency `json:"-"`
+}
+
+// Performs money transfer between accounts held in different currencies.
+// The source is debited Amount in its currency and the destination credited ToAmount in its currency,
+// and the rate used is recorded on the transfer
+func (store *SQLStore) ExchangeTransferTx(ctx context.Context, arg ExchangeTransferTxParams) (TransferTxResult, error) {
+	var result TransferTxResult
+
+	err := store.execIdempotentTx(ctx, arg.Idempotency, &result, func(q *Queries) error {
+		if arg.Limits != nil {
+			err := checkTransferLimits(ctx, q, arg.FromAccountID, arg.Amount, 0, *arg.Limits)
 			if err != nil {
 				return err
 			}
+		}
+
+		fee, err := transferFee(ctx, q, arg.FromAccountID, arg.Amount)
+		if err != nil {
+			return err
+		}
 
-			result.FromAccount, err = q.GetAccount(ctx, id1)
+		toAmount := arg.ToAmount
+		if fee > 0 {
+			quote := fx.Quote{Rate: arg.ExchangeRate}
+			toAmount, err = quote.Convert(arg.Amount - fee)
 			if err != nil {
 				return err
 			}
-			result.ToAccount, err = q.GetAccount(ctx, id2)
-			if err != nil {
-				return err
+			if toAmount <= 0 {
+				return fmt.Errorf("%w: %d left after a fee of %d converts to nothing", ErrAmountBelowFee, arg.Amount-fee, fee)
 			}
 		}
 
-		return nil
+		result, err = transfer(ctx, q, CreateTransferParams{
+			FromAccountID: arg.FromAccountID,
+			ToAccountID:   arg.ToAccountID,
+			Amount:        arg.Amount,
+			ToAmount:      toAmount,
+			ExchangeRate:  arg.ExchangeRate,
+			RateQuotedAt:  sql.NullTime{Time: arg.QuotedAt, Valid: true},
+			Fee:           fee,
+		})
+		return err
 	})
 
 	return result, err
 }
 
-func transferMoney(
-	ctx context.Context,
-	q *Queries,
-	accountID1 int64,
-	amount1 int64,
-	accountID2 int64,
-	amount2 int64,
-) (account1 int64, account2 int64, err error) {
-	account1, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
-		ID:     accountID1,
-		Amount: amount1,
-	})
+// creates the transfer record and moves the money with one journal posting,
+// debiting Amount from the source, crediting Fee to fees income and ToAmount to the destination
+func transfer(ctx context.Context, q *Queries, arg CreateTransferParams) (TransferTxResult, error) {
+	var result TransferTxResult
+
+	fromAccount, toAccount, err := lockAccounts(ctx, q, arg.FromAccountID, arg.ToAccountID)
 	if err != nil {
-		return
+		return result, err
 	}
 
-	account2, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
-		ID:     accountID2,
-		Amount: amount2,
-	})
+	err = checkActive(fromAccount)
+	if err != nil {
+		return result, err
+	}
+
+	err = checkActive(toAccount)
 	if err != nil {
+		return result, err
+	}
+
+	err = checkNotSystemAccount(ctx, q, toAccount.ID)
+	if err != nil {
+		return result, err
+	}
+
+	err = checkFunds(fromAccount, arg.Amount)
+	if err != nil {
+		return result, err
+	}
+
+	result.Transfer, err = q.CreateTransfer(ctx, arg)
+	if err != nil {
+		return result, err
+	}
+	recordChange(ctx, AuditTargetTransfer, strconv.FormatInt(result.Transfer.ID, 10), nil, result.Transfer)
+
+	transferID := sql.NullInt64{Int64: result.Transfer.ID, Valid: true}
+	legs := []JournalLeg{{Account: fromAccount, Amount: -arg.Amount, TransferID: transferID}}
+
+	if arg.Fee > 0 {
+		feesIncome, err := systemAccount(ctx, q, util.FeesIncomeAccount, fromAccount.Currency)
+		if err != nil {
+			return result, err
+		}
+		legs = append(legs, JournalLeg{Account: feesIncome, Amount: arg.Fee})
+	}
+
+	// an exchange balances each side against the suspense account of its own currency
+	sent := arg.Amount - arg.Fee
+	if fromAccount.Currency != toAccount.Currency || sent != arg.ToAmount {
+		fromSuspense, err := systemAccount(ctx, q, util.FXSuspenseAccount, fromAccount.Currency)
+		if err != nil {
+			return result, err
+		}
+		toSuspense, err := systemAccount(ctx, q, util.FXSuspenseAccount, toAccount.Currency)
+		if err != nil {
+			return result, err
+		}
+
+		legs = append(legs,
+			JournalLeg{Account: fromSuspense, Amount: sent},
+			JournalLeg{Account: toSuspense, Amount: -arg.ToAmount},
+		)
+	}
+
+	legs = append(legs, JournalLeg{Account: toAccount, Amount: arg.ToAmount, TransferID: transferID})
+
+	_, entries, err := postJournal(ctx, q, util.TransferJournal, legs)
+	if err != nil {
+		return result, err
+	}
+	result.FromEntry = entries[0]
+	result.ToEntry = entries[len(entries)-1]
+	result.Fee = arg.Fee
+
+	result.FromAccount, err = q.GetAccount(ctx, arg.FromAccountID)
+	if err != nil {
+		return result, err
+	}
+	result.ToAccount, err = q.GetAccount(ctx, arg.ToAccountID)
+	return result, err
+}
+
+// Contains input parameters of the create account transaction
+type CreateAccountTxParams struct {
+	CreateAccountParams
+	// optional, makes retries of the same request return the first result
+	Idempotency *Idempotency
+}
+
+// Creates an account within a database transaction, at most once per idempotency key
+func (store *SQLStore) CreateAccountTx(ctx context.Context, arg CreateAccountTxParams) (Account, error) {
+	var account Account
+
+	err := store.execIdempotentTx(ctx, arg.Idempotency, &account, func(q *Queries) error {
+		var err error
+		account, err = q.CreateAccount(ctx, arg.CreateAccountParams)
+		if err != nil {
+			return err
+		}
+
+		recordChange(ctx, AuditTargetAccount, strconv.FormatInt(account.ID, 10), nil, account)
+		return nil
+	})
+
+	return account, err
+}
+
+// locks both accounts of a transfer in a consistent order to avoid deadlocks
+func lockAccounts(ctx context.Context, q *Queries, fromAccountID int64, toAccountID int64) (fromAccount Account, toAccount Account, err error) {
+	if fromAccountID <= toAccountID {
+		fromAccount, err = q.GetAccountForUpdate(ctx, fromAccountID)
+		if err != nil {
+			return
+		}
+		toAccount, err = q.GetAccountForUpdate(ctx, toAccountID)
 		return
 	}
 
+	toAccount, err = q.GetAccountForUpdate(ctx, toAccountID)
+	if err != nil {
+		return
+	}
+	fromAccount, err = q.GetAccountForUpdate(ctx, fromAccountID)
 	return
 }
+
+// checks that the account can be debited or credited
+func checkActive(account Account) error {
+	switch account.Status {
+	case util.AccountFrozen:
+		return fmt.Errorf("%w: account %d", ErrAccountFrozen, account.ID)
+	case util.AccountClosed:
+		return fmt.Errorf("%w: account %d", ErrAccountClosed, account.ID)
+	}
+	return nil
+}
+
+// checks that debiting amount keeps the account within its overdraft limit,
+// counting the money reserved by pending holds as already spent
+func checkFunds(account Account, amount int64) error {
+	available := account.Balance + account.OverdraftLimit - account.HeldAmount
+	if available < amount {
+		return fmt.Errorf("%w: account %d has %d available, transfer needs %d", ErrInsufficientFunds, account.ID, available, amount)
+	}
+	return nil
+}
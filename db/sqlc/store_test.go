@@ -1,8 +1,10 @@
 package zigibankgo
 
 import (
+	"BankAppGo/db/util"
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -154,3 +156,115 @@ func TestTransferTxDeadlock(t *testing.T) {
 	require.Equal(t, account2.Balance, updateBalance2.Balance)
 
 }
+
+func TestTransferTxIdempotent(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	amount := int64(10)
+
+	idempotency := &Idempotency{
+		Key:         util.RandomString(16),
+		Username:    account1.Owner,
+		RequestPath: "POST /transfers",
+		RequestHash: util.RandomString(64),
+	}
+	arg := TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        amount,
+		Idempotency:   idempotency,
+	}
+
+	result1, err := store.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	// a retry returns the first result without moving money again
+	result2, err := store.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, result1.Transfer.ID, result2.Transfer.ID)
+	require.Equal(t, result1.FromAccount.Balance, result2.FromAccount.Balance)
+
+	updatedAccount1, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance-amount, updatedAccount1.Balance)
+
+	// the same key with a different request is rejected
+	arg.Idempotency = &Idempotency{
+		Key:         idempotency.Key,
+		Username:    idempotency.Username,
+		RequestPath: idempotency.RequestPath,
+		RequestHash: util.RandomString(64),
+	}
+	_, err = store.TransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrIdempotencyKeyReused)
+}
+
+func TestTransferTxInsufficientFunds(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        account1.Balance + 1,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	// an overdraft limit lets the account go negative up to the limit
+	account1, err = testQueries.UpdateOverdraftLimit(context.Background(), UpdateOverdraftLimitParams{
+		ID:             account1.ID,
+		OverdraftLimit: 100,
+	})
+	require.NoError(t, err)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        account1.Balance + 100,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(-100), result.FromAccount.Balance)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        1,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+}
+
+func TestExchangeTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	quotedAt := time.Now().Truncate(time.Microsecond)
+
+	arg := ExchangeTransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        100,
+		ToAmount:      92,
+		ExchangeRate:  "0.92",
+		QuotedAt:      quotedAt,
+	}
+
+	result, err := store.ExchangeTransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	transfer := result.Transfer
+	require.Equal(t, arg.Amount, transfer.Amount)
+	require.Equal(t, arg.ToAmount, transfer.ToAmount)
+	require.Equal(t, arg.ExchangeRate, transfer.ExchangeRate)
+	require.True(t, transfer.RateQuotedAt.Valid)
+	require.WithinDuration(t, quotedAt, transfer.RateQuotedAt.Time, time.Second)
+
+	require.Equal(t, -arg.Amount, result.FromEntry.Amount)
+	require.Equal(t, arg.ToAmount, result.ToEntry.Amount)
+	require.Equal(t, account1.Balance-arg.Amount, result.FromAccount.Balance)
+	require.Equal(t, account2.Balance+arg.ToAmount, result.ToAccount.Balance)
+}
@@ -0,0 +1,154 @@
+package zigibankgo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Directions of a transfer relative to the filtered accounts
+const (
+	TransferDirectionIn  = "in"
+	TransferDirectionOut = "out"
+)
+
+// Orders in which filtered transfers can be listed
+const (
+	TransferSortDate   = "date"
+	TransferSortAmount = "amount"
+)
+
+// Contains the filters, order and page of a transfer listing.
+// Zero values leave a filter out
+type FilterTransfersParams struct {
+	// only transfers from or to these accounts are listed, must not be empty
+	AccountIDs []int64
+	// in, out or empty for both
+	Direction string
+	// the other side of the transfer
+	CounterpartyAccountID int64
+	MinAmount             int64
+	MaxAmount             int64
+	// transfers created in [From, To)
+	From time.Time
+	To   time.Time
+	// date or amount, transfers with equal amounts are ordered by id
+	SortBy     string
+	Descending bool
+	// keyset of the last transfer of the previous page, AfterID 0 starts from the first one
+	AfterID     int64
+	AfterAmount int64
+	Limit       int64
+}
+
+// Lists the transfers matching the filters. The query is built by hand since sqlc
+// cannot vary the filters and order; the account conditions are written as
+// from_account_id/to_account_id = ANY(...) so the indexes on both columns are used
+func (store *SQLStore) FilterTransfers(ctx context.Context, arg FilterTransfersParams) ([]Transfer, error) {
+	if len(arg.AccountIDs) == 0 {
+		return []Transfer{}, nil
+	}
+
+	var conditions []string
+	var args []any
+	param := func(value any) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	accounts := param(pq.Array(arg.AccountIDs))
+	out := "from_account_id = ANY(" + accounts + ")"
+	in := "to_account_id = ANY(" + accounts + ")"
+	if arg.CounterpartyAccountID != 0 {
+		counterparty := param(arg.CounterpartyAccountID)
+		out += " AND to_account_id = " + counterparty
+		in += " AND from_account_id = " + counterparty
+	}
+
+	switch arg.Direction {
+	case TransferDirectionOut:
+		conditions = append(conditions, out)
+	case TransferDirectionIn:
+		conditions = append(conditions, in)
+	case "":
+		conditions = append(conditions, "(("+out+") OR ("+in+"))")
+	default:
+		return nil, fmt.Errorf("unknown transfer direction %q", arg.Direction)
+	}
+
+	if arg.MinAmount != 0 {
+		conditions = append(conditions, "amount >= "+param(arg.MinAmount))
+	}
+	if arg.MaxAmount != 0 {
+		conditions = append(conditions, "amount <= "+param(arg.MaxAmount))
+	}
+	if !arg.From.IsZero() {
+		conditions = append(conditions, "created_at >= "+param(arg.From))
+	}
+	if !arg.To.IsZero() {
+		conditions = append(conditions, "created_at < "+param(arg.To))
+	}
+
+	comparison, direction := ">", "ASC"
+	if arg.Descending {
+		comparison, direction = "<", "DESC"
+	}
+
+	var order string
+	switch arg.SortBy {
+	case TransferSortDate, "":
+		// ids grow with creation time
+		order = "id " + direction
+		if arg.AfterID != 0 {
+			conditions = append(conditions, "id "+comparison+" "+param(arg.AfterID))
+		}
+	case TransferSortAmount:
+		order = "amount " + direction + ", id " + direction
+		if arg.AfterID != 0 {
+			conditions = append(conditions, fmt.Sprintf("(amount, id) %s (%s, %s)", comparison, param(arg.AfterAmount), param(arg.AfterID)))
+		}
+	default:
+		return nil, fmt.Errorf("unknown transfer sort %q", arg.SortBy)
+	}
+
+	query := fmt.Sprintf(`SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, rate_quoted_at, reversal_of, reversed_amount, fee FROM transfers
+WHERE %s
+ORDER BY %s
+LIMIT %s`, strings.Join(conditions, "\n  AND "), order, param(arg.Limit))
+
+	rows, err := store.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.ToAmount,
+			&i.ExchangeRate,
+			&i.RateQuotedAt,
+			&i.ReversalOf,
+			&i.ReversedAmount,
+			&i.Fee,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,93 @@
+package zigibankgo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterTransfers(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	account3 := createRandomAccount(t)
+
+	// the random balances may be too low for the transfers below
+	for _, account := range []Account{account1, account2} {
+		_, err := store.UpdateOverdraftLimit(context.Background(), UpdateOverdraftLimitParams{
+			ID:             account.ID,
+			OverdraftLimit: 100,
+		})
+		require.NoError(t, err)
+	}
+
+	transfer := func(from, to Account, amount int64) Transfer {
+		result, err := store.TransferTx(context.Background(), TransferTxParams{
+			FromAccountID: from.ID,
+			ToAccountID:   to.ID,
+			Amount:        amount,
+		})
+		require.NoError(t, err)
+		return result.Transfer
+	}
+
+	out1 := transfer(account1, account2, 30)
+	out2 := transfer(account1, account3, 10)
+	in1 := transfer(account2, account1, 20)
+
+	arg := FilterTransfersParams{
+		AccountIDs: []int64{account1.ID},
+		Limit:      10,
+	}
+
+	transfers, err := store.FilterTransfers(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, []int64{out1.ID, out2.ID, in1.ID}, transferIDs(transfers))
+
+	arg.Direction = TransferDirectionOut
+	transfers, err = store.FilterTransfers(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, []int64{out1.ID, out2.ID}, transferIDs(transfers))
+
+	arg.CounterpartyAccountID = account3.ID
+	transfers, err = store.FilterTransfers(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, []int64{out2.ID}, transferIDs(transfers))
+
+	// largest first, paged by amount and id
+	arg = FilterTransfersParams{
+		AccountIDs: []int64{account1.ID},
+		MinAmount:  15,
+		SortBy:     TransferSortAmount,
+		Descending: true,
+		Limit:      1,
+	}
+	transfers, err = store.FilterTransfers(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, []int64{out1.ID}, transferIDs(transfers))
+
+	arg.AfterID = transfers[0].ID
+	arg.AfterAmount = transfers[0].Amount
+	transfers, err = store.FilterTransfers(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, []int64{in1.ID}, transferIDs(transfers))
+
+	arg = FilterTransfersParams{
+		AccountIDs: []int64{account1.ID},
+		To:         time.Now().Add(-time.Hour),
+		Limit:      10,
+	}
+	transfers, err = store.FilterTransfers(context.Background(), arg)
+	require.NoError(t, err)
+	require.Empty(t, transfers)
+}
+
+func transferIDs(transfers []Transfer) []int64 {
+	ids := make([]int64, len(transfers))
+	for i, transfer := range transfers {
+		ids[i] = transfer.ID
+	}
+	return ids
+}
@@ -0,0 +1,144 @@
+package zigibankgo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// a transfer would go over the per-transfer or daily limit of its sender
+var ErrTransferLimitExceeded = errors.New("transfer limit exceeded")
+
+// Velocity limits of a user in one currency, in minor units
+type TransferLimits struct {
+	// most a single transfer may move, unlimited when zero
+	PerTransfer int64 `json:"per_transfer"`
+	// most the user may send in the currency per UTC day, pending holds included, unlimited when zero
+	Daily int64 `json:"daily"`
+}
+
+// checks that the sender of a transfer stays within its limits, the defaults unless an override is set
+// for the owner and currency of the source account. Outbound transfers of the owner in that currency
+// are serialized until the transaction ends, so concurrent transfers cannot both fit in the same allowance.
+// Pending holds take their part of the daily allowance until they are settled, settling is the amount
+// of the owner's holds the transfer itself captures, which is not counted twice
+func checkTransferLimits(ctx context.Context, q *Queries, fromAccountID int64, amount int64, settling int64, defaults TransferLimits) error {
+	account, err := q.GetAccount(ctx, fromAccountID)
+	if err != nil {
+		return err
+	}
+
+	err = q.LockTransferLimit(ctx, LockTransferLimitParams{
+		Owner:    account.Owner,
+		Currency: account.Currency,
+	})
+	if err != nil {
+		return err
+	}
+
+	limits := defaults
+	override, err := q.GetTransferLimit(ctx, GetTransferLimitParams{
+		Username: account.Owner,
+		Currency: account.Currency,
+	})
+	switch {
+	case err == nil:
+		limits = TransferLimits{PerTransfer: override.PerTransferLimit, Daily: override.DailyLimit}
+	case !errors.Is(err, sql.ErrNoRows):
+		return err
+	}
+
+	// the most the owner may still send right now, -1 when unlimited
+	remaining := int64(-1)
+	if limits.Daily > 0 {
+		sent, err := q.GetOutboundTransferTotal(ctx, GetOutboundTransferTotalParams{
+			Owner:    account.Owner,
+			Currency: account.Currency,
+			Since:    time.Now().UTC().Truncate(24 * time.Hour),
+		})
+		if err != nil {
+			return err
+		}
+
+		held, err := q.GetHeldAmountTotal(ctx, GetHeldAmountTotalParams{
+			Owner:    account.Owner,
+			Currency: account.Currency,
+		})
+		if err != nil {
+			return err
+		}
+		remaining = max(limits.Daily-sent-(held-settling), 0)
+	}
+	if limits.PerTransfer > 0 && (remaining < 0 || limits.PerTransfer < remaining) {
+		remaining = limits.PerTransfer
+	}
+
+	if limits.PerTransfer > 0 && amount > limits.PerTransfer {
+		return fmt.Errorf("%w: %d %s is over the per-transfer limit of %d, %d remaining",
+			ErrTransferLimitExceeded, amount, account.Currency, limits.PerTransfer, remaining)
+	}
+	if limits.Daily > 0 && amount > remaining {
+		return fmt.Errorf("%w: %d %s is over the daily limit of %d, %d remaining today",
+			ErrTransferLimitExceeded, amount, account.Currency, limits.Daily, remaining)
+	}
+	return nil
+}
+
+// Overrides the default transfer limits of a user in one currency within a database transaction
+func (store *SQLStore) SetTransferLimitTx(ctx context.Context, arg UpsertTransferLimitParams) (TransferLimit, error) {
+	var limit TransferLimit
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		before, err := currentTransferLimit(ctx, q, arg.Username, arg.Currency)
+		if err != nil {
+			return err
+		}
+
+		limit, err = q.UpsertTransferLimit(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		recordChange(ctx, AuditTargetTransferLimit, arg.Username+"/"+arg.Currency, before, limit)
+		return nil
+	})
+
+	return limit, err
+}
+
+// Removes the transfer limit override of a user in one currency within a database transaction
+func (store *SQLStore) DeleteTransferLimitTx(ctx context.Context, arg DeleteTransferLimitParams) error {
+	return store.execTx(ctx, func(q *Queries) error {
+		before, err := currentTransferLimit(ctx, q, arg.Username, arg.Currency)
+		if err != nil {
+			return err
+		}
+
+		err = q.DeleteTransferLimit(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		if before != nil {
+			recordChange(ctx, AuditTargetTransferLimit, arg.Username+"/"+arg.Currency, before, nil)
+		}
+		return nil
+	})
+}
+
+// returns the override of a user in one currency, nil when none is set
+func currentTransferLimit(ctx context.Context, q *Queries, username string, currency string) (any, error) {
+	limit, err := q.GetTransferLimit(ctx, GetTransferLimitParams{
+		Username: username,
+		Currency: currency,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return limit, nil
+}
@@ -0,0 +1,196 @@
+package zigibankgo
+
+import (
+	"BankAppGo/db/util"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransferLimits(t *testing.T) {
+	store := NewStore(testDB)
+	owner := createRandomUser(t)
+	other := createRandomAccount(t)
+
+	account, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    owner.Username,
+		Balance:  1000,
+		Currency: other.Currency,
+		Kind:     util.CheckingAccount,
+	})
+	require.NoError(t, err)
+
+	defaults := &TransferLimits{PerTransfer: 100, Daily: 1000}
+
+	// the override takes the place of the defaults
+	_, err = testQueries.UpsertTransferLimit(context.Background(), UpsertTransferLimitParams{
+		Username:         owner.Username,
+		Currency:         account.Currency,
+		PerTransferLimit: 10,
+		DailyLimit:       25,
+		UpdatedBy:        owner.Username,
+	})
+	require.NoError(t, err)
+
+	send := func(amount int64) error {
+		_, err := store.TransferTx(context.Background(), TransferTxParams{
+			FromAccountID: account.ID,
+			ToAccountID:   other.ID,
+			Amount:        amount,
+			Limits:        defaults,
+		})
+		return err
+	}
+
+	require.ErrorIs(t, send(11), ErrTransferLimitExceeded)
+	require.NoError(t, send(10))
+	require.NoError(t, send(10))
+
+	err = send(10)
+	require.ErrorIs(t, err, ErrTransferLimitExceeded)
+	require.Contains(t, err.Error(), "5 remaining today")
+	require.NoError(t, send(5))
+
+	// without the override the defaults apply again
+	err = testQueries.DeleteTransferLimit(context.Background(), DeleteTransferLimitParams{
+		Username: owner.Username,
+		Currency: account.Currency,
+	})
+	require.NoError(t, err)
+	require.ErrorIs(t, send(101), ErrTransferLimitExceeded)
+	require.NoError(t, send(100))
+
+	// transfers made without limits are still counted
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account.ID,
+		ToAccountID:   other.ID,
+		Amount:        100,
+	})
+	require.NoError(t, err)
+
+	sent, err := testQueries.GetOutboundTransferTotal(context.Background(), GetOutboundTransferTotalParams{
+		Owner:    owner.Username,
+		Currency: account.Currency,
+		Since:    account.CreatedAt.Add(-1),
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(225), sent)
+}
+
+func TestTransferLimitsConcurrent(t *testing.T) {
+	store := NewStore(testDB)
+	owner := createRandomUser(t)
+	other := createRandomAccount(t)
+
+	account, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    owner.Username,
+		Balance:  1000,
+		Currency: other.Currency,
+		Kind:     util.CheckingAccount,
+	})
+	require.NoError(t, err)
+
+	n := 6
+	errs := make(chan error)
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := store.TransferTx(context.Background(), TransferTxParams{
+				FromAccountID: account.ID,
+				ToAccountID:   other.ID,
+				Amount:        10,
+				Limits:        &TransferLimits{Daily: 35},
+			})
+			errs <- err
+		}()
+	}
+
+	var made int
+	for i := 0; i < n; i++ {
+		err := <-errs
+		if err == nil {
+			made++
+			continue
+		}
+		require.ErrorIs(t, err, ErrTransferLimitExceeded)
+	}
+	require.Equal(t, 3, made)
+}
+
+// transfers, holds and their captures of the same owner and currency share one daily allowance
+func TestTransferLimitsConcurrentEntryPoints(t *testing.T) {
+	store := NewStore(testDB)
+	owner := createRandomUser(t)
+	other := createRandomAccount(t)
+
+	account, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    owner.Username,
+		Balance:  1000,
+		Currency: other.Currency,
+		Kind:     util.CheckingAccount,
+	})
+	require.NoError(t, err)
+
+	limits := &TransferLimits{Daily: 35}
+
+	// the pending holds take 20 of the allowance, their captures always fit in it
+	holds := make([]Hold, 2)
+	for i := range holds {
+		holds[i], err = store.AuthorizeTransferTx(context.Background(), AuthorizeTransferTxParams{
+			FromAccountID: account.ID,
+			ToAccountID:   other.ID,
+			Amount:        10,
+			ExpiresAt:     time.Now().Add(time.Hour),
+			Limits:        limits,
+		})
+		require.NoError(t, err)
+	}
+
+	transferErrs := make(chan error)
+	captureErrs := make(chan error)
+	n := 3
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := store.TransferTx(context.Background(), TransferTxParams{
+				FromAccountID: account.ID,
+				ToAccountID:   other.ID,
+				Amount:        10,
+				Limits:        limits,
+			})
+			transferErrs <- err
+		}()
+	}
+	for _, hold := range holds {
+		go func(hold Hold) {
+			_, err := store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{
+				HoldID: hold.ID,
+				Limits: limits,
+			})
+			captureErrs <- err
+		}(hold)
+	}
+
+	for range holds {
+		require.NoError(t, <-captureErrs)
+	}
+
+	var made int
+	for i := 0; i < n; i++ {
+		err := <-transferErrs
+		if err == nil {
+			made++
+			continue
+		}
+		require.ErrorIs(t, err, ErrTransferLimitExceeded)
+	}
+	require.Equal(t, 1, made)
+
+	sent, err := testQueries.GetOutboundTransferTotal(context.Background(), GetOutboundTransferTotalParams{
+		Owner:    owner.Username,
+		Currency: account.Currency,
+		Since:    account.CreatedAt.Add(-1),
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(30), sent)
+}
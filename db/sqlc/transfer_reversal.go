@@ -0,0 +1,216 @@
+package zigibankgo
+
+import (
+	"BankAppGo/db/util"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"time"
+)
+
+// Different types of error returned when a transfer cannot be reversed
+var (
+	// everything the transfer moved was already sent back
+	ErrTransferReversed = errors.New("transfer was already reversed")
+	// a reversal cannot send back more than is left of the transfer
+	ErrReversalExceedsTransfer = errors.New("reversal exceeds what is left of the transfer")
+	// reversals cannot be reversed, and cross-currency transfers only in full
+	ErrReversalNotAllowed = errors.New("transfer cannot be reversed")
+)
+
+// decimal places of the rate recorded on the reversal of a cross-currency transfer
+const reversalRatePrecision = 10
+
+// Contains input parameters of the transfer reversal transactions
+type TransferReversalTxParams struct {
+	TransferID int64 `json:"transfer_id"`
+	// sent back to the source account, everything left of the transfer when zero
+	Amount      int64  `json:"amount"`
+	Reason      string `json:"reason"`
+	RequestedBy string `json:"requested_by"`
+}
+
+// Contains result of the transfer reversal transactions
+type TransferReversalTxResult struct {
+	Reversal TransferReversal `json:"reversal"`
+	// the reversal transfer, empty while the reversal is pending
+	TransferTxResult
+}
+
+// Sends money of a transfer back to its source account right away, with a linked
+// reversal transfer and two compensating entries, within a single database transaction
+func (store *SQLStore) ReverseTransferTx(ctx context.Context, arg TransferReversalTxParams) (TransferReversalTxResult, error) {
+	var result TransferReversalTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		original, err := q.GetTransferForUpdate(ctx, arg.TransferID)
+		if err != nil {
+			return err
+		}
+
+		amount, err := reversalAmount(original, arg.Amount)
+		if err != nil {
+			return err
+		}
+
+		result.TransferTxResult, err = reverseTransfer(ctx, q, original, amount)
+		if err != nil {
+			return err
+		}
+
+		result.Reversal, err = q.CreateTransferReversal(ctx, CreateTransferReversalParams{
+			TransferID:         original.ID,
+			Amount:             amount,
+			Reason:             arg.Reason,
+			RequestedBy:        arg.RequestedBy,
+			Status:             util.ReversalCompleted,
+			ReversalTransferID: sql.NullInt64{Int64: result.Transfer.ID, Valid: true},
+			CompletedAt:        sql.NullTime{Time: time.Now(), Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+
+		recordChange(ctx, AuditTargetReversal, strconv.FormatInt(result.Reversal.ID, 10), nil, result.Reversal)
+		return nil
+	})
+
+	return result, err
+}
+
+// Records a reversal that waits for the consent of the recipient. The amount is checked
+// against the transfer now and again when the reversal is approved.
+// A transfer has at most one pending reversal
+func (store *SQLStore) RequestTransferReversalTx(ctx context.Context, arg TransferReversalTxParams) (TransferReversal, error) {
+	var reversal TransferReversal
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		original, err := q.GetTransferForUpdate(ctx, arg.TransferID)
+		if err != nil {
+			return err
+		}
+
+		amount, err := reversalAmount(original, arg.Amount)
+		if err != nil {
+			return err
+		}
+
+		reversal, err = q.CreateTransferReversal(ctx, CreateTransferReversalParams{
+			TransferID:  original.ID,
+			Amount:      amount,
+			Reason:      arg.Reason,
+			RequestedBy: arg.RequestedBy,
+			Status:      util.ReversalPending,
+		})
+		if err != nil {
+			return err
+		}
+
+		recordChange(ctx, AuditTargetReversal, strconv.FormatInt(reversal.ID, 10), nil, reversal)
+		return nil
+	})
+
+	return reversal, err
+}
+
+// Carries out the pending reversal of a transfer once its recipient consents.
+// Returns sql.ErrNoRows when the transfer has no pending reversal
+func (store *SQLStore) ApproveTransferReversalTx(ctx context.Context, transferID int64) (TransferReversalTxResult, error) {
+	var result TransferReversalTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		original, err := q.GetTransferForUpdate(ctx, transferID)
+		if err != nil {
+			return err
+		}
+
+		reversal, err := q.GetPendingTransferReversalForUpdate(ctx, transferID)
+		if err != nil {
+			return err
+		}
+
+		// other reversals may have been made since the request
+		amount, err := reversalAmount(original, reversal.Amount)
+		if err != nil {
+			return err
+		}
+
+		result.TransferTxResult, err = reverseTransfer(ctx, q, original, amount)
+		if err != nil {
+			return err
+		}
+
+		result.Reversal, err = q.CompleteTransferReversal(ctx, CompleteTransferReversalParams{
+			ID:                 reversal.ID,
+			ReversalTransferID: sql.NullInt64{Int64: result.Transfer.ID, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+
+		recordChange(ctx, AuditTargetReversal, strconv.FormatInt(reversal.ID, 10), reversal, result.Reversal)
+		return nil
+	})
+
+	return result, err
+}
+
+// checks that amount can still be sent back for a locked transfer, zero meaning all that is left
+func reversalAmount(original Transfer, amount int64) (int64, error) {
+	if original.ReversalOf.Valid {
+		return 0, fmt.Errorf("%w: transfer %d is a reversal", ErrReversalNotAllowed, original.ID)
+	}
+
+	// the fee is kept by the bank, only what was sent on can come back
+	sent := original.Amount - original.Fee
+	left := sent - original.ReversedAmount
+	if left == 0 {
+		return 0, fmt.Errorf("%w: transfer %d", ErrTransferReversed, original.ID)
+	}
+	if amount == 0 {
+		amount = left
+	}
+	if amount > left {
+		return 0, fmt.Errorf("%w: transfer %d has %d left, reversal needs %d", ErrReversalExceedsTransfer, original.ID, left, amount)
+	}
+
+	// a partial amount has no exact counterpart at the rate the transfer was made at
+	if original.ToAmount != sent && amount != sent {
+		return 0, fmt.Errorf("%w: cross-currency transfer %d can only be reversed in full", ErrReversalNotAllowed, original.ID)
+	}
+	return amount, nil
+}
+
+// sends amount back from the recipient to the source of the locked original transfer
+func reverseTransfer(ctx context.Context, q *Queries, original Transfer, amount int64) (TransferTxResult, error) {
+	arg := CreateTransferParams{
+		FromAccountID: original.ToAccountID,
+		ToAccountID:   original.FromAccountID,
+		Amount:        amount,
+		ToAmount:      amount,
+		ExchangeRate:  "1",
+		ReversalOf:    sql.NullInt64{Int64: original.ID, Valid: true},
+	}
+	sent := original.Amount - original.Fee
+	if original.ToAmount != sent {
+		// the recipient gives back what it was credited, the source gets back what was sent on after the fee
+		arg.Amount = original.ToAmount
+		arg.ToAmount = sent
+		arg.ExchangeRate = new(big.Rat).SetFrac64(sent, original.ToAmount).FloatString(reversalRatePrecision)
+		arg.RateQuotedAt = original.RateQuotedAt
+	}
+
+	result, err := transfer(ctx, q, arg)
+	if err != nil {
+		return result, err
+	}
+
+	_, err = q.AddTransferReversedAmount(ctx, AddTransferReversedAmountParams{
+		ID:     original.ID,
+		Amount: amount,
+	})
+	return result, err
+}
@@ -0,0 +1,116 @@
+package zigibankgo
+
+import (
+	"BankAppGo/db/util"
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// records a transfer of amount between two fresh accounts, leaving balances untouched
+func createReversibleTransfer(t *testing.T, store Store, amount int64) (Account, Account, Transfer) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	// the recipient must be able to give the money back whatever its random balance
+	_, err := store.UpdateOverdraftLimit(context.Background(), UpdateOverdraftLimitParams{
+		ID:             account2.ID,
+		OverdraftLimit: amount,
+	})
+	require.NoError(t, err)
+
+	transfer, err := store.CreateTransfer(context.Background(), CreateTransferParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        amount,
+		ToAmount:      amount,
+		ExchangeRate:  "1",
+	})
+	require.NoError(t, err)
+
+	return account1, account2, transfer
+}
+
+func TestReverseTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+	account1, account2, transfer := createReversibleTransfer(t, store, 100)
+
+	arg := TransferReversalTxParams{
+		TransferID:  transfer.ID,
+		Amount:      30,
+		Reason:      "wrong amount",
+		RequestedBy: account1.Owner,
+	}
+
+	result, err := store.ReverseTransferTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, util.ReversalCompleted, result.Reversal.Status)
+	require.Equal(t, int64(30), result.Reversal.Amount)
+	require.True(t, result.Reversal.CompletedAt.Valid)
+	require.Equal(t, result.Transfer.ID, result.Reversal.ReversalTransferID.Int64)
+
+	require.Equal(t, account2.ID, result.Transfer.FromAccountID)
+	require.Equal(t, account1.ID, result.Transfer.ToAccountID)
+	require.Equal(t, transfer.ID, result.Transfer.ReversalOf.Int64)
+	require.Equal(t, account1.Balance+30, result.ToAccount.Balance)
+	require.Equal(t, account2.Balance-30, result.FromAccount.Balance)
+
+	// zero sends back everything left
+	arg.Amount = 0
+	result, err = store.ReverseTransferTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, int64(70), result.Reversal.Amount)
+
+	original, err := store.GetTransferFromId(context.Background(), transfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, original.Amount, original.ReversedAmount)
+
+	_, err = store.ReverseTransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrTransferReversed)
+
+	// a reversal cannot itself be reversed
+	arg.TransferID = result.Transfer.ID
+	_, err = store.ReverseTransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrReversalNotAllowed)
+}
+
+func TestApproveTransferReversalTx(t *testing.T) {
+	store := NewStore(testDB)
+	account1, _, transfer := createReversibleTransfer(t, store, 50)
+
+	arg := TransferReversalTxParams{
+		TransferID:  transfer.ID,
+		Amount:      60,
+		Reason:      "sent twice",
+		RequestedBy: account1.Owner,
+	}
+
+	_, err := store.RequestTransferReversalTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrReversalExceedsTransfer)
+
+	arg.Amount = 20
+	reversal, err := store.RequestTransferReversalTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, util.ReversalPending, reversal.Status)
+	require.False(t, reversal.ReversalTransferID.Valid)
+
+	// nothing moves until the recipient approves
+	account, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, account.Balance)
+
+	// a transfer has at most one pending reversal
+	_, err = store.RequestTransferReversalTx(context.Background(), arg)
+	require.Error(t, err)
+
+	result, err := store.ApproveTransferReversalTx(context.Background(), transfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, reversal.ID, result.Reversal.ID)
+	require.Equal(t, util.ReversalCompleted, result.Reversal.Status)
+	require.Equal(t, account1.Balance+20, result.ToAccount.Balance)
+
+	_, err = store.ApproveTransferReversalTx(context.Background(), transfer.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
@@ -7,25 +7,71 @@ package zigibankgo
 
 import (
 	"context"
+	"database/sql"
 )
 
+const addTransferReversedAmount = `-- name: AddTransferReversedAmount :one
+UPDATE transfers
+SET reversed_amount = reversed_amount + $1
+WHERE id = $2
+RETURNING id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, rate_quoted_at, reversal_of, reversed_amount, fee
+`
+
+type AddTransferReversedAmountParams struct {
+	Amount int64
+	ID     int64
+}
+
+func (q *Queries) AddTransferReversedAmount(ctx context.Context, arg AddTransferReversedAmountParams) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, addTransferReversedAmount, arg.Amount, arg.ID)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.RateQuotedAt,
+		&i.ReversalOf,
+		&i.ReversedAmount,
+		&i.Fee,
+	)
+	return i, err
+}
+
 const createTransfer = `-- name: CreateTransfer :one
 INSERT INTO transfers (
-  from_account_id, to_account_id, amount
+  from_account_id, to_account_id, amount, to_amount, exchange_rate, rate_quoted_at, reversal_of, fee
 ) VALUES (
-  $1, $2, $3
+  $1, $2, $3, $4, $5, $6, $7, $8
 )
-RETURNING id, from_account_id, to_account_id, amount, created_at
+RETURNING id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, rate_quoted_at, reversal_of, reversed_amount, fee
 `
 
 type CreateTransferParams struct {
 	FromAccountID int64
 	ToAccountID   int64
 	Amount        int64
+	ToAmount      int64
+	ExchangeRate  string
+	RateQuotedAt  sql.NullTime
+	ReversalOf    sql.NullInt64
+	Fee           int64
 }
 
 func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
-	row := q.db.QueryRowContext(ctx, createTransfer, arg.FromAccountID, arg.ToAccountID, arg.Amount)
+	row := q.db.QueryRowContext(ctx, createTransfer,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.ToAmount,
+		arg.ExchangeRate,
+		arg.RateQuotedAt,
+		arg.ReversalOf,
+		arg.Fee,
+	)
 	var i Transfer
 	err := row.Scan(
 		&i.ID,
@@ -33,12 +79,18 @@ func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams)
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.RateQuotedAt,
+		&i.ReversalOf,
+		&i.ReversedAmount,
+		&i.Fee,
 	)
 	return i, err
 }
 
 const getTransfer = `-- name: GetTransfer :one
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
+SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, rate_quoted_at, reversal_of, reversed_amount, fee FROM transfers
 WHERE from_account_id = $1 AND to_account_id = $2
 LIMIT 1
 `
@@ -57,12 +109,44 @@ func (q *Queries) GetTransfer(ctx context.Context, arg GetTransferParams) (Trans
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.RateQuotedAt,
+		&i.ReversalOf,
+		&i.ReversedAmount,
+		&i.Fee,
+	)
+	return i, err
+}
+
+const getTransferForUpdate = `-- name: GetTransferForUpdate :one
+SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, rate_quoted_at, reversal_of, reversed_amount, fee FROM transfers
+WHERE id = $1
+LIMIT 1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, getTransferForUpdate, id)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.RateQuotedAt,
+		&i.ReversalOf,
+		&i.ReversedAmount,
+		&i.Fee,
 	)
 	return i, err
 }
 
 const getTransferFromId = `-- name: GetTransferFromId :one
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
+SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, rate_quoted_at, reversal_of, reversed_amount, fee FROM transfers
 WHERE id = $1
 `
 
@@ -75,12 +159,18 @@ func (q *Queries) GetTransferFromId(ctx context.Context, id int64) (Transfer, er
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.RateQuotedAt,
+		&i.ReversalOf,
+		&i.ReversedAmount,
+		&i.Fee,
 	)
 	return i, err
 }
 
 const listTransfers = `-- name: ListTransfers :many
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
+SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, rate_quoted_at, reversal_of, reversed_amount, fee FROM transfers
 `
 
 func (q *Queries) ListTransfers(ctx context.Context) ([]Transfer, error) {
@@ -98,6 +188,12 @@ func (q *Queries) ListTransfers(ctx context.Context) ([]Transfer, error) {
 			&i.ToAccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.ToAmount,
+			&i.ExchangeRate,
+			&i.RateQuotedAt,
+			&i.ReversalOf,
+			&i.ReversedAmount,
+			&i.Fee,
 		); err != nil {
 			return nil, err
 		}
@@ -30,6 +30,7 @@ func createRandomUser(t *testing.T) User {
 
 	require.True(t, user.PasswordChangedAt.IsZero())
 	require.NotZero(t, user.CreatedAt)
+	require.Equal(t, util.DepositorRole, user.Role)
 
 	return user
 }
@@ -51,3 +52,21 @@ func TestGetUser(t *testing.T) {
 	require.WithinDuration(t, user1.PasswordChangedAt, user2.PasswordChangedAt, time.Second)
 	require.WithinDuration(t, user1.CreatedAt, user2.CreatedAt, time.Second)
 }
+
+func TestUpdateUserRole(t *testing.T) {
+	user1 := createRandomUser(t)
+
+	user2, err := testQueries.UpdateUserRole(context.Background(), UpdateUserRoleParams{
+		Username: user1.Username,
+		Role:     util.BankerRole,
+	})
+	require.NoError(t, err)
+	require.Equal(t, user1.Username, user2.Username)
+	require.Equal(t, util.BankerRole, user2.Role)
+
+	_, err = testQueries.UpdateUserRole(context.Background(), UpdateUserRoleParams{
+		Username: user1.Username,
+		Role:     "superuser",
+	})
+	require.Error(t, err)
+}
@@ -0,0 +1,41 @@
+package zigibankgo
+
+import (
+	"context"
+)
+
+// Creates a user within a database transaction
+func (store *SQLStore) CreateUserTx(ctx context.Context, arg CreateUserParams) (User, error) {
+	var user User
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		user, err = q.CreateUser(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		recordChange(ctx, AuditTargetUser, user.Username, nil, newAuditedUser(user))
+		return nil
+	})
+
+	return user, err
+}
+
+// Creates the session of a refresh token within a database transaction
+func (store *SQLStore) CreateSessionTx(ctx context.Context, arg CreateSessionParams) (Session, error) {
+	var session Session
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		session, err = q.CreateSession(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		recordChange(ctx, AuditTargetSession, session.ID.String(), nil, newAuditedSession(session))
+		return nil
+	})
+
+	return session, err
+}
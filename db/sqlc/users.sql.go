@@ -14,7 +14,7 @@ INSERT INTO users ( username, hashed_password, email, full_name)
 VALUES ( $1,
          $2,
          $3,
-         $4) RETURNING username, hashed_password, full_name, email, password_changed_at, created_at
+         $4) RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, role
 `
 
 type CreateUserParams struct {
@@ -39,12 +39,13 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.Email,
 		&i.PasswordChangedAt,
 		&i.CreatedAt,
+		&i.Role,
 	)
 	return i, err
 }
 
 const getUser = `-- name: GetUser :one
-SELECT  username, hashed_password, full_name, email, password_changed_at, created_at
+SELECT  username, hashed_password, full_name, email, password_changed_at, created_at, role
 FROM users
 WHERE username = $1
 LIMIT 1
@@ -60,6 +61,34 @@ func (q *Queries) GetUser(ctx context.Context, username string) (User, error) {
 		&i.Email,
 		&i.PasswordChangedAt,
 		&i.CreatedAt,
+		&i.Role,
+	)
+	return i, err
+}
+
+const updateUserRole = `-- name: UpdateUserRole :one
+UPDATE users
+SET role = $2
+WHERE username = $1
+RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, role
+`
+
+type UpdateUserRoleParams struct {
+	Username string
+	Role     string
+}
+
+func (q *Queries) UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserRole, arg.Username, arg.Role)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Role,
 	)
 	return i, err
 }
@@ -0,0 +1,11 @@
+package util
+
+// States of an account
+const (
+	// debited and credited as usual
+	AccountActive = "active"
+	// blocked by compliance, neither debited nor credited until unfrozen
+	AccountFrozen = "frozen"
+	// no longer used, its entries and transfers are kept
+	AccountClosed = "closed"
+)
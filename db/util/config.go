@@ -9,11 +9,32 @@ import (
 // Stores all configurations of the app
 // The values are read by viper from a config file or environment variables
 type Config struct {
-	DBDriver            string        `mapstructure:"DB_DRIVER"`
-	DBSource            string        `mapstructure:"DB_SOURCE"`
-	ServerAddress       string        `mapstructure:"SERVER_ADDRESS"`
-	TokenSymmetricKey   string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
-	AccessTokenDuration time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
+	DBDriver             string        `mapstructure:"DB_DRIVER"`
+	DBSource             string        `mapstructure:"DB_SOURCE"`
+	ServerAddress        string        `mapstructure:"SERVER_ADDRESS"`
+	TokenSymmetricKey    string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
+	AccessTokenDuration  time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
+	RefreshTokenDuration time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
+	FXRatesFile          string        `mapstructure:"FX_RATES_FILE"`
+	// where the gRPC BankService listens, not served when empty
+	GRPCServerAddress string `mapstructure:"GRPC_SERVER_ADDRESS"`
+	// how often token revocations made by other servers are picked up
+	RevocationSyncInterval time.Duration `mapstructure:"REVOCATION_SYNC_INTERVAL"`
+	// how often due scheduled transfers are looked for and run
+	ScheduledTransferInterval time.Duration `mapstructure:"SCHEDULED_TRANSFER_INTERVAL"`
+	// how long an authorized transfer holds its money before it expires
+	HoldTTL time.Duration `mapstructure:"HOLD_TTL"`
+	// how long after a transfer its sender may ask for it to be reversed
+	ReversalGracePeriod time.Duration `mapstructure:"REVERSAL_GRACE_PERIOD"`
+	// most a single transfer may move in minor units, unless overridden for the user. Unlimited when zero
+	TransferLimit int64 `mapstructure:"TRANSFER_LIMIT"`
+	// most a user may send per currency and UTC day in minor units, unless overridden. Unlimited when zero
+	DailyTransferLimit int64 `mapstructure:"DAILY_TRANSFER_LIMIT"`
+}
+
+// longest a token issued with this configuration stays valid, a revocation of all tokens of a user must last as long
+func (config Config) TokenLifetime() time.Duration {
+	return max(config.AccessTokenDuration, config.RefreshTokenDuration)
 }
 
 func LoadConfig(path string) (config Config, err error) {
@@ -1,5 +1,7 @@
 package util
 
+import "slices"
+
 const (
 	EUR  = "EUR"
 	CAD  = "CAD"
@@ -14,10 +16,9 @@ const (
 	CNY  = "CNY"
 )
 
+// every currency accounts can be held in
+var SupportedCurrencies = []string{EUR, CAD, USD, GBP, GHS, NGN, KES, FCFA, ZAR, YEN, CNY}
+
 func IsSupportedCurrency(currency string) bool {
-	switch currency {
-	case EUR, CAD, USD, GBP, GHS, NGN, KES, FCFA, ZAR, CNY:
-		return true
-	}
-	return false
+	return slices.Contains(SupportedCurrencies, currency)
 }
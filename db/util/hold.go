@@ -0,0 +1,13 @@
+package util
+
+// States of a hold
+const (
+	// reserves its amount on the source account
+	HoldPending = "pending"
+	// settled by a transfer
+	HoldCaptured = "captured"
+	// released by its owner
+	HoldVoided = "voided"
+	// released because it was not captured in time
+	HoldExpired = "expired"
+)
@@ -0,0 +1,66 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// Kinds of account
+const (
+	// for day to day payments, earns no interest unless a rate is set for it
+	CheckingAccount = "checking"
+	// earns interest on its end-of-day balance
+	SavingsAccount = "savings"
+)
+
+var ErrInvalidInterestRate = errors.New("annual interest rate must be a decimal between 0 and 1")
+
+func IsSupportedAccountKind(kind string) bool {
+	switch kind {
+	case CheckingAccount, SavingsAccount:
+		return true
+	}
+	return false
+}
+
+// Parses an annual rate written as a decimal fraction, "0.035" being 3.5% a year
+func ParseInterestRate(rate string) (*big.Rat, error) {
+	r, ok := new(big.Rat).SetString(rate)
+	if !ok || r.Sign() < 0 || r.Cmp(big.NewRat(1, 1)) > 0 {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidInterestRate, rate)
+	}
+	return r, nil
+}
+
+// Returns the interest earned on day by an end-of-day balance at an annual rate, counting the actual
+// days of the year and rounding to the nearest minor unit with ties to even. Balances below zero earn nothing
+func DailyInterest(balance int64, annualRate string, day time.Time) (int64, error) {
+	rate, err := ParseInterestRate(annualRate)
+	if err != nil {
+		return 0, err
+	}
+	if balance <= 0 {
+		return 0, nil
+	}
+
+	year := day.Year()
+	days := time.Date(year+1, time.January, 1, 0, 0, 0, 0, time.UTC).Sub(time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)).Hours() / 24
+
+	interest := new(big.Rat).Mul(new(big.Rat).SetInt64(balance), rate)
+	interest.Quo(interest, big.NewRat(int64(days), 1))
+	return roundHalfEven(interest), nil
+}
+
+// rounds a positive r no larger than an int64 to an integer with ties to even
+func roundHalfEven(r *big.Rat) int64 {
+	quo, rem := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int))
+
+	twiceRem := rem.Lsh(rem, 1)
+	cmp := twiceRem.Cmp(r.Denom())
+	if cmp > 0 || (cmp == 0 && quo.Bit(0) == 1) {
+		quo.Add(quo, big.NewInt(1))
+	}
+	return quo.Int64()
+}
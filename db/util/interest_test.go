@@ -0,0 +1,49 @@
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDailyInterest(t *testing.T) {
+	day := time.Date(2025, time.March, 10, 0, 0, 0, 0, time.UTC)
+	leapDay := time.Date(2024, time.March, 10, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name     string
+		balance  int64
+		rate     string
+		day      time.Time
+		expected int64
+	}{
+		{name: "Exact", balance: 365000, rate: "0.1", day: day, expected: 100},
+		{name: "LeapYear", balance: 366000, rate: "0.1", day: leapDay, expected: 100},
+		{name: "RoundUp", balance: 100000, rate: "0.05", day: day, expected: 14},
+		{name: "RoundDown", balance: 100000, rate: "0.06", day: day, expected: 16},
+		// 36500 * 0.01 / 365 = 1, 54750 * 0.01 / 365 = 1.5 and 91250 * 0.01 / 365 = 2.5
+		{name: "TieToEvenUp", balance: 54750, rate: "0.01", day: day, expected: 2},
+		{name: "TieToEvenDown", balance: 91250, rate: "0.01", day: day, expected: 2},
+		{name: "ZeroRate", balance: 100000, rate: "0", day: day, expected: 0},
+		{name: "NegativeBalance", balance: -100000, rate: "0.05", day: day, expected: 0},
+		{name: "TooSmall", balance: 100, rate: "0.05", day: day, expected: 0},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			interest, err := DailyInterest(tc.balance, tc.rate, tc.day)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, interest)
+		})
+	}
+}
+
+func TestDailyInterestInvalidRate(t *testing.T) {
+	for _, rate := range []string{"", "abc", "-0.01", "1.5"} {
+		_, err := DailyInterest(1000, rate, time.Now())
+		require.ErrorIs(t, err, ErrInvalidInterestRate)
+	}
+}
@@ -0,0 +1,28 @@
+package util
+
+// What a journal posting records
+const (
+	// money held by customer accounts before the general ledger
+	OpeningJournal  = "opening"
+	TransferJournal = "transfer"
+	// money paid in from outside the bank
+	DepositJournal = "deposit"
+	// money paid out of the bank
+	WithdrawalJournal = "withdrawal"
+	// correction made by an admin
+	AdjustmentJournal = "adjustment"
+	// interest paid on savings
+	InterestJournal = "interest"
+)
+
+// Accounts the bank holds in every currency to balance postings
+const (
+	// counterpart of money entering or leaving the bank
+	CashClearingAccount = "cash_clearing"
+	// collects the fees charged to customers
+	FeesIncomeAccount = "fees_income"
+	// carries cross-currency transfers between the two currencies
+	FXSuspenseAccount = "fx_suspense"
+	// pays the interest earned by customers
+	InterestExpenseAccount = "interest_expense"
+)
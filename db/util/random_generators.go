@@ -33,8 +33,7 @@ func RandomMoney() int64 {
 }
 
 func RandomCurrency() string {
-	currencies := []string{EUR, CAD, USD, GBP, GHS, NGN, KES, FCFA, ZAR, YEN, CNY}
-	return currencies[rand.Intn(len(currencies))]
+	return SupportedCurrencies[rand.Intn(len(SupportedCurrencies))]
 }
 
 func RandomEmail() string {
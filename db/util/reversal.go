@@ -0,0 +1,9 @@
+package util
+
+// States of a transfer reversal
+const (
+	// requested by the sender, waits for the recipient to consent
+	ReversalPending = "pending"
+	// the money was sent back
+	ReversalCompleted = "completed"
+)
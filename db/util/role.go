@@ -0,0 +1,19 @@
+package util
+
+// Roles a user can hold
+const (
+	// owns accounts and may only act on them
+	DepositorRole = "depositor"
+	// may read any account and book deposits and withdrawals
+	BankerRole = "banker"
+	// may also change accounts they do not own
+	AdminRole = "admin"
+)
+
+func IsSupportedRole(role string) bool {
+	switch role {
+	case DepositorRole, BankerRole, AdminRole:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+package util
+
+import "time"
+
+// How often a scheduled transfer runs
+const (
+	OnceCadence    = "once"
+	DailyCadence   = "daily"
+	WeeklyCadence  = "weekly"
+	MonthlyCadence = "monthly"
+)
+
+// States of a scheduled transfer
+const (
+	// runs when due
+	ScheduleActive = "active"
+	// kept but skipped by the executor until resumed
+	SchedulePaused = "paused"
+	// made its last run
+	ScheduleCompleted = "completed"
+	// stopped by its owner, cannot be resumed
+	ScheduleCancelled = "cancelled"
+)
+
+func IsSupportedCadence(cadence string) bool {
+	switch cadence {
+	case OnceCadence, DailyCadence, WeeklyCadence, MonthlyCadence:
+		return true
+	}
+	return false
+}
+
+// Returns the run following previous for a schedule that first ran at start, and false
+// when there is none. Monthly runs keep the day of start, moved to the last day of
+// shorter months, so a schedule starting on the 31st runs on Feb 28 then Mar 31
+func NextRun(cadence string, start time.Time, previous time.Time) (time.Time, bool) {
+	switch cadence {
+	case DailyCadence:
+		return previous.AddDate(0, 0, 1), true
+	case WeeklyCadence:
+		return previous.AddDate(0, 0, 7), true
+	case MonthlyCadence:
+		year, month, _ := previous.Date()
+		// day 0 of the month after next is the last day of next month
+		last := time.Date(year, month+2, 0, 0, 0, 0, 0, previous.Location()).Day()
+		day := min(start.Day(), last)
+		hour, minute, second := start.Clock()
+		return time.Date(year, month+1, day, hour, minute, second, start.Nanosecond(), previous.Location()), true
+	}
+	return time.Time{}, false
+}
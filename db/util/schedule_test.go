@@ -0,0 +1,71 @@
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNextRun(t *testing.T) {
+	start := time.Date(2024, time.January, 31, 9, 30, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name     string
+		cadence  string
+		previous time.Time
+		next     time.Time
+		ok       bool
+	}{
+		{
+			name:     "Once",
+			cadence:  OnceCadence,
+			previous: start,
+			ok:       false,
+		},
+		{
+			name:     "Daily",
+			cadence:  DailyCadence,
+			previous: start,
+			next:     time.Date(2024, time.February, 1, 9, 30, 0, 0, time.UTC),
+			ok:       true,
+		},
+		{
+			name:     "Weekly",
+			cadence:  WeeklyCadence,
+			previous: start,
+			next:     time.Date(2024, time.February, 7, 9, 30, 0, 0, time.UTC),
+			ok:       true,
+		},
+		{
+			name:     "MonthlyShorterMonth",
+			cadence:  MonthlyCadence,
+			previous: start,
+			next:     time.Date(2024, time.February, 29, 9, 30, 0, 0, time.UTC),
+			ok:       true,
+		},
+		{
+			name:     "MonthlyKeepsStartDay",
+			cadence:  MonthlyCadence,
+			previous: time.Date(2024, time.February, 29, 9, 30, 0, 0, time.UTC),
+			next:     time.Date(2024, time.March, 31, 9, 30, 0, 0, time.UTC),
+			ok:       true,
+		},
+		{
+			name:     "MonthlyAcrossYear",
+			cadence:  MonthlyCadence,
+			previous: time.Date(2024, time.December, 31, 9, 30, 0, 0, time.UTC),
+			next:     time.Date(2025, time.January, 31, 9, 30, 0, 0, time.UTC),
+			ok:       true,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			next, ok := NextRun(tc.cadence, start, tc.previous)
+			require.Equal(t, tc.ok, ok)
+			require.True(t, tc.next.Equal(next), "got %v, want %v", next, tc.next)
+		})
+	}
+}
@@ -0,0 +1,7 @@
+// Package swagger embeds the OpenAPI document generated from the protos in proto/, see the proto target of the Makefile
+package swagger
+
+import _ "embed"
+
+//go:embed bank.swagger.json
+var Bank []byte
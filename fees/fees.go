@@ -0,0 +1,145 @@
+// Package fees computes the fee charged on a transfer from a fee schedule
+package fees
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// Kinds of fee schedule
+const (
+	// the same fee whatever the amount
+	Flat = "flat"
+	// a share of the amount
+	Percentage = "percentage"
+	// a flat fee and a share of the amount that depend on the tier the amount falls in
+	Tiered = "tiered"
+)
+
+// basis points in a whole
+const bpsPerUnit = 10000
+
+var ErrInvalidSchedule = errors.New("invalid fee schedule")
+
+// Tier of a tiered schedule, its fee applies to the whole amount
+type Tier struct {
+	// largest amount in the tier, zero on the last tier which has no upper bound
+	UpTo    int64 `json:"up_to"`
+	Flat    int64 `json:"flat"`
+	RateBps int64 `json:"rate_bps"`
+}
+
+// Schedule sets how the fee of an amount is computed, in minor units of the amount's currency
+type Schedule struct {
+	Kind string `json:"kind"`
+	// fee of a flat schedule
+	Flat int64 `json:"flat"`
+	// basis points of the amount taken by a percentage schedule
+	RateBps int64  `json:"rate_bps"`
+	Tiers   []Tier `json:"tiers,omitempty"`
+	// the fee is raised to Min and lowered to Max, no cap when Max is zero
+	Min int64 `json:"min"`
+	Max int64 `json:"max"`
+}
+
+// Validate reports whether the schedule gives a fee for every amount
+func (schedule Schedule) Validate() error {
+	if schedule.Flat < 0 || schedule.Min < 0 || schedule.Max < 0 {
+		return fmt.Errorf("%w: fees and caps cannot be negative", ErrInvalidSchedule)
+	}
+	if schedule.Max != 0 && schedule.Max < schedule.Min {
+		return fmt.Errorf("%w: max %d is below min %d", ErrInvalidSchedule, schedule.Max, schedule.Min)
+	}
+	err := validRate(schedule.RateBps)
+	if err != nil {
+		return err
+	}
+
+	switch schedule.Kind {
+	case Flat, Percentage:
+		if len(schedule.Tiers) != 0 {
+			return fmt.Errorf("%w: only tiered schedules have tiers", ErrInvalidSchedule)
+		}
+		return nil
+	case Tiered:
+		return validTiers(schedule.Tiers)
+	}
+	return fmt.Errorf("%w: unsupported kind %q", ErrInvalidSchedule, schedule.Kind)
+}
+
+// checks that the tiers are ordered and the last one is unbounded
+func validTiers(tiers []Tier) error {
+	if len(tiers) == 0 {
+		return fmt.Errorf("%w: a tiered schedule needs tiers", ErrInvalidSchedule)
+	}
+
+	var lastUpTo int64
+	for i, tier := range tiers {
+		if tier.Flat < 0 {
+			return fmt.Errorf("%w: tier %d has a negative fee", ErrInvalidSchedule, i)
+		}
+		err := validRate(tier.RateBps)
+		if err != nil {
+			return err
+		}
+
+		last := i == len(tiers)-1
+		if last && tier.UpTo != 0 {
+			return fmt.Errorf("%w: the last tier cannot have an upper bound", ErrInvalidSchedule)
+		}
+		if !last && tier.UpTo <= lastUpTo {
+			return fmt.Errorf("%w: tier %d must go above %d", ErrInvalidSchedule, i, lastUpTo)
+		}
+		lastUpTo = tier.UpTo
+	}
+	return nil
+}
+
+func validRate(rateBps int64) error {
+	if rateBps < 0 || rateBps > bpsPerUnit {
+		return fmt.Errorf("%w: rate of %d basis points is not between 0 and %d", ErrInvalidSchedule, rateBps, bpsPerUnit)
+	}
+	return nil
+}
+
+// Fee returns the fee of a positive amount, shares of the amount are rounded half up to the minor unit
+func (schedule Schedule) Fee(amount int64) (int64, error) {
+	err := schedule.Validate()
+	if err != nil {
+		return 0, err
+	}
+	if amount <= 0 {
+		return 0, fmt.Errorf("amount %d must be positive", amount)
+	}
+
+	var fee int64
+	switch schedule.Kind {
+	case Flat:
+		fee = schedule.Flat
+	case Percentage:
+		fee = share(amount, schedule.RateBps)
+	case Tiered:
+		tier := schedule.Tiers[len(schedule.Tiers)-1]
+		for _, t := range schedule.Tiers {
+			if t.UpTo != 0 && amount <= t.UpTo {
+				tier = t
+				break
+			}
+		}
+		fee = tier.Flat + share(amount, tier.RateBps)
+	}
+
+	fee = max(fee, schedule.Min)
+	if schedule.Max != 0 {
+		fee = min(fee, schedule.Max)
+	}
+	return fee, nil
+}
+
+// rateBps basis points of amount, rounded half up. It never exceeds amount so it fits in an int64
+func share(amount int64, rateBps int64) int64 {
+	product := new(big.Int).Mul(big.NewInt(amount), big.NewInt(rateBps))
+	product.Add(product, big.NewInt(bpsPerUnit/2))
+	return product.Quo(product, big.NewInt(bpsPerUnit)).Int64()
+}
@@ -0,0 +1,71 @@
+package fees
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduleFee(t *testing.T) {
+	tiers := []Tier{
+		{UpTo: 1000, Flat: 10},
+		{UpTo: 10000, Flat: 5, RateBps: 100},
+		{RateBps: 50},
+	}
+
+	testCases := []struct {
+		name     string
+		schedule Schedule
+		amount   int64
+		expected int64
+	}{
+		{name: "Flat", schedule: Schedule{Kind: Flat, Flat: 25}, amount: 1, expected: 25},
+		{name: "Percentage", schedule: Schedule{Kind: Percentage, RateBps: 150}, amount: 1000, expected: 15},
+		{name: "PercentageRoundsHalfUp", schedule: Schedule{Kind: Percentage, RateBps: 50}, amount: 100, expected: 1},
+		{name: "PercentageRoundsDown", schedule: Schedule{Kind: Percentage, RateBps: 40}, amount: 100, expected: 0},
+		{name: "Min", schedule: Schedule{Kind: Percentage, RateBps: 100, Min: 30}, amount: 1000, expected: 30},
+		{name: "Max", schedule: Schedule{Kind: Percentage, RateBps: 100, Max: 50}, amount: 100000, expected: 50},
+		{name: "FirstTier", schedule: Schedule{Kind: Tiered, Tiers: tiers}, amount: 1000, expected: 10},
+		{name: "MiddleTier", schedule: Schedule{Kind: Tiered, Tiers: tiers}, amount: 2000, expected: 25},
+		{name: "LastTier", schedule: Schedule{Kind: Tiered, Tiers: tiers}, amount: 20000, expected: 100},
+		{name: "TieredMax", schedule: Schedule{Kind: Tiered, Tiers: tiers, Max: 60}, amount: 20000, expected: 60},
+		{name: "LargeAmount", schedule: Schedule{Kind: Percentage, RateBps: 10000}, amount: 1 << 62, expected: 1 << 62},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			fee, err := tc.schedule.Fee(tc.amount)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, fee)
+		})
+	}
+}
+
+func TestScheduleValidate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		schedule Schedule
+	}{
+		{name: "UnsupportedKind", schedule: Schedule{Kind: "monthly"}},
+		{name: "NegativeFee", schedule: Schedule{Kind: Flat, Flat: -1}},
+		{name: "MaxBelowMin", schedule: Schedule{Kind: Flat, Min: 10, Max: 5}},
+		{name: "RateAboveWhole", schedule: Schedule{Kind: Percentage, RateBps: 10001}},
+		{name: "TiersOnFlat", schedule: Schedule{Kind: Flat, Tiers: []Tier{{Flat: 1}}}},
+		{name: "NoTiers", schedule: Schedule{Kind: Tiered}},
+		{name: "BoundedLastTier", schedule: Schedule{Kind: Tiered, Tiers: []Tier{{UpTo: 100}}}},
+		{name: "UnorderedTiers", schedule: Schedule{Kind: Tiered, Tiers: []Tier{{UpTo: 100}, {UpTo: 50}, {}}}},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			require.ErrorIs(t, tc.schedule.Validate(), ErrInvalidSchedule)
+
+			_, err := tc.schedule.Fee(100)
+			require.ErrorIs(t, err, ErrInvalidSchedule)
+		})
+	}
+}
@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: BankAppGo/fx (interfaces: FXRateProvider)
+
+// Package mockfx is a generated GoMock package.
+package mockfx
+
+import (
+	fx "BankAppGo/fx"
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockFXRateProvider is a mock of FXRateProvider interface.
+type MockFXRateProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockFXRateProviderMockRecorder
+}
+
+// MockFXRateProviderMockRecorder is the mock recorder for MockFXRateProvider.
+type MockFXRateProviderMockRecorder struct {
+	mock *MockFXRateProvider
+}
+
+// NewMockFXRateProvider creates a new mock instance.
+func NewMockFXRateProvider(ctrl *gomock.Controller) *MockFXRateProvider {
+	mock := &MockFXRateProvider{ctrl: ctrl}
+	mock.recorder = &MockFXRateProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFXRateProvider) EXPECT() *MockFXRateProviderMockRecorder {
+	return m.recorder
+}
+
+// GetQuote mocks base method.
+func (m *MockFXRateProvider) GetQuote(arg0 context.Context, arg1, arg2 string) (fx.Quote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetQuote", arg0, arg1, arg2)
+	ret0, _ := ret[0].(fx.Quote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetQuote indicates an expected call of GetQuote.
+func (mr *MockFXRateProviderMockRecorder) GetQuote(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetQuote", reflect.TypeOf((*MockFXRateProvider)(nil).GetQuote), arg0, arg1, arg2)
+}
@@ -0,0 +1,76 @@
+package fx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// Different types of error returned by rate providers
+var (
+	ErrRateNotFound = errors.New("no exchange rate for currency pair")
+	ErrInvalidRate  = errors.New("exchange rate must be a positive decimal")
+)
+
+// interface for looking up exchange rates
+type FXRateProvider interface {
+	// quotes the rate for converting amounts in currency from into currency to
+	GetQuote(ctx context.Context, from string, to string) (Quote, error)
+}
+
+// Quote is an exchange rate between two currencies at a point in time.
+// Rate is a decimal string: one minor unit of From is worth Rate minor units of To
+type Quote struct {
+	From     string    `json:"from"`
+	To       string    `json:"to"`
+	Rate     string    `json:"rate"`
+	QuotedAt time.Time `json:"quoted_at"`
+}
+
+// Convert returns amount expressed in the quote's target currency,
+// rounded to the nearest minor unit with ties to even
+func (quote Quote) Convert(amount int64) (int64, error) {
+	rate, err := parseRate(quote.Rate)
+	if err != nil {
+		return 0, err
+	}
+
+	converted := new(big.Rat).Mul(new(big.Rat).SetInt64(amount), rate)
+	result, ok := roundHalfEven(converted)
+	if !ok {
+		return 0, fmt.Errorf("converted amount of %d %s overflows", amount, quote.From)
+	}
+	return result, nil
+}
+
+func parseRate(rate string) (*big.Rat, error) {
+	r, ok := new(big.Rat).SetString(rate)
+	if !ok || r.Sign() <= 0 {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidRate, rate)
+	}
+	return r, nil
+}
+
+// rounds r to an integer with ties to even, reporting false if it does not fit in an int64
+func roundHalfEven(r *big.Rat) (int64, bool) {
+	quo, rem := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int))
+
+	// compare twice the remainder with the denominator to find which side of .5 we are on
+	twiceRem := new(big.Int).Abs(rem)
+	twiceRem.Lsh(twiceRem, 1)
+	cmp := twiceRem.Cmp(r.Denom())
+	if cmp > 0 || (cmp == 0 && quo.Bit(0) == 1) {
+		if rem.Sign() < 0 {
+			quo.Sub(quo, big.NewInt(1))
+		} else {
+			quo.Add(quo, big.NewInt(1))
+		}
+	}
+
+	if !quo.IsInt64() {
+		return 0, false
+	}
+	return quo.Int64(), true
+}
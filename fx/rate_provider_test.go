@@ -0,0 +1,74 @@
+package fx
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuoteConvert(t *testing.T) {
+	testCases := []struct {
+		name     string
+		rate     string
+		amount   int64
+		expected int64
+	}{
+		{name: "Identity", rate: "1", amount: 1234, expected: 1234},
+		{name: "RoundDown", rate: "0.92", amount: 101, expected: 93},
+		{name: "TieToEven", rate: "0.5", amount: 5, expected: 2},
+		{name: "TieToEvenUp", rate: "0.5", amount: 7, expected: 4},
+		{name: "Multiply", rate: "150.25", amount: 200, expected: 30050},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			quote := Quote{From: "USD", To: "EUR", Rate: tc.rate}
+			converted, err := quote.Convert(tc.amount)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, converted)
+		})
+	}
+}
+
+func TestQuoteConvertInvalidRate(t *testing.T) {
+	quote := Quote{From: "USD", To: "EUR", Rate: "-1"}
+	_, err := quote.Convert(100)
+	require.ErrorIs(t, err, ErrInvalidRate)
+}
+
+func TestStaticRateProvider(t *testing.T) {
+	provider, err := NewStaticRateProvider(map[string]map[string]string{
+		"USD": {"EUR": "0.8"},
+	})
+	require.NoError(t, err)
+
+	quote, err := provider.GetQuote(context.Background(), "USD", "EUR")
+	require.NoError(t, err)
+	require.Equal(t, "USD", quote.From)
+	require.Equal(t, "EUR", quote.To)
+	require.Equal(t, "0.8", quote.Rate)
+	require.WithinDuration(t, time.Now(), quote.QuotedAt, time.Second)
+
+	// the inverse pair is derived from the table
+	quote, err = provider.GetQuote(context.Background(), "EUR", "USD")
+	require.NoError(t, err)
+	require.Equal(t, "1.2500000000", quote.Rate)
+
+	quote, err = provider.GetQuote(context.Background(), "GBP", "GBP")
+	require.NoError(t, err)
+	require.Equal(t, "1", quote.Rate)
+
+	_, err = provider.GetQuote(context.Background(), "USD", "GBP")
+	require.ErrorIs(t, err, ErrRateNotFound)
+}
+
+func TestStaticRateProviderInvalidRate(t *testing.T) {
+	_, err := NewStaticRateProvider(map[string]map[string]string{
+		"USD": {"EUR": "abc"},
+	})
+	require.ErrorIs(t, err, ErrInvalidRate)
+}
@@ -0,0 +1,84 @@
+package fx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+)
+
+// number of decimal places kept for rates derived from the inverse pair
+const derivedRatePrecision = 10
+
+// StaticRateProvider quotes rates from a fixed table
+type StaticRateProvider struct {
+	rates map[string]map[string]string
+}
+
+// LoadStaticRateProvider reads a rate table from a JSON file of the form
+// {"USD": {"EUR": "0.92"}}, meaning one USD minor unit is worth 0.92 EUR minor units
+func LoadStaticRateProvider(path string) (FXRateProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read rate table: %w", err)
+	}
+
+	var rates map[string]map[string]string
+	err = json.Unmarshal(data, &rates)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse rate table: %w", err)
+	}
+
+	return NewStaticRateProvider(rates)
+}
+
+// NewStaticRateProvider creates a provider from a rate table keyed by source then target currency.
+// Missing pairs are derived from their inverse when it is present
+func NewStaticRateProvider(rates map[string]map[string]string) (FXRateProvider, error) {
+	table := make(map[string]map[string]string)
+	set := func(from string, to string, rate string) {
+		if table[from] == nil {
+			table[from] = make(map[string]string)
+		}
+		table[from][to] = rate
+	}
+
+	for from, targets := range rates {
+		for to, rate := range targets {
+			r, err := parseRate(rate)
+			if err != nil {
+				return nil, fmt.Errorf("%s/%s: %w", from, to, err)
+			}
+			set(from, to, rate)
+
+			if _, ok := rates[to][from]; !ok {
+				set(to, from, new(big.Rat).Inv(r).FloatString(derivedRatePrecision))
+			}
+		}
+	}
+
+	return &StaticRateProvider{rates: table}, nil
+}
+
+// GetQuote implements FXRateProvider.
+func (provider *StaticRateProvider) GetQuote(ctx context.Context, from string, to string) (Quote, error) {
+	quote := Quote{
+		From:     from,
+		To:       to,
+		Rate:     "1",
+		QuotedAt: time.Now(),
+	}
+	if from == to {
+		return quote, nil
+	}
+
+	rate, ok := provider.rates[from][to]
+	if !ok {
+		return Quote{}, fmt.Errorf("%w: %s/%s", ErrRateNotFound, from, to)
+	}
+
+	quote.Rate = rate
+	return quote, nil
+}
@@ -0,0 +1,134 @@
+// Package interest accrues daily interest on accounts and pays it into them once a month
+package interest
+
+import (
+	db "BankAppGo/db/sqlc"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Accounts read per query when no batch size is given
+const DefaultBatchSize = 500
+
+// Options of an interest run
+type Options struct {
+	// the UTC day to accrue, the time of day is ignored
+	Date time.Time
+	// accounts read per query, DefaultBatchSize when zero
+	BatchSize int64
+}
+
+// Report is the outcome of an interest run
+type Report struct {
+	Date time.Time `json:"date"`
+	// accruals of days before this one were capitalised
+	CapitalizedBefore   time.Time `json:"capitalized_before"`
+	AccountsAccrued     int64     `json:"accounts_accrued"`
+	InterestAccrued     int64     `json:"interest_accrued"`
+	AccountsCapitalized int64     `json:"accounts_capitalized"`
+	InterestCapitalized int64     `json:"interest_capitalized"`
+	// closed or frozen accounts left for a later run
+	AccountsSkipped int64 `json:"accounts_skipped"`
+}
+
+// Accrues the interest of the day on every interest-bearing account, then pays the interest accrued in
+// previous months. Accruing the last day of a month pays that month too. Both steps are idempotent,
+// so a failed run can be started again for the same day
+func Run(ctx context.Context, store db.Store, opts Options) (Report, error) {
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+
+	day := opts.Date.UTC().Truncate(24 * time.Hour)
+	next := day.AddDate(0, 0, 1)
+
+	report := Report{
+		Date:              day,
+		CapitalizedBefore: time.Date(next.Year(), next.Month(), 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	err := accrue(ctx, store, batchSize, &report)
+	if err != nil {
+		return report, err
+	}
+
+	err = capitalize(ctx, store, batchSize, &report)
+	return report, err
+}
+
+// accrues the day on every account with a rate for its currency and kind
+func accrue(ctx context.Context, store db.Store, batchSize int64, report *Report) error {
+	var afterID int64
+	for {
+		accountIDs, err := store.ListInterestBearingAccounts(ctx, db.ListInterestBearingAccountsParams{
+			Before:     report.Date.AddDate(0, 0, 1),
+			AfterID:    afterID,
+			LimitCount: batchSize,
+		})
+		if err != nil {
+			return fmt.Errorf("cannot list accounts after %d: %w", afterID, err)
+		}
+
+		for _, accountID := range accountIDs {
+			accrual, err := store.AccrueInterestTx(ctx, db.AccrueInterestTxParams{
+				AccountID: accountID,
+				Date:      report.Date,
+			})
+			switch {
+			case errors.Is(err, db.ErrAccountClosed), errors.Is(err, db.ErrNotInterestBearing):
+				report.AccountsSkipped++
+				continue
+			case err != nil:
+				return fmt.Errorf("cannot accrue interest on account %d: %w", accountID, err)
+			}
+
+			report.AccountsAccrued++
+			report.InterestAccrued += accrual.Amount
+		}
+
+		if int64(len(accountIDs)) < batchSize {
+			return nil
+		}
+		afterID = accountIDs[len(accountIDs)-1]
+	}
+}
+
+// pays the accruals of days before the first day of the current month
+func capitalize(ctx context.Context, store db.Store, batchSize int64, report *Report) error {
+	var afterID int64
+	for {
+		accountIDs, err := store.ListAccountsWithUncapitalizedInterest(ctx, db.ListAccountsWithUncapitalizedInterestParams{
+			Before:     report.CapitalizedBefore,
+			AfterID:    afterID,
+			LimitCount: batchSize,
+		})
+		if err != nil {
+			return fmt.Errorf("cannot list accounts after %d: %w", afterID, err)
+		}
+
+		for _, accountID := range accountIDs {
+			result, err := store.CapitalizeInterestTx(ctx, db.CapitalizeInterestTxParams{
+				AccountID: accountID,
+				Before:    report.CapitalizedBefore,
+			})
+			switch {
+			case errors.Is(err, db.ErrAccountClosed), errors.Is(err, db.ErrAccountFrozen):
+				report.AccountsSkipped++
+				continue
+			case err != nil:
+				return fmt.Errorf("cannot capitalize interest on account %d: %w", accountID, err)
+			}
+
+			report.AccountsCapitalized++
+			report.InterestCapitalized += result.Amount
+		}
+
+		if int64(len(accountIDs)) < batchSize {
+			return nil
+		}
+		afterID = accountIDs[len(accountIDs)-1]
+	}
+}
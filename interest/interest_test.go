@@ -0,0 +1,119 @@
+package interest
+
+import (
+	mockdb "BankAppGo/db/mock"
+	db "BankAppGo/db/sqlc"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+
+	day := time.Date(2025, time.March, 10, 0, 0, 0, 0, time.UTC)
+	firstOfMonth := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	// a full batch is followed by another read from the last id
+	gomock.InOrder(
+		store.EXPECT().
+			ListInterestBearingAccounts(gomock.Any(), gomock.Eq(db.ListInterestBearingAccountsParams{
+				Before:     day.AddDate(0, 0, 1),
+				AfterID:    0,
+				LimitCount: 2,
+			})).
+			Return([]int64{1, 2}, nil),
+		store.EXPECT().
+			ListInterestBearingAccounts(gomock.Any(), gomock.Eq(db.ListInterestBearingAccountsParams{
+				Before:     day.AddDate(0, 0, 1),
+				AfterID:    2,
+				LimitCount: 2,
+			})).
+			Return([]int64{5}, nil),
+	)
+	store.EXPECT().
+		AccrueInterestTx(gomock.Any(), gomock.Eq(db.AccrueInterestTxParams{AccountID: 1, Date: day})).
+		Return(db.InterestAccrual{AccountID: 1, Amount: 3}, nil)
+	store.EXPECT().
+		AccrueInterestTx(gomock.Any(), gomock.Eq(db.AccrueInterestTxParams{AccountID: 2, Date: day})).
+		Return(db.InterestAccrual{}, db.ErrAccountClosed)
+	store.EXPECT().
+		AccrueInterestTx(gomock.Any(), gomock.Eq(db.AccrueInterestTxParams{AccountID: 5, Date: day})).
+		Return(db.InterestAccrual{AccountID: 5, Amount: 4}, nil)
+
+	// in the middle of a month only the accruals of earlier months are paid
+	store.EXPECT().
+		ListAccountsWithUncapitalizedInterest(gomock.Any(), gomock.Eq(db.ListAccountsWithUncapitalizedInterestParams{
+			Before:     firstOfMonth,
+			AfterID:    0,
+			LimitCount: 2,
+		})).
+		Return([]int64{5}, nil)
+	store.EXPECT().
+		CapitalizeInterestTx(gomock.Any(), gomock.Eq(db.CapitalizeInterestTxParams{AccountID: 5, Before: firstOfMonth})).
+		Return(db.CapitalizeInterestTxResult{Amount: 90}, nil)
+
+	report, err := Run(context.Background(), store, Options{Date: day.Add(15 * time.Hour), BatchSize: 2})
+	require.NoError(t, err)
+	require.Equal(t, day, report.Date)
+	require.Equal(t, firstOfMonth, report.CapitalizedBefore)
+	require.Equal(t, int64(2), report.AccountsAccrued)
+	require.Equal(t, int64(7), report.InterestAccrued)
+	require.Equal(t, int64(1), report.AccountsCapitalized)
+	require.Equal(t, int64(90), report.InterestCapitalized)
+	require.Equal(t, int64(1), report.AccountsSkipped)
+}
+
+func TestRunLastDayOfMonth(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+
+	day := time.Date(2025, time.March, 31, 0, 0, 0, 0, time.UTC)
+	nextMonth := time.Date(2025, time.April, 1, 0, 0, 0, 0, time.UTC)
+
+	store.EXPECT().ListInterestBearingAccounts(gomock.Any(), gomock.Any()).Return([]int64{3}, nil)
+	store.EXPECT().AccrueInterestTx(gomock.Any(), gomock.Any()).Return(db.InterestAccrual{AccountID: 3, Amount: 2}, nil)
+
+	// the month that just ended is paid, frozen accounts wait for a later run
+	store.EXPECT().
+		ListAccountsWithUncapitalizedInterest(gomock.Any(), gomock.Eq(db.ListAccountsWithUncapitalizedInterestParams{
+			Before:     nextMonth,
+			LimitCount: DefaultBatchSize,
+		})).
+		Return([]int64{3, 4}, nil)
+	store.EXPECT().
+		CapitalizeInterestTx(gomock.Any(), gomock.Eq(db.CapitalizeInterestTxParams{AccountID: 3, Before: nextMonth})).
+		Return(db.CapitalizeInterestTxResult{Amount: 60}, nil)
+	store.EXPECT().
+		CapitalizeInterestTx(gomock.Any(), gomock.Eq(db.CapitalizeInterestTxParams{AccountID: 4, Before: nextMonth})).
+		Return(db.CapitalizeInterestTxResult{}, db.ErrAccountFrozen)
+
+	report, err := Run(context.Background(), store, Options{Date: day})
+	require.NoError(t, err)
+	require.Equal(t, nextMonth, report.CapitalizedBefore)
+	require.Equal(t, int64(1), report.AccountsCapitalized)
+	require.Equal(t, int64(60), report.InterestCapitalized)
+	require.Equal(t, int64(1), report.AccountsSkipped)
+}
+
+func TestRunAccrualError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+
+	store.EXPECT().ListInterestBearingAccounts(gomock.Any(), gomock.Any()).Return([]int64{1}, nil)
+	store.EXPECT().AccrueInterestTx(gomock.Any(), gomock.Any()).Return(db.InterestAccrual{}, db.ErrAccrualDayNotOver)
+	store.EXPECT().ListAccountsWithUncapitalizedInterest(gomock.Any(), gomock.Any()).Times(0)
+
+	_, err := Run(context.Background(), store, Options{Date: time.Now()})
+	require.ErrorIs(t, err, db.ErrAccrualDayNotOver)
+}
@@ -0,0 +1,140 @@
+// Package ledgerchain verifies the hash chain linking the entries of every account
+package ledgerchain
+
+import (
+	db "BankAppGo/db/sqlc"
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// Entries read per query when no batch size is given
+const DefaultBatchSize = 1000
+
+// Kinds of break found in a chain
+const (
+	// the entry does not hash to its stored hash, its contents or prev_hash were changed
+	HashMismatch = "hash_mismatch"
+	// prev_hash is not the hash of the entry before it, entries in between were removed or inserted
+	// or the hash of the entry before was rewritten
+	ChainBroken = "chain_broken"
+)
+
+// Options of a verification run
+type Options struct {
+	// entries read per query, DefaultBatchSize when zero
+	BatchSize int64
+}
+
+// Break is the first tampered entry of an account, later entries of the account are not checked
+type Break struct {
+	Kind      string `json:"kind"`
+	AccountID int64  `json:"account_id"`
+	EntryID   int64  `json:"entry_id"`
+	// the entry the broken one should chain onto, zero for the first entry of the account
+	PreviousEntryID int64  `json:"previous_entry_id,omitempty"`
+	Detail          string `json:"detail"`
+}
+
+// Report is the outcome of a verification run
+type Report struct {
+	StartedAt       time.Time `json:"started_at"`
+	FinishedAt      time.Time `json:"finished_at"`
+	AccountsChecked int64     `json:"accounts_checked"`
+	EntriesChecked  int64     `json:"entries_checked"`
+	Breaks          []Break   `json:"breaks"`
+}
+
+// the chain of the account being walked
+type chain struct {
+	accountID int64
+	// last entry checked, zero before the first one
+	lastID   int64
+	lastHash []byte
+	broken   bool
+}
+
+// Walks the entries of every account in the order they were chained and reports the first
+// tampered entry of each account. Removing the latest entries of an account leaves a valid
+// chain behind, that is caught by the balance check of the reconcile command
+func Run(ctx context.Context, store db.Store, opts Options) (Report, error) {
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+
+	report := Report{
+		StartedAt: time.Now(),
+		Breaks:    []Break{},
+	}
+
+	var current chain
+	var afterAccountID, afterID int64
+	for {
+		entries, err := store.ListChainEntries(ctx, db.ListChainEntriesParams{
+			AfterAccountID: afterAccountID,
+			AfterID:        afterID,
+			LimitCount:     batchSize,
+		})
+		if err != nil {
+			return report, fmt.Errorf("cannot read entries after %d of account %d: %w", afterID, afterAccountID, err)
+		}
+
+		for _, entry := range entries {
+			if entry.AccountID != current.accountID || report.EntriesChecked == 0 {
+				current = chain{accountID: entry.AccountID}
+				report.AccountsChecked++
+			}
+			report.EntriesChecked++
+
+			if !current.broken {
+				brk, ok := check(current, entry)
+				if !ok {
+					report.Breaks = append(report.Breaks, brk)
+					current.broken = true
+				}
+			}
+			current.lastID = entry.ID
+			current.lastHash = entry.Hash
+		}
+
+		if int64(len(entries)) < batchSize {
+			break
+		}
+		last := entries[len(entries)-1]
+		afterAccountID, afterID = last.AccountID, last.ID
+	}
+
+	report.FinishedAt = time.Now()
+	return report, nil
+}
+
+// checks an entry against itself and the entry before it in the chain
+func check(current chain, entry db.Entry) (Break, bool) {
+	brk := Break{
+		AccountID:       entry.AccountID,
+		EntryID:         entry.ID,
+		PreviousEntryID: current.lastID,
+	}
+
+	if hash := db.EntryHash(entry); !bytes.Equal(hash, entry.Hash) {
+		brk.Kind = HashMismatch
+		brk.Detail = fmt.Sprintf("entry hashes to %s, %s is stored", hex.EncodeToString(hash), hex.EncodeToString(entry.Hash))
+		return brk, false
+	}
+
+	if !bytes.Equal(entry.PrevHash, current.lastHash) {
+		brk.Kind = ChainBroken
+		if current.lastID == 0 {
+			brk.Detail = fmt.Sprintf("first entry of the account has prev_hash %s", hex.EncodeToString(entry.PrevHash))
+		} else {
+			brk.Detail = fmt.Sprintf("prev_hash is %s, entry %d hashes to %s",
+				hex.EncodeToString(entry.PrevHash), current.lastID, hex.EncodeToString(current.lastHash))
+		}
+		return brk, false
+	}
+
+	return brk, true
+}
@@ -0,0 +1,128 @@
+package ledgerchain
+
+import (
+	mockdb "BankAppGo/db/mock"
+	db "BankAppGo/db/sqlc"
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+// builds a valid chain of entries for an account, starting at id
+func randomChain(accountID int64, id int64, amounts ...int64) []db.Entry {
+	entries := make([]db.Entry, len(amounts))
+	var prev []byte
+	for i, amount := range amounts {
+		entry := db.Entry{
+			ID:         id + int64(i),
+			AccountID:  accountID,
+			Amount:     amount,
+			CreatedAt:  time.Now().UTC().Truncate(time.Microsecond),
+			TransferID: sql.NullInt64{Int64: id + int64(i), Valid: true},
+			JournalID:  sql.NullInt64{Int64: id + int64(i), Valid: true},
+			PrevHash:   prev,
+		}
+		entry.Hash = db.EntryHash(entry)
+		prev = entry.Hash
+		entries[i] = entry
+	}
+	return entries
+}
+
+func TestRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+
+	account1 := randomChain(1, 10, 100, -20, 5)
+	// the amount was changed after the entry was booked
+	account1[1].Amount = -2
+
+	account2 := randomChain(2, 20, 50, 60, 70)
+	// the middle entry was removed
+	account2 = append(account2[:1], account2[2])
+
+	account3 := randomChain(3, 30, 10)
+
+	// a full batch is followed by another read from the last entry
+	gomock.InOrder(
+		store.EXPECT().
+			ListChainEntries(gomock.Any(), gomock.Eq(db.ListChainEntriesParams{LimitCount: 4})).
+			Return([]db.Entry{account1[0], account1[1], account1[2], account2[0]}, nil),
+		store.EXPECT().
+			ListChainEntries(gomock.Any(), gomock.Eq(db.ListChainEntriesParams{AfterAccountID: 2, AfterID: 20, LimitCount: 4})).
+			Return([]db.Entry{account2[1], account3[0]}, nil),
+	)
+
+	report, err := Run(context.Background(), store, Options{BatchSize: 4})
+	require.NoError(t, err)
+	require.Equal(t, int64(3), report.AccountsChecked)
+	require.Equal(t, int64(6), report.EntriesChecked)
+	require.Len(t, report.Breaks, 2)
+
+	require.Equal(t, HashMismatch, report.Breaks[0].Kind)
+	require.Equal(t, int64(1), report.Breaks[0].AccountID)
+	require.Equal(t, int64(11), report.Breaks[0].EntryID)
+	require.Equal(t, int64(10), report.Breaks[0].PreviousEntryID)
+
+	require.Equal(t, ChainBroken, report.Breaks[1].Kind)
+	require.Equal(t, int64(2), report.Breaks[1].AccountID)
+	require.Equal(t, int64(22), report.Breaks[1].EntryID)
+	require.Equal(t, int64(20), report.Breaks[1].PreviousEntryID)
+}
+
+func TestRunRehashedEntry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+
+	entries := randomChain(1, 1, 100, -20, 5)
+	// the first entry was changed and hashed again, the next entry still points at the old hash
+	entries[0].Amount = 1000
+	entries[0].Hash = db.EntryHash(entries[0])
+
+	store.EXPECT().ListChainEntries(gomock.Any(), gomock.Any()).Times(1).Return(entries, nil)
+
+	report, err := Run(context.Background(), store, Options{})
+	require.NoError(t, err)
+	require.Len(t, report.Breaks, 1)
+	require.Equal(t, ChainBroken, report.Breaks[0].Kind)
+	require.Equal(t, int64(2), report.Breaks[0].EntryID)
+	require.Equal(t, int64(1), report.Breaks[0].PreviousEntryID)
+}
+
+func TestRunFirstEntryWithPrevHash(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+
+	// the first entry of the account was removed
+	entries := randomChain(1, 1, 100, -20)[1:]
+
+	store.EXPECT().ListChainEntries(gomock.Any(), gomock.Any()).Times(1).Return(entries, nil)
+
+	report, err := Run(context.Background(), store, Options{})
+	require.NoError(t, err)
+	require.Len(t, report.Breaks, 1)
+	require.Equal(t, ChainBroken, report.Breaks[0].Kind)
+	require.Equal(t, int64(2), report.Breaks[0].EntryID)
+	require.Zero(t, report.Breaks[0].PreviousEntryID)
+}
+
+func TestRunError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().ListChainEntries(gomock.Any(), gomock.Any()).Times(1).Return(nil, sql.ErrConnDone)
+
+	_, err := Run(context.Background(), store, Options{})
+	require.ErrorIs(t, err, sql.ErrConnDone)
+}
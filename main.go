@@ -4,8 +4,16 @@ import (
 	"BankAppGo/api"
 	db "BankAppGo/db/sqlc"
 	"BankAppGo/db/util"
+	"BankAppGo/interest"
+	"BankAppGo/ledgerchain"
+	"BankAppGo/reconcile"
+	"context"
 	"database/sql"
+	"encoding/json"
+	"flag"
 	"log"
+	"os"
+	"time"
 
 	_ "github.com/lib/pq"
 )
@@ -22,14 +30,167 @@ func main() {
 	}
 
 	store := db.NewStore(conn)
+
+	if len(os.Args) > 1 {
+		runCommand(config, store, os.Args[1], os.Args[2:])
+		return
+	}
+
 	server, err := api.NewServer(config, store)
 	if err != nil {
 		log.Fatal("cannot create server:", err)
 	}
 
-	err = server.Start(config.ServerAddress)
+	err = server.Serve(config.ServerAddress, config.GRPCServerAddress)
 	if err != nil {
 		log.Fatal("cannot start server:", err)
 	}
 
 }
+
+// runs an admin command instead of the server
+func runCommand(config util.Config, store db.Store, name string, args []string) {
+	switch name {
+	case "revoke-sessions":
+		revokeSessions(config, store, args)
+	case "set-role":
+		setRole(store, args)
+	case "reconcile":
+		reconcileLedger(store, args)
+	case "interest":
+		runInterest(store, args)
+	case "verify-ledger":
+		verifyLedger(store, args)
+	default:
+		log.Fatalf("unknown command %q", name)
+	}
+}
+
+// blocks every session of a user and revokes all tokens issued to them so far
+func revokeSessions(config util.Config, store db.Store, args []string) {
+	flags := flag.NewFlagSet("revoke-sessions", flag.ExitOnError)
+	username := flags.String("username", "", "user whose sessions are revoked")
+	flags.Parse(args)
+
+	if *username == "" {
+		log.Fatal("revoke-sessions: -username is required")
+	}
+
+	// the revocation must outlive every token that may have been issued already.
+	// Running servers pick it up on their next revocation sync, the admin endpoint applies it at once on its server
+	result, err := store.RevokeUserSessionsTx(context.Background(), db.RevokeUserSessionsTxParams{
+		Username:  *username,
+		ExpiresAt: time.Now().Add(config.TokenLifetime()),
+	})
+	if err != nil {
+		log.Fatal("cannot revoke sessions:", err)
+	}
+
+	log.Printf("revoked all tokens of %s, blocked %d sessions", *username, result.BlockedSessions)
+}
+
+// changes the role of a user. Access tokens already issued keep the old role until they expire or the user's sessions
+// are revoked, the ones renewed from then on carry the new role
+func setRole(store db.Store, args []string) {
+	flags := flag.NewFlagSet("set-role", flag.ExitOnError)
+	username := flags.String("username", "", "user whose role is changed")
+	role := flags.String("role", "", "depositor, banker or admin")
+	flags.Parse(args)
+
+	if *username == "" {
+		log.Fatal("set-role: -username is required")
+	}
+	if !util.IsSupportedRole(*role) {
+		log.Fatalf("set-role: unsupported role %q", *role)
+	}
+
+	user, err := store.UpdateUserRole(context.Background(), db.UpdateUserRoleParams{
+		Username: *username,
+		Role:     *role,
+	})
+	if err != nil {
+		log.Fatal("cannot set role:", err)
+	}
+
+	log.Printf("%s is now %s, access tokens renewed from now on carry the new role", user.Username, user.Role)
+}
+
+// checks the ledger invariants and prints the report as JSON, exiting with status 1 on discrepancies
+func reconcileLedger(store db.Store, args []string) {
+	flags := flag.NewFlagSet("reconcile", flag.ExitOnError)
+	batchSize := flags.Int64("batch-size", reconcile.DefaultBatchSize, "accounts or transfers read per query")
+	record := flags.Bool("record", false, "write a reconciliation report row for the run")
+	flags.Parse(args)
+
+	report, err := reconcile.Run(context.Background(), store, reconcile.Options{
+		BatchSize: *batchSize,
+		Record:    *record,
+	})
+	if err != nil {
+		log.Fatal("cannot reconcile ledger:", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	err = encoder.Encode(report)
+	if err != nil {
+		log.Fatal("cannot write report:", err)
+	}
+
+	if len(report.Discrepancies) > 0 {
+		os.Exit(1)
+	}
+}
+
+// walks the hash chains of the entries and prints the report as JSON, exiting with status 1 on breaks
+func verifyLedger(store db.Store, args []string) {
+	flags := flag.NewFlagSet("verify-ledger", flag.ExitOnError)
+	batchSize := flags.Int64("batch-size", ledgerchain.DefaultBatchSize, "entries read per query")
+	flags.Parse(args)
+
+	report, err := ledgerchain.Run(context.Background(), store, ledgerchain.Options{
+		BatchSize: *batchSize,
+	})
+	if err != nil {
+		log.Fatal("cannot verify ledger:", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	err = encoder.Encode(report)
+	if err != nil {
+		log.Fatal("cannot write report:", err)
+	}
+
+	if len(report.Breaks) > 0 {
+		os.Exit(1)
+	}
+}
+
+// accrues a day of interest, pays the interest of months that ended and prints the report as JSON
+func runInterest(store db.Store, args []string) {
+	flags := flag.NewFlagSet("interest", flag.ExitOnError)
+	date := flags.String("date", time.Now().UTC().AddDate(0, 0, -1).Format(time.DateOnly), "UTC day to accrue, yesterday by default")
+	batchSize := flags.Int64("batch-size", interest.DefaultBatchSize, "accounts read per query")
+	flags.Parse(args)
+
+	day, err := time.Parse(time.DateOnly, *date)
+	if err != nil {
+		log.Fatalf("interest: invalid -date %q", *date)
+	}
+
+	report, err := interest.Run(context.Background(), store, interest.Options{
+		Date:      day,
+		BatchSize: *batchSize,
+	})
+	if err != nil {
+		log.Fatal("cannot run interest:", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	err = encoder.Encode(report)
+	if err != nil {
+		log.Fatal("cannot write report:", err)
+	}
+}
@@ -0,0 +1,206 @@
+// Package reconcile checks that the ledger is consistent with the balances it explains
+package reconcile
+
+import (
+	db "BankAppGo/db/sqlc"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Rows read per query when no batch size is given
+const DefaultBatchSize = 500
+
+// Kinds of discrepancy found by a check
+const (
+	// the balance of an account differs from the sum of its entries
+	BalanceMismatch = "balance_mismatch"
+	// a transfer does not have exactly one matching debit and one matching credit entry
+	TransferEntriesMismatch = "transfer_entries_mismatch"
+	// the balances of all accounts in a currency, system accounts included, do not sum to zero
+	CurrencyNotZeroSum = "currency_not_zero_sum"
+)
+
+// Options of a reconciliation run
+type Options struct {
+	// accounts or transfers read per query, DefaultBatchSize when zero
+	BatchSize int64
+	// writes a report row for the run
+	Record bool
+	// admin who ran the check, empty when run from the command line
+	RunBy string
+}
+
+// Discrepancy is one broken invariant of the ledger
+type Discrepancy struct {
+	Kind       string `json:"kind"`
+	AccountID  int64  `json:"account_id,omitempty"`
+	TransferID int64  `json:"transfer_id,omitempty"`
+	Currency   string `json:"currency,omitempty"`
+	Detail     string `json:"detail"`
+}
+
+// Report is the outcome of a reconciliation run
+type Report struct {
+	// set when the report was recorded
+	ID               int64     `json:"id,omitempty"`
+	StartedAt        time.Time `json:"started_at"`
+	FinishedAt       time.Time `json:"finished_at"`
+	AccountsChecked  int64     `json:"accounts_checked"`
+	TransfersChecked int64     `json:"transfers_checked"`
+	// checked transfers made before entries recorded their transfer, whose entries could not be linked to them
+	TransfersUnverifiable int64         `json:"transfers_unverifiable"`
+	Discrepancies         []Discrepancy `json:"discrepancies"`
+}
+
+// Scans every account and transfer in batches and reports the discrepancies found.
+// Each batch is read by a single query, so money moving during the scan is not reported
+func Run(ctx context.Context, store db.Store, opts Options) (Report, error) {
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+
+	report := Report{
+		StartedAt:     time.Now(),
+		Discrepancies: []Discrepancy{},
+	}
+
+	err := checkAccounts(ctx, store, batchSize, &report)
+	if err != nil {
+		return report, err
+	}
+
+	err = checkTransfers(ctx, store, batchSize, &report)
+	if err != nil {
+		return report, err
+	}
+
+	err = checkCurrencies(ctx, store, &report)
+	if err != nil {
+		return report, err
+	}
+
+	report.FinishedAt = time.Now()
+
+	if opts.Record {
+		err = record(ctx, store, &report, opts.RunBy)
+	}
+	return report, err
+}
+
+// compares the balance of every account with the sum of its entries
+func checkAccounts(ctx context.Context, store db.Store, batchSize int64, report *Report) error {
+	var afterID int64
+	for {
+		accounts, err := store.ListAccountEntryTotals(ctx, db.ListAccountEntryTotalsParams{
+			AfterID:    afterID,
+			LimitCount: batchSize,
+		})
+		if err != nil {
+			return fmt.Errorf("cannot check accounts after %d: %w", afterID, err)
+		}
+
+		for _, account := range accounts {
+			report.AccountsChecked++
+			if account.Balance != account.EntriesTotal {
+				report.Discrepancies = append(report.Discrepancies, Discrepancy{
+					Kind:      BalanceMismatch,
+					AccountID: account.ID,
+					Detail:    fmt.Sprintf("balance is %d, entries sum to %d", account.Balance, account.EntriesTotal),
+				})
+			}
+		}
+
+		if int64(len(accounts)) < batchSize {
+			return nil
+		}
+		afterID = accounts[len(accounts)-1].ID
+	}
+}
+
+// checks that every transfer booked one debit on its source and one credit on its destination.
+// Older transfers whose entries were never linked to them are counted as unverifiable instead
+func checkTransfers(ctx context.Context, store db.Store, batchSize int64, report *Report) error {
+	var afterID int64
+	for {
+		transfers, err := store.ListTransferEntryCounts(ctx, db.ListTransferEntryCountsParams{
+			AfterID:    afterID,
+			LimitCount: batchSize,
+		})
+		if err != nil {
+			return fmt.Errorf("cannot check transfers after %d: %w", afterID, err)
+		}
+
+		for _, transfer := range transfers {
+			report.TransfersChecked++
+			if transfer.Unlinked && transfer.EntryCount == 0 {
+				report.TransfersUnverifiable++
+				continue
+			}
+			if transfer.EntryCount != 2 || transfer.DebitCount != 1 || transfer.CreditCount != 1 {
+				report.Discrepancies = append(report.Discrepancies, Discrepancy{
+					Kind:       TransferEntriesMismatch,
+					TransferID: transfer.ID,
+					Detail: fmt.Sprintf(
+						"%d entries booked, %d debit of %d on account %d and %d credit of %d on account %d",
+						transfer.EntryCount,
+						transfer.DebitCount, transfer.Amount, transfer.FromAccountID,
+						transfer.CreditCount, transfer.ToAmount, transfer.ToAccountID,
+					),
+				})
+			}
+		}
+
+		if int64(len(transfers)) < batchSize {
+			return nil
+		}
+		afterID = transfers[len(transfers)-1].ID
+	}
+}
+
+// checks that no money was created or destroyed in any currency
+func checkCurrencies(ctx context.Context, store db.Store, report *Report) error {
+	totals, err := store.ListCurrencyTotals(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot check currency totals: %w", err)
+	}
+
+	for _, total := range totals {
+		if total.Total != 0 {
+			report.Discrepancies = append(report.Discrepancies, Discrepancy{
+				Kind:     CurrencyNotZeroSum,
+				Currency: total.Currency,
+				Detail:   fmt.Sprintf("balances sum to %d", total.Total),
+			})
+		}
+	}
+	return nil
+}
+
+// writes the report row of a finished run
+func record(ctx context.Context, store db.Store, report *Report, runBy string) error {
+	discrepancies, err := json.Marshal(report.Discrepancies)
+	if err != nil {
+		return err
+	}
+
+	row, err := store.CreateReconciliationReport(ctx, db.CreateReconciliationReportParams{
+		StartedAt:             report.StartedAt,
+		FinishedAt:            report.FinishedAt,
+		AccountsChecked:       report.AccountsChecked,
+		TransfersChecked:      report.TransfersChecked,
+		TransfersUnverifiable: report.TransfersUnverifiable,
+		DiscrepancyCount:      int64(len(report.Discrepancies)),
+		Discrepancies:         discrepancies,
+		RunBy:                 sql.NullString{String: runBy, Valid: runBy != ""},
+	})
+	if err != nil {
+		return fmt.Errorf("cannot record reconciliation report: %w", err)
+	}
+
+	report.ID = row.ID
+	return nil
+}
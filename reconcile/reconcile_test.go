@@ -0,0 +1,99 @@
+package reconcile
+
+import (
+	mockdb "BankAppGo/db/mock"
+	db "BankAppGo/db/sqlc"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+
+	// a full batch is followed by another read from the last id
+	gomock.InOrder(
+		store.EXPECT().
+			ListAccountEntryTotals(gomock.Any(), gomock.Eq(db.ListAccountEntryTotalsParams{AfterID: 0, LimitCount: 2})).
+			Return([]db.ListAccountEntryTotalsRow{
+				{ID: 1, Balance: 100, EntriesTotal: 100},
+				{ID: 2, Balance: 50, EntriesTotal: 40},
+			}, nil),
+		store.EXPECT().
+			ListAccountEntryTotals(gomock.Any(), gomock.Eq(db.ListAccountEntryTotalsParams{AfterID: 2, LimitCount: 2})).
+			Return([]db.ListAccountEntryTotalsRow{
+				{ID: 5, Balance: 0, EntriesTotal: 0},
+			}, nil),
+	)
+	store.EXPECT().
+		ListTransferEntryCounts(gomock.Any(), gomock.Eq(db.ListTransferEntryCountsParams{AfterID: 0, LimitCount: 2})).
+		Return([]db.ListTransferEntryCountsRow{
+			{ID: 1, Amount: 10, ToAmount: 10, EntryCount: 2, DebitCount: 1, CreditCount: 1},
+		}, nil)
+	store.EXPECT().
+		ListCurrencyTotals(gomock.Any()).
+		Return([]db.ListCurrencyTotalsRow{{Currency: "EUR", Total: 0}, {Currency: "USD", Total: 25}}, nil)
+	store.EXPECT().CreateReconciliationReport(gomock.Any(), gomock.Any()).Times(0)
+
+	report, err := Run(context.Background(), store, Options{BatchSize: 2})
+	require.NoError(t, err)
+	require.Equal(t, int64(3), report.AccountsChecked)
+	require.Equal(t, int64(1), report.TransfersChecked)
+	require.Len(t, report.Discrepancies, 2)
+	require.Equal(t, BalanceMismatch, report.Discrepancies[0].Kind)
+	require.Equal(t, int64(2), report.Discrepancies[0].AccountID)
+	require.Equal(t, CurrencyNotZeroSum, report.Discrepancies[1].Kind)
+	require.Equal(t, "USD", report.Discrepancies[1].Currency)
+	require.Zero(t, report.ID)
+}
+
+func TestRunRecord(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+
+	store.EXPECT().ListAccountEntryTotals(gomock.Any(), gomock.Any()).Return([]db.ListAccountEntryTotalsRow{}, nil)
+	store.EXPECT().
+		ListTransferEntryCounts(gomock.Any(), gomock.Any()).
+		Return([]db.ListTransferEntryCountsRow{
+			// the credit was booked on the wrong account
+			{ID: 7, FromAccountID: 1, ToAccountID: 2, Amount: 10, ToAmount: 10, EntryCount: 2, DebitCount: 1, CreditCount: 0},
+			// written without entries
+			{ID: 8, FromAccountID: 1, ToAccountID: 2, Amount: 10, ToAmount: 10},
+			// made before entries recorded their transfer, and never linked
+			{ID: 9, FromAccountID: 1, ToAccountID: 2, Amount: 10, ToAmount: 10, Unlinked: true},
+		}, nil)
+	store.EXPECT().ListCurrencyTotals(gomock.Any()).Return([]db.ListCurrencyTotalsRow{}, nil)
+	store.EXPECT().
+		CreateReconciliationReport(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, arg db.CreateReconciliationReportParams) (db.ReconciliationReport, error) {
+			require.Equal(t, int64(2), arg.DiscrepancyCount)
+			require.Equal(t, int64(3), arg.TransfersChecked)
+			require.Equal(t, int64(1), arg.TransfersUnverifiable)
+			require.Equal(t, "admin", arg.RunBy.String)
+			require.True(t, arg.RunBy.Valid)
+
+			var discrepancies []Discrepancy
+			require.NoError(t, json.Unmarshal(arg.Discrepancies, &discrepancies))
+			require.Len(t, discrepancies, 2)
+			require.Equal(t, TransferEntriesMismatch, discrepancies[0].Kind)
+			require.Equal(t, int64(7), discrepancies[0].TransferID)
+
+			return db.ReconciliationReport{ID: 3}, nil
+		})
+
+	report, err := Run(context.Background(), store, Options{Record: true, RunBy: "admin"})
+	require.NoError(t, err)
+	require.Equal(t, int64(3), report.ID)
+	require.Len(t, report.Discrepancies, 2)
+	require.Equal(t, int64(1), report.TransfersUnverifiable)
+	require.False(t, report.FinishedAt.Before(report.StartedAt))
+}
@@ -0,0 +1,51 @@
+package statement
+
+import (
+	db "BankAppGo/db/sqlc"
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Writes the statement as CSV, a few header rows followed by one row per entry
+func WriteCSV(w io.Writer, st db.StatementTxResult) error {
+	writer := csv.NewWriter(w)
+
+	header := [][]string{
+		{"account", strconv.FormatInt(st.Account.ID, 10)},
+		{"owner", st.OwnerFullName},
+		{"currency", st.Account.Currency},
+		{"period", st.From.Format(time.DateOnly), lastDay(st).Format(time.DateOnly)},
+		{"opening_balance", strconv.FormatInt(st.OpeningBalance, 10)},
+		{"closing_balance", strconv.FormatInt(st.ClosingBalance, 10)},
+		{},
+		{"date", "description", "counterparty_account", "amount", "balance"},
+	}
+	for _, record := range header {
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	for _, line := range st.Lines {
+		counterparty := ""
+		if line.CounterpartyAccountID != 0 {
+			counterparty = strconv.FormatInt(line.CounterpartyAccountID, 10)
+		}
+
+		err := writer.Write([]string{
+			line.CreatedAt.UTC().Format(time.RFC3339),
+			describe(line),
+			counterparty,
+			strconv.FormatInt(line.Amount, 10),
+			strconv.FormatInt(line.Balance, 10),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
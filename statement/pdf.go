@@ -0,0 +1,129 @@
+package statement
+
+import (
+	db "BankAppGo/db/sqlc"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// A4 page laid out with a fixed width font so the columns line up
+const (
+	pageWidth    = 595
+	pageHeight   = 842
+	marginLeft   = 40
+	marginTop    = 50
+	fontSize     = 9
+	lineHeight   = 13
+	linesPerPage = (pageHeight - 2*marginTop) / lineHeight
+)
+
+// Writes the statement as a PDF document of plain text pages
+func WritePDF(w io.Writer, st db.StatementTxResult) error {
+	lines := []string{
+		"Account statement",
+		"",
+		fmt.Sprintf("Account:          %d", st.Account.ID),
+		fmt.Sprintf("Owner:            %s", st.OwnerFullName),
+		fmt.Sprintf("Currency:         %s", st.Account.Currency),
+		fmt.Sprintf("Period:           %s to %s", st.From.Format(time.DateOnly), lastDay(st).Format(time.DateOnly)),
+		fmt.Sprintf("Opening balance:  %d", st.OpeningBalance),
+		fmt.Sprintf("Closing balance:  %d", st.ClosingBalance),
+		"",
+		fmt.Sprintf("%-20s %-44s %12s %14s", "Date", "Description", "Amount", "Balance"),
+		strings.Repeat("-", 93),
+	}
+
+	for _, line := range st.Lines {
+		lines = append(lines, fmt.Sprintf("%-20s %-44s %12d %14d",
+			line.CreatedAt.UTC().Format("2006-01-02 15:04:05"),
+			truncate(describe(line), 44),
+			line.Amount,
+			line.Balance,
+		))
+	}
+	if len(st.Lines) == 0 {
+		lines = append(lines, "No entries in this period")
+	}
+
+	return writeTextPDF(w, lines)
+}
+
+// lays the lines out on as many pages as needed and writes the PDF objects with their cross-reference table
+func writeTextPDF(w io.Writer, lines []string) error {
+	var pages [][]string
+	for len(lines) > linesPerPage {
+		pages = append(pages, lines[:linesPerPage])
+		lines = lines[linesPerPage:]
+	}
+	pages = append(pages, lines)
+
+	var buf bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n")
+
+	// objects 1 to 3 are the catalog, the page tree and the font, then a page and its content per page
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 4+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+
+	for i, page := range pages {
+		object(fmt.Sprintf(
+			"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, 5+2*i,
+		))
+
+		var content strings.Builder
+		fmt.Fprintf(&content, "BT /F1 %d Tf %d TL %d %d Td\n", fontSize, lineHeight, marginLeft, pageHeight-marginTop)
+		for _, line := range page {
+			fmt.Fprintf(&content, "(%s) Tj T*\n", escapePDF(line))
+		}
+		content.WriteString("ET")
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.String()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// escapes a PDF string literal, characters the standard font cannot show are replaced
+func escapePDF(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteRune('\\')
+			b.WriteRune(r)
+		case r < ' ' || r > '~':
+			b.WriteRune('?')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func truncate(s string, width int) string {
+	if len(s) <= width {
+		return s
+	}
+	return s[:width-3] + "..."
+}
@@ -0,0 +1,40 @@
+// Package statement renders account statements for download
+package statement
+
+import (
+	db "BankAppGo/db/sqlc"
+	"fmt"
+	"time"
+)
+
+// Layout of the month query parameter
+const monthLayout = "2006-01"
+
+// Returns the [from, to) range covering a month given as YYYY-MM, in UTC
+func MonthRange(month string) (from time.Time, to time.Time, err error) {
+	from, err = time.Parse(monthLayout, month)
+	if err != nil {
+		return from, to, fmt.Errorf("month must be formatted as YYYY-MM: %w", err)
+	}
+	return from, from.AddDate(0, 1, 0), nil
+}
+
+// describes what booked a statement line
+func describe(line db.StatementLine) string {
+	switch {
+	case line.TransferID != 0 && line.Amount < 0:
+		return fmt.Sprintf("transfer %d to account %d", line.TransferID, line.CounterpartyAccountID)
+	case line.TransferID != 0:
+		return fmt.Sprintf("transfer %d from account %d", line.TransferID, line.CounterpartyAccountID)
+	case line.AdjustmentReason != "":
+		return "adjustment: " + line.AdjustmentReason
+	case line.MovementKind != "":
+		return line.MovementKind + " " + line.ExternalReference
+	}
+	return "entry"
+}
+
+// the last day covered by the statement, as To is exclusive
+func lastDay(st db.StatementTxResult) time.Time {
+	return st.To.AddDate(0, 0, -1)
+}
@@ -0,0 +1,110 @@
+package statement
+
+import (
+	db "BankAppGo/db/sqlc"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func randomStatement(lines int) db.StatementTxResult {
+	from, to, _ := MonthRange("2024-02")
+	st := db.StatementTxResult{
+		Account:        db.Account{ID: 7, Owner: "jesse", Currency: "USD"},
+		OwnerFullName:  "Jesse (Jackson)",
+		From:           from,
+		To:             to,
+		OpeningBalance: 100,
+	}
+
+	balance := st.OpeningBalance
+	for i := 0; i < lines; i++ {
+		line := db.StatementLine{
+			EntryID:               int64(i + 1),
+			CreatedAt:             from.Add(time.Duration(i) * time.Hour),
+			Amount:                -10,
+			TransferID:            int64(i + 1),
+			CounterpartyAccountID: 9,
+		}
+		if i%2 == 1 {
+			line.Amount = 15
+		}
+		balance += line.Amount
+		line.Balance = balance
+		st.Lines = append(st.Lines, line)
+	}
+	st.ClosingBalance = balance
+	return st
+}
+
+func TestMonthRange(t *testing.T) {
+	from, to, err := MonthRange("2024-12")
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC), from)
+	require.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), to)
+
+	_, _, err = MonthRange("2024-13")
+	require.Error(t, err)
+
+	_, _, err = MonthRange("december")
+	require.Error(t, err)
+}
+
+func TestWriteCSV(t *testing.T) {
+	st := randomStatement(3)
+
+	var buf bytes.Buffer
+	err := WriteCSV(&buf, st)
+	require.NoError(t, err)
+
+	reader := csv.NewReader(&buf)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"owner", st.OwnerFullName}, records[1])
+	require.Equal(t, []string{"period", "2024-02-01", "2024-02-29"}, records[3])
+	require.Equal(t, []string{"closing_balance", strconv.FormatInt(st.ClosingBalance, 10)}, records[5])
+
+	rows := records[7:]
+	require.Len(t, rows, len(st.Lines))
+	require.Equal(t, "transfer 1 to account 9", rows[0][1])
+	require.Equal(t, "transfer 2 from account 9", rows[1][1])
+	require.Equal(t, "9", rows[0][2])
+	require.Equal(t, strconv.FormatInt(st.Lines[2].Balance, 10), rows[2][4])
+}
+
+func TestWritePDF(t *testing.T) {
+	// enough lines to need a second page
+	st := randomStatement(linesPerPage)
+
+	var buf bytes.Buffer
+	err := WritePDF(&buf, st)
+	require.NoError(t, err)
+
+	pdf := buf.String()
+	require.True(t, strings.HasPrefix(pdf, "%PDF-1.4\n"))
+	require.True(t, strings.HasSuffix(pdf, "%%EOF\n"))
+	require.Contains(t, pdf, "/Count 2")
+	require.Contains(t, pdf, `Owner:            Jesse \(Jackson\)`)
+
+	// every cross-reference entry points at the start of its object
+	offsets := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllStringSubmatch(pdf, -1)
+	require.Len(t, offsets, 3+2*2)
+	for i, match := range offsets {
+		offset, err := strconv.Atoi(match[1])
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(pdf[offset:], fmt.Sprintf("%d 0 obj", i+1)))
+	}
+}
+
+func TestEscapePDF(t *testing.T) {
+	require.Equal(t, `a\(b\)\\c?`, escapePDF(`a(b)\c`+"é"))
+}
@@ -22,14 +22,15 @@ func NewJWTTokenMaker(secretKey string) (TokenMaker, error) {
 	return &JWTTokenMaker{secretKey}, nil
 }
 
-func (tokenMaker *JWTTokenMaker) CreateToken(username string, duration time.Duration) (string, error) {
-	payload, err := NewPayload(username, duration)
+func (tokenMaker *JWTTokenMaker) CreateToken(username string, role string, tokenType TokenType, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewPayload(username, role, tokenType, duration)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 
 	jwtToken := jwt.NewWithClaims(jwt.SigningMethodHS256, payload)
-	return jwtToken.SignedString([]byte(tokenMaker.secretKey))
+	token, err := jwtToken.SignedString([]byte(tokenMaker.secretKey))
+	return token, payload, err
 }
 
 // verifies if a token is valid
@@ -14,21 +14,25 @@ func TestJWTTokenMaker(t *testing.T) {
 	require.NoError(t, err)
 
 	username := util.RandomOwner()
+	role := util.BankerRole
 	duration := time.Minute
 
 	issuedAt := time.Now()
 	expiredAt := issuedAt.Add(duration)
 
-	token, err := token_maker.CreateToken(username, duration)
+	token, payload, err := token_maker.CreateToken(username, role, TokenTypeAccess, duration)
 	require.NoError(t, err)
 	require.NotEmpty(t, token)
+	require.NotEmpty(t, payload)
 
-	payload, err := token_maker.VerifyToken(token)
+	payload, err = token_maker.VerifyToken(token)
 	require.NoError(t, err)
 	require.NotEmpty(t, payload)
 
 	require.NotZero(t, payload.ID)
 	require.Equal(t, username, payload.Username)
+	require.Equal(t, role, payload.Role)
+	require.Equal(t, TokenTypeAccess, payload.Type)
 	require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
 	require.WithinDuration(t, expiredAt, payload.ExpiredAt, time.Second)
 }
@@ -37,17 +41,18 @@ func TestExpiredJWTTokenMaker(t *testing.T) {
 	token_maker, err := NewJWTTokenMaker(util.RandomString(32))
 	require.NoError(t, err)
 
-	token, err := token_maker.CreateToken(util.RandomOwner(), -time.Minute)
+	token, payload, err := token_maker.CreateToken(util.RandomOwner(), util.DepositorRole, TokenTypeAccess, -time.Minute)
 	require.NoError(t, err)
 	require.NotEmpty(t, token)
+	require.NotEmpty(t, payload)
 
-	payload, err := token_maker.VerifyToken(token)
+	payload, err = token_maker.VerifyToken(token)
 	require.EqualError(t, err, ErrExpiredToken.Error())
 	require.Nil(t, payload)
 }
 
 func TestInvalidJWTTokenAlgNone(t *testing.T) {
-	payload, err := NewPayload(util.RandomOwner(), time.Minute)
+	payload, err := NewPayload(util.RandomOwner(), util.DepositorRole, TokenTypeAccess, time.Minute)
 	require.NoError(t, err)
 
 	jwtToken := jwt.NewWithClaims(jwt.SigningMethodNone, payload)
@@ -14,21 +14,25 @@ func TestPasetoMaker(t *testing.T) {
 	require.NoError(t, err)
 
 	username := util.RandomOwner()
+	role := util.BankerRole
 	duration := time.Minute
 
 	issuedAt := time.Now()
 	expiredAt := issuedAt.Add(duration)
 
-	token, err := token_maker.CreateToken(username, duration)
+	token, payload, err := token_maker.CreateToken(username, role, TokenTypeAccess, duration)
 	require.NoError(t, err)
 	require.NotEmpty(t, token)
+	require.NotEmpty(t, payload)
 
-	payload, err := token_maker.VerifyToken(token)
+	payload, err = token_maker.VerifyToken(token)
 	require.NoError(t, err)
 	require.NotEmpty(t, payload)
 
 	require.NotZero(t, payload.ID)
 	require.Equal(t, username, payload.Username)
+	require.Equal(t, role, payload.Role)
+	require.Equal(t, TokenTypeAccess, payload.Type)
 	require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
 	require.WithinDuration(t, expiredAt, payload.ExpiredAt, time.Second)
 }
@@ -37,11 +41,26 @@ func TestExpiredPasetoTokenMaker(t *testing.T) {
 	token_maker, err := NewPasetoMaker(util.RandomString(chacha20poly1305.KeySize))
 	require.NoError(t, err)
 
-	token, err := token_maker.CreateToken(util.RandomOwner(), -time.Minute)
+	token, payload, err := token_maker.CreateToken(util.RandomOwner(), util.DepositorRole, TokenTypeAccess, -time.Minute)
 	require.NoError(t, err)
 	require.NotEmpty(t, token)
+	require.NotEmpty(t, payload)
 
-	payload, err := token_maker.VerifyToken(token)
+	payload, err = token_maker.VerifyToken(token)
 	require.EqualError(t, err, ErrExpiredToken.Error())
 	require.Nil(t, payload)
 }
+
+func TestPasetoTokenType(t *testing.T) {
+	token_maker, err := NewPasetoMaker(util.RandomString(chacha20poly1305.KeySize))
+	require.NoError(t, err)
+
+	token, _, err := token_maker.CreateToken(util.RandomOwner(), util.DepositorRole, TokenTypeRefresh, time.Minute)
+	require.NoError(t, err)
+
+	payload, err := token_maker.VerifyToken(token)
+	require.NoError(t, err)
+	require.Equal(t, TokenTypeRefresh, payload.Type)
+	require.NoError(t, payload.CheckType(TokenTypeRefresh))
+	require.EqualError(t, payload.CheckType(TokenTypeAccess), ErrTokenType.Error())
+}
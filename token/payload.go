@@ -12,11 +12,25 @@ import (
 var (
 	ErrInvalidToken = errors.New("token is invalid")
 	ErrExpiredToken = errors.New("token is expired")
+	ErrTokenType    = errors.New("token is of the wrong type")
+)
+
+// TokenType tells what a token may be used for
+type TokenType string
+
+// Types of token
+const (
+	// authorizes requests, short lived
+	TokenTypeAccess TokenType = "access"
+	// only renews access tokens while its session lives
+	TokenTypeRefresh TokenType = "refresh"
 )
 
 type Payload struct {
 	ID        uuid.UUID `json:"id"`
 	Username  string    `json:"username"`
+	Role      string    `json:"role"`
+	Type      TokenType `json:"token_type"`
 	IssuedAt  time.Time `json:"issued_at"`
 	ExpiredAt time.Time `json:"expired_at"`
 }
@@ -51,7 +65,7 @@ func (p *Payload) GetSubject() (string, error) {
 	return p.Username, nil
 }
 
-func NewPayload(username string, duration time.Duration) (*Payload, error) {
+func NewPayload(username string, role string, tokenType TokenType, duration time.Duration) (*Payload, error) {
 	tokenID, err := uuid.NewRandom()
 	if err != nil {
 		return nil, err
@@ -59,6 +73,8 @@ func NewPayload(username string, duration time.Duration) (*Payload, error) {
 
 	payload := &Payload{
 		Username:  username,
+		Role:      role,
+		Type:      tokenType,
 		ID:        tokenID,
 		IssuedAt:  time.Now(),
 		ExpiredAt: time.Now().Add(duration),
@@ -74,3 +90,11 @@ func (payload *Payload) Valid() error {
 	}
 	return nil
 }
+
+// CheckType checks the token was issued for the given use, so a refresh token can't authorize requests nor an access token renew itself
+func (payload *Payload) CheckType(tokenType TokenType) error {
+	if payload.Type != tokenType {
+		return ErrTokenType
+	}
+	return nil
+}
@@ -4,8 +4,8 @@ import "time"
 
 // interface for managing tokens
 type TokenMaker interface {
-	// creates new token for a specific username and duration
-	CreateToken(username string, duration time.Duration) (string, error)
+	// creates new token of a type for a specific username, role and duration, returning it with its payload
+	CreateToken(username string, role string, tokenType TokenType, duration time.Duration) (string, *Payload, error)
 
 	// verifies if a token is valid
 	VerifyToken(token string) (*Payload, error)